@@ -0,0 +1,127 @@
+// Package employee keeps the employee register and validates government numbers.
+package employee
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"runfyne/payroll"
+)
+
+// TaxStatus is the BIR civil status code of an employee
+type TaxStatus string
+
+// TaxStatuses lists the accepted status codes
+var TaxStatuses = []TaxStatus{"S", "ME", "S1", "S2", "S3", "S4", "ME1", "ME2", "ME3", "ME4", "Z"}
+
+// Employee is one entry in the employee register
+type Employee struct {
+	ID            int
+	Name          string
+	TIN           string
+	SSS           string
+	PhilHealth    string
+	PagIbig       string
+	BirthDate     time.Time
+	HireDate      time.Time
+	SeparatedOn   time.Time // zero while the employee is still with the company
+	PayFrequency  payroll.PayFrequency
+	MonthlySalary decimal.Decimal
+	TaxStatus     TaxStatus
+	EmployeeType  payroll.EmployeeType `json:",omitempty"` // regular when empty
+	Region        string               `json:",omitempty"` // where a kasambahay works, to check the minimum wage
+}
+
+// Validate checks the employee's details and puts the government numbers in their dashed form
+func (e *Employee) Validate() error {
+	var errs []error
+
+	e.Name = strings.TrimSpace(e.Name)
+	if e.Name == "" {
+		errs = append(errs, errors.New("name is required"))
+	}
+
+	// Government numbers are optional but must be valid when given
+	ids := []struct {
+		value     *string
+		normalize func(string) (string, error)
+	}{
+		{&e.TIN, NormalizeTIN},
+		{&e.SSS, NormalizeSSS},
+		{&e.PhilHealth, NormalizePhilHealth},
+		{&e.PagIbig, NormalizePagIbig},
+	}
+	for _, id := range ids {
+		if strings.TrimSpace(*id.value) == "" {
+			*id.value = ""
+			continue
+		}
+		normalized, err := id.normalize(*id.value)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		*id.value = normalized
+	}
+
+	if e.BirthDate.IsZero() {
+		errs = append(errs, errors.New("birthdate is required"))
+	}
+	if e.HireDate.IsZero() {
+		errs = append(errs, errors.New("hire date is required"))
+	} else if !e.BirthDate.IsZero() && !e.HireDate.After(e.BirthDate) {
+		errs = append(errs, errors.New("hire date must be after the birthdate"))
+	}
+
+	if !e.SeparatedOn.IsZero() && e.SeparatedOn.Before(e.HireDate) {
+		errs = append(errs, errors.New("separation date must be after the hire date"))
+	}
+
+	if _, err := payroll.ParsePayFrequency(string(e.PayFrequency)); err != nil {
+		errs = append(errs, err)
+	}
+	if e.MonthlySalary.LessThan(decimal.Zero) {
+		errs = append(errs, errors.New("salary cannot be negative"))
+	}
+
+	if e.EmployeeType != "" {
+		if _, err := payroll.ParseEmployeeType(string(e.EmployeeType)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	e.Region = strings.TrimSpace(e.Region)
+	switch {
+	case e.Region != "" && e.EmployeeType != payroll.Kasambahay:
+		errs = append(errs, errors.New("region is only kept for kasambahay"))
+	case e.Region != "":
+		if _, err := payroll.Current().Kasambahay.MinimumWageIn(e.Region); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	validStatus := false
+	for _, s := range TaxStatuses {
+		validStatus = validStatus || s == e.TaxStatus
+	}
+	if !validStatus {
+		errs = append(errs, fmt.Errorf("unknown tax status %q", e.TaxStatus))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Options are the payroll options of the employee's pay
+func (e Employee) Options() payroll.Options {
+	return payroll.Options{PayFrequency: e.PayFrequency, EmployeeType: e.EmployeeType, Region: e.Region}
+}
+
+// ActiveDuring reports whether the employee was employed at any time between start and end
+func (e Employee) ActiveDuring(start, end time.Time) bool {
+	if e.HireDate.After(end) {
+		return false
+	}
+	return e.SeparatedOn.IsZero() || !e.SeparatedOn.Before(start)
+}
@@ -0,0 +1,53 @@
+package employee
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"runfyne/payroll"
+)
+
+func TestValidate(t *testing.T) {
+	valid := func() Employee {
+		return Employee{
+			Name:          " Juan dela Cruz ",
+			SSS:           "3412345678",
+			BirthDate:     time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+			HireDate:      time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			PayFrequency:  payroll.SemiMonthly,
+			MonthlySalary: decimal.NewFromInt(30000),
+			TaxStatus:     "S",
+		}
+	}
+	tests := []struct {
+		name   string
+		change func(e *Employee)
+		ok     bool
+	}{
+		{"valid", func(e *Employee) {}, true},
+		{"no name", func(e *Employee) { e.Name = " " }, false},
+		{"bad SSS number", func(e *Employee) { e.SSS = "34-12345" }, false},
+		{"hired before birth", func(e *Employee) { e.HireDate = e.BirthDate.AddDate(-1, 0, 0) }, false},
+		{"separated before hire", func(e *Employee) { e.SeparatedOn = e.HireDate.AddDate(0, -1, 0) }, false},
+		{"unknown tax status", func(e *Employee) { e.TaxStatus = "X" }, false},
+		{"unknown pay frequency", func(e *Employee) { e.PayFrequency = "yearly" }, false},
+		{"negative salary", func(e *Employee) { e.MonthlySalary = decimal.NewFromInt(-1) }, false},
+		{"minimum wage earner", func(e *Employee) { e.EmployeeType = payroll.MinimumWage }, true},
+		{"unknown employee type", func(e *Employee) { e.EmployeeType = "contractor" }, false},
+		{"kasambahay in NCR", func(e *Employee) { e.EmployeeType, e.Region = payroll.Kasambahay, "NCR" }, true},
+		{"kasambahay in an unknown region", func(e *Employee) { e.EmployeeType, e.Region = payroll.Kasambahay, "XX" }, false},
+		{"region for a regular employee", func(e *Employee) { e.Region = "NCR" }, false},
+	}
+	for _, tt := range tests {
+		e := valid()
+		tt.change(&e)
+		err := e.Validate()
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: error %v", tt.name, err)
+		}
+		if tt.ok && (e.Name != "Juan dela Cruz" || e.SSS != "34-1234567-8") {
+			t.Errorf("%s: name %q and SSS %q not normalized", tt.name, e.Name, e.SSS)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package employee
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"runfyne/storage"
+)
+
+// Store is the employee register, saved to a JSON file after every change
+type Store struct {
+	mu        sync.Mutex
+	path      string
+	nextID    int
+	employees map[int]Employee
+}
+
+// storeFile is the layout of the register on disk
+type storeFile struct {
+	NextID    int
+	Employees []Employee
+}
+
+// NewStore returns an empty register backed by the file at path
+func NewStore(path string) *Store {
+	return &Store{path: path, nextID: 1, employees: map[int]Employee{}}
+}
+
+// Load reads the register from disk, a missing file gives an empty register
+func (s *Store) Load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file := storeFile{NextID: 1}
+	if err := storage.ReadJSON(s.path, &file); err != nil {
+		return fmt.Errorf("loading employees: %w", err)
+	}
+	s.nextID = file.NextID
+	s.employees = make(map[int]Employee, len(file.Employees))
+	for _, e := range file.Employees {
+		s.employees[e.ID] = e
+		if e.ID >= s.nextID {
+			s.nextID = e.ID + 1
+		}
+	}
+	return nil
+}
+
+// Add validates a new employee, assigns its ID and saves it
+func (s *Store) Add(e Employee) (Employee, error) {
+	if err := e.Validate(); err != nil {
+		return Employee{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkDuplicates(e); err != nil {
+		return Employee{}, err
+	}
+	e.ID = s.nextID
+	s.nextID++
+	s.employees[e.ID] = e
+	if err := s.save(); err != nil {
+		delete(s.employees, e.ID)
+		return Employee{}, err
+	}
+	return e, nil
+}
+
+// Update validates and saves changes to an existing employee
+func (s *Store) Update(e Employee) (Employee, error) {
+	if err := e.Validate(); err != nil {
+		return Employee{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, ok := s.employees[e.ID]
+	if !ok {
+		return Employee{}, fmt.Errorf("employee %d does not exist", e.ID)
+	}
+	if err := s.checkDuplicates(e); err != nil {
+		return Employee{}, err
+	}
+	s.employees[e.ID] = e
+	if err := s.save(); err != nil {
+		s.employees[e.ID] = old
+		return Employee{}, err
+	}
+	return e, nil
+}
+
+// Get returns the employee with the given ID
+func (s *Store) Get(id int) (Employee, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.employees[id]
+	return e, ok
+}
+
+// All returns every employee sorted by name
+func (s *Store) All() []Employee {
+	return s.Search("")
+}
+
+// Search returns the employees whose name or government numbers contain the query,
+// ignoring case and dashes
+func (s *Store) Search(query string) []Employee {
+	query = simplify(query)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var found []Employee
+	for _, e := range s.employees {
+		fields := []string{e.Name, e.TIN, e.SSS, e.PhilHealth, e.PagIbig}
+		for _, f := range fields {
+			if strings.Contains(simplify(f), query) {
+				found = append(found, e)
+				break
+			}
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Name != found[j].Name {
+			return found[i].Name < found[j].Name
+		}
+		return found[i].ID < found[j].ID
+	})
+	return found
+}
+
+// checkDuplicates makes sure no other employee already has the same government numbers
+func (s *Store) checkDuplicates(e Employee) error {
+	for _, other := range s.employees {
+		if other.ID == e.ID {
+			continue
+		}
+		switch {
+		case e.TIN != "" && e.TIN == other.TIN:
+			return fmt.Errorf("TIN %s is already used by %s", e.TIN, other.Name)
+		case e.SSS != "" && e.SSS == other.SSS:
+			return fmt.Errorf("SSS number %s is already used by %s", e.SSS, other.Name)
+		case e.PhilHealth != "" && e.PhilHealth == other.PhilHealth:
+			return fmt.Errorf("PhilHealth PIN %s is already used by %s", e.PhilHealth, other.Name)
+		case e.PagIbig != "" && e.PagIbig == other.PagIbig:
+			return fmt.Errorf("Pag-IBIG MID %s is already used by %s", e.PagIbig, other.Name)
+		}
+	}
+	return nil
+}
+
+func (s *Store) save() error {
+	file := storeFile{NextID: s.nextID}
+	for _, e := range s.employees {
+		file.Employees = append(file.Employees, e)
+	}
+	sort.Slice(file.Employees, func(i, j int) bool { return file.Employees[i].ID < file.Employees[j].ID })
+	return storage.WriteJSON(s.path, file)
+}
+
+func simplify(s string) string {
+	return strings.ToLower(strings.ReplaceAll(s, "-", ""))
+}
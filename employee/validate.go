@@ -0,0 +1,199 @@
+package employee
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/BurntSushi/toml"
+)
+
+// idFormat describes how a government number is laid out, as digit groups
+// separated by dashes
+type idFormat struct {
+	name   string
+	key    string  // table of its check digit in LoadCheckDigits
+	groups [][]int // accepted group lengths, any of these layouts is valid
+}
+
+// Layouts as printed on the IDs issued by each agency:
+// TIN        123-456-789-000 (the 3 or 5 digit branch code is optional)
+// SSS        34-1234567-8
+// PhilHealth 12-345678901-2
+// Pag-IBIG   1234-5678-9012
+//
+// Numbers that are a run of a single repeated digit are refused, as that is
+// how placeholders are usually keyed in. The check digit is verified for the
+// numbers configured with LoadCheckDigits
+var (
+	tinFormat        = idFormat{"TIN", "tin", [][]int{{3, 3, 3}, {3, 3, 3, 3}, {3, 3, 3, 5}}}
+	sssFormat        = idFormat{"SSS number", "sss", [][]int{{2, 7, 1}}}
+	philHealthFormat = idFormat{"PhilHealth PIN", "philhealth", [][]int{{2, 9, 1}}}
+	pagIbigFormat    = idFormat{"Pag-IBIG MID", "pagibig", [][]int{{4, 4, 4}}}
+)
+
+// CheckDigit is a weighted modulus check. Each digit before the check digit
+// is multiplied by its weight and the products are added up. The check digit
+// is the sum modulo Modulus, or Modulus less that remainder when Complement
+// is set, taken modulo Modulus again. Numbers whose result is 10 or more have
+// no valid check digit and are refused
+type CheckDigit struct {
+	Digit      int   `toml:"digit"`      // position of the check digit counting from 1, the last digit of the shortest layout when zero
+	Weights    []int `toml:"weights"`    // one weight for each digit before the check digit
+	Modulus    int   `toml:"modulus"`    // usually 10 or 11
+	Complement bool  `toml:"complement"` // the check digit is Modulus less the remainder
+}
+
+// checkDigits holds the checks loaded by LoadCheckDigits, keyed by the
+// format's key. Numbers without one are only checked for their layout
+var checkDigits atomic.Pointer[map[string]CheckDigit]
+
+// LoadCheckDigits reads the check digit of each kind of number from a TOML
+// file. A missing file leaves the check digits unverified:
+//
+//	[sss]
+//	weights = [...]
+//	modulus = 11
+//	complement = true
+//
+// The tables are tin, sss, philhealth and pagibig, and any of them may be
+// left out. Nothing is loaded if any table is invalid
+func LoadCheckDigits(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	var checks map[string]CheckDigit
+	md, err := toml.Decode(string(data), &checks)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return fmt.Errorf("%s: unknown key %s", path, undecoded[0])
+	}
+
+	formats := map[string]idFormat{}
+	for _, f := range []idFormat{tinFormat, sssFormat, philHealthFormat, pagIbigFormat} {
+		formats[f.key] = f
+	}
+	var errs []error
+	for key, c := range checks {
+		f, ok := formats[key]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: unknown number %q, use tin, sss, philhealth or pagibig", path, key))
+			continue
+		}
+		if c.Digit == 0 {
+			c.Digit = f.shortest()
+			checks[key] = c
+		}
+		switch {
+		case c.Digit < 2 || c.Digit > f.shortest():
+			errs = append(errs, fmt.Errorf("%s: %s: digit must be from 2 to %d", path, key, f.shortest()))
+		case len(c.Weights) != c.Digit-1:
+			errs = append(errs, fmt.Errorf("%s: %s: give %d weights, one for each digit before the check digit", path, key, c.Digit-1))
+		case c.Modulus < 2:
+			errs = append(errs, fmt.Errorf("%s: %s: modulus must be at least 2", path, key))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	checkDigits.Store(&checks)
+	return nil
+}
+
+// valid reports whether the check digit of a number of digits matches
+func (c CheckDigit) valid(digits string) bool {
+	sum := 0
+	for i, w := range c.Weights {
+		sum += int(digits[i]-'0') * w
+	}
+	want := sum % c.Modulus
+	if c.Complement {
+		want = (c.Modulus - want) % c.Modulus
+	}
+	return want < 10 && int(digits[c.Digit-1]-'0') == want
+}
+
+// NormalizeTIN validates a TIN and returns it in its dashed form
+func NormalizeTIN(s string) (string, error) { return tinFormat.normalize(s) }
+
+// NormalizeSSS validates an SSS number and returns it in its dashed form
+func NormalizeSSS(s string) (string, error) { return sssFormat.normalize(s) }
+
+// NormalizePhilHealth validates a PhilHealth PIN and returns it in its dashed form
+func NormalizePhilHealth(s string) (string, error) { return philHealthFormat.normalize(s) }
+
+// NormalizePagIbig validates a Pag-IBIG MID and returns it in its dashed form
+func NormalizePagIbig(s string) (string, error) { return pagIbigFormat.normalize(s) }
+
+func (f idFormat) normalize(s string) (string, error) {
+	// Accept the number with or without dashes and spaces
+	digits := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, s)
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return "", fmt.Errorf("%s must only contain digits", f.name)
+		}
+	}
+
+	for _, groups := range f.groups {
+		total := 0
+		for _, n := range groups {
+			total += n
+		}
+		if len(digits) != total {
+			continue
+		}
+		if strings.Count(digits, digits[:1]) == len(digits) {
+			return "", fmt.Errorf("%s %s is not a valid number", f.name, s)
+		}
+		if checks := checkDigits.Load(); checks != nil {
+			if c, ok := (*checks)[f.key]; ok && !c.valid(digits) {
+				return "", fmt.Errorf("%s %s has the wrong check digit", f.name, s)
+			}
+		}
+
+		parts := make([]string, 0, len(groups))
+		for _, n := range groups {
+			parts = append(parts, digits[:n])
+			digits = digits[n:]
+		}
+		return strings.Join(parts, "-"), nil
+	}
+
+	return "", fmt.Errorf("%s must be in the format %s", f.name, f.example())
+}
+
+// shortest is the number of digits in the shortest layout
+func (f idFormat) shortest() int {
+	least := 0
+	for i, groups := range f.groups {
+		total := 0
+		for _, n := range groups {
+			total += n
+		}
+		if i == 0 || total < least {
+			least = total
+		}
+	}
+	return least
+}
+
+func (f idFormat) example() string {
+	parts := make([]string, 0, len(f.groups[0]))
+	for _, n := range f.groups[0] {
+		parts = append(parts, strings.Repeat("#", n))
+	}
+	return strings.Join(parts, "-")
+}
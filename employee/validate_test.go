@@ -0,0 +1,111 @@
+package employee
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name      string
+		normalize func(string) (string, error)
+		in, want  string // want is empty when the number is refused
+	}{
+		{"TIN", NormalizeTIN, "123456789", "123-456-789"},
+		{"TIN with branch", NormalizeTIN, "123-456-789-000", "123-456-789-000"},
+		{"TIN with long branch", NormalizeTIN, "123 456 789 00000", "123-456-789-00000"},
+		{"TIN too short", NormalizeTIN, "12345678", ""},
+		{"TIN with letters", NormalizeTIN, "123-456-78A", ""},
+		{"TIN placeholder", NormalizeTIN, "000-000-000", ""},
+		{"SSS", NormalizeSSS, "3412345678", "34-1234567-8"},
+		{"SSS misplaced dashes", NormalizeSSS, "341-234-5678", "34-1234567-8"},
+		{"SSS too long", NormalizeSSS, "34123456789", ""},
+		{"PhilHealth", NormalizePhilHealth, "12-345678901-2", "12-345678901-2"},
+		{"PhilHealth placeholder", NormalizePhilHealth, "111111111111", ""},
+		{"Pag-IBIG", NormalizePagIbig, "123456789012", "1234-5678-9012"},
+		{"Pag-IBIG too short", NormalizePagIbig, "1234-5678", ""},
+	}
+	for _, tt := range tests {
+		got, err := tt.normalize(tt.in)
+		switch {
+		case tt.want == "" && err == nil:
+			t.Errorf("%s: %q accepted as %q", tt.name, tt.in, got)
+		case tt.want != "" && (err != nil || got != tt.want):
+			t.Errorf("%s: %q = %q, %v, want %q", tt.name, tt.in, got, err, tt.want)
+		}
+	}
+}
+
+// loadCheckDigits loads a check digit file for the length of the test
+func loadCheckDigits(t *testing.T, contents string) error {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "checkdigits.toml")
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { checkDigits.Store(nil) })
+	return LoadCheckDigits(path)
+}
+
+func TestCheckDigits(t *testing.T) {
+	// Made-up checks: the sums of the digits before the check digit of
+	// 34-1234567 and 123-456-78 are 35 and 36
+	err := loadCheckDigits(t, `
+[sss]
+weights = [1, 1, 1, 1, 1, 1, 1, 1, 1]
+modulus = 10
+complement = true
+
+[tin]
+weights = [1, 1, 1, 1, 1, 1, 1, 1]
+modulus = 11
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	accepted := map[string]func(string) (string, error){
+		"34-1234567-5":    NormalizeSSS,
+		"123-456-783":     NormalizeTIN,
+		"123-456-783-000": NormalizeTIN, // the branch code is not part of the check
+		"12-345678901-2":  NormalizePhilHealth,
+	}
+	for in, normalize := range accepted {
+		if _, err := normalize(in); err != nil {
+			t.Errorf("%s refused: %v", in, err)
+		}
+	}
+	for _, in := range []string{"34-1234567-8", "123-456-789"} {
+		normalize := NormalizeSSS
+		if len(in) == len("123-456-789") {
+			normalize = NormalizeTIN
+		}
+		if _, err := normalize(in); err == nil || !strings.Contains(err.Error(), "check digit") {
+			t.Errorf("%s: error %v, want a wrong check digit", in, err)
+		}
+	}
+}
+
+func TestLoadCheckDigitsErrors(t *testing.T) {
+	for contents, want := range map[string]string{
+		"[umid]\nweights = [1]\nmodulus = 10":                       "unknown number",
+		"[sss]\nweights = [1, 2]\nmodulus = 10":                     "give 9 weights",
+		"[sss]\nweights = [1, 1, 1, 1, 1, 1, 1, 1, 1]\nmodulus = 1": "modulus",
+		"[tin]\ndigit = 12\nweights = [1]\nmodulus = 10":            "digit must be from 2 to 9",
+		"[sss]\nweight = [1]":                                       "unknown key",
+	} {
+		err := loadCheckDigits(t, contents)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: error %v, want %q", contents, err, want)
+		}
+		if checkDigits.Load() != nil {
+			t.Errorf("%q: checks loaded despite the error", contents)
+		}
+	}
+
+	if err := LoadCheckDigits(filepath.Join(t.TempDir(), "none.toml")); err != nil || checkDigits.Load() != nil {
+		t.Errorf("missing file: error %v", err)
+	}
+}
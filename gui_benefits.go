@@ -0,0 +1,161 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shopspring/decimal"
+	"runfyne/payroll"
+)
+
+// benefitsTab estimates SSS benefits and the retirement pension from a
+// contribution history typed in or filled from a steady salary
+func benefitsTab(win fyne.Window) fyne.CanvasObject {
+	var names []string
+	for _, b := range payroll.SSSBenefits {
+		names = append(names, b.String())
+	}
+	benefitSelect := widget.NewSelect(names, nil)
+	benefitSelect.SetSelected(payroll.MaternityBenefit.String())
+
+	dateEntry := widget.NewEntry()
+	dateEntry.SetPlaceHolder(dateLayout)
+	dateEntry.SetText(time.Now().Format(dateLayout))
+	miscarriageCheck := widget.NewCheck("Miscarriage or emergency termination", nil)
+	daysEntry := widget.NewEntry()
+	daysEntry.SetPlaceHolder("Days of confinement, for sickness")
+
+	historyEntry := widget.NewMultiLineEntry()
+	historyEntry.SetPlaceHolder("One contribution per line as YYYY-MM,amount\n2023-01,20000")
+	historyEntry.SetMinRowsVisible(8)
+
+	// Fills the history with the same salary every month up to the month before the date
+	salaryEntry := widget.NewEntry()
+	salaryEntry.SetPlaceHolder("Monthly salary")
+	monthsEntry := widget.NewEntry()
+	monthsEntry.SetText("36")
+	fillBtn := widget.NewButton("Fill History", func() {
+		salary, err1 := decimal.NewFromString(salaryEntry.Text)
+		months, err2 := strconv.Atoi(monthsEntry.Text)
+		date, err3 := time.Parse(dateLayout, dateEntry.Text)
+		if err1 != nil || err2 != nil || err3 != nil || months <= 0 {
+			dialog.ShowInformation("SSS Benefits", "Enter the date, a salary and a number of months", win)
+			return
+		}
+		var b strings.Builder
+		for _, c := range payroll.SteadySSSHistory(salary, date.AddDate(0, -1, 0), months) {
+			b.WriteString(c.Month.Format("2006-01") + "," + c.Amount.String() + "\n")
+		}
+		historyEntry.SetText(b.String())
+	})
+
+	yearsEntry := widget.NewEntry()
+	yearsEntry.SetPlaceHolder("Credited years, counted from the history when empty")
+	dependentsEntry := widget.NewEntry()
+	dependentsEntry.SetText("0")
+
+	explanation := newExplanationView()
+	estimateBtn := widget.NewButton("Estimate", func() {
+		b, err := payroll.ParseSSSBenefit(benefitSelect.Selected)
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		date, err := time.Parse(dateLayout, dateEntry.Text)
+		if err != nil {
+			dialog.ShowInformation("SSS Benefits", "Enter the date as "+dateLayout, win)
+			return
+		}
+		days := 0
+		if daysEntry.Text != "" {
+			if days, err = strconv.Atoi(daysEntry.Text); err != nil {
+				dialog.ShowInformation("SSS Benefits", "Invalid days of confinement", win)
+				return
+			}
+		}
+		history, err := payroll.ParseSSSHistory(strings.NewReader(historyEntry.Text))
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		st, err := payroll.ComputeSSSBenefit(payroll.SSSBenefitInputs{
+			Benefit:     b,
+			Contingency: date,
+			History:     history,
+			Miscarriage: miscarriageCheck.Checked,
+			SickDays:    days,
+		})
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		explanation.show([]payroll.Trace{st.Trace()})
+		explanation.accordion.OpenAll()
+	})
+
+	pensionBtn := widget.NewButton("Estimate Pension", func() {
+		date, err := time.Parse(dateLayout, dateEntry.Text)
+		if err != nil {
+			dialog.ShowInformation("SSS Benefits", "Enter the retirement date as "+dateLayout, win)
+			return
+		}
+		years, err1 := 0, error(nil)
+		if yearsEntry.Text != "" {
+			years, err1 = strconv.Atoi(yearsEntry.Text)
+		}
+		dependents, err2 := strconv.Atoi(dependentsEntry.Text)
+		if err1 != nil || err2 != nil {
+			dialog.ShowInformation("SSS Benefits", "Enter the credited years and dependents in numbers", win)
+			return
+		}
+		history, err := payroll.ParseSSSHistory(strings.NewReader(historyEntry.Text))
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		st, err := payroll.ComputeSSSPension(payroll.SSSPensionInputs{
+			History:        history,
+			CreditedYears:  years,
+			Dependents:     dependents,
+			RetirementDate: date,
+		})
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		explanation.show([]payroll.Trace{st.Trace()})
+		explanation.accordion.OpenAll()
+	})
+
+	return container.NewVScroll(container.NewVBox(
+		widget.NewLabelWithStyle("SSS Benefits",
+			fyne.TextAlignLeading,
+			fyne.TextStyle{Bold: true}),
+		widget.NewForm(
+			widget.NewFormItem("Benefit", benefitSelect),
+			widget.NewFormItem("Contingency or Retirement Date", dateEntry),
+			widget.NewFormItem("", miscarriageCheck),
+			widget.NewFormItem("Sick Days", daysEntry),
+		),
+		widget.NewLabelWithStyle("Contribution History",
+			fyne.TextAlignLeading,
+			fyne.TextStyle{Bold: true}),
+		container.NewGridWithColumns(3, salaryEntry, monthsEntry, fillBtn),
+		historyEntry,
+		estimateBtn,
+		widget.NewLabelWithStyle("Retirement Pension",
+			fyne.TextAlignLeading,
+			fyne.TextStyle{Bold: true}),
+		widget.NewForm(
+			widget.NewFormItem("Credited Years", yearsEntry),
+			widget.NewFormItem("Dependent Children", dependentsEntry),
+		),
+		pensionBtn,
+		explanation.accordion,
+	))
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"github.com/leekchan/accounting"
+	"github.com/shopspring/decimal"
+	"runfyne/payroll"
+)
+
+// Number of incomes sampled across the range of the charts
+const chartSamples = 400
+
+// Axis labels are rounded to the peso
+var wholePeso = accounting.Accounting{Symbol: "₱ ", Precision: 0}
+
+var (
+	netPayColor     = color.NRGBA{R: 0x2e, G: 0x7d, B: 0x32, A: 0xff}
+	deductionsColor = color.NRGBA{R: 0xc6, G: 0x28, B: 0x28, A: 0xff}
+	effectiveColor  = color.NRGBA{R: 0x15, G: 0x65, B: 0xc0, A: 0xff}
+	marginalColor   = color.NRGBA{R: 0xef, G: 0x6c, B: 0x00, A: 0xff}
+	axisColor       = color.NRGBA{R: 0x75, G: 0x75, B: 0x75, A: 0xff}
+	highlightColor  = color.NRGBA{R: 0x6a, G: 0x1b, B: 0x9a, A: 0xff}
+)
+
+// chartsView plots net pay, deductions and tax rates across a range of incomes
+type chartsView struct {
+	content   fyne.CanvasObject
+	setIncome func(decimal.Decimal)
+}
+
+func newChartsView(win fyne.Window) *chartsView {
+	v := &chartsView{}
+	income := -1.0
+
+	amounts := newLineChart("Monthly Amounts", func(y float64) string {
+		return wholePeso.FormatMoney(int64(math.Round(y)))
+	})
+	rates := newLineChart("Rates", func(y float64) string {
+		return fmt.Sprintf("%.0f%%", y*100)
+	})
+	// The marginal rate dives far below zero where a contribution jumps,
+	// keep the axis readable and let those dips run off the chart
+	rates.minY, rates.maxY = -1, 1
+
+	fromEntry := widget.NewEntry()
+	fromEntry.SetText("0")
+	toEntry := widget.NewEntry()
+	toEntry.SetText("150000")
+
+	plot := func() {
+		from, err1 := decimal.NewFromString(fromEntry.Text)
+		to, err2 := decimal.NewFromString(toEntry.Text)
+		if err1 != nil || err2 != nil || from.LessThan(decimal.Zero) || !to.GreaterThan(from) {
+			dialog.ShowInformation("Charts", "Enter an income range where From is below To", win)
+			return
+		}
+
+		xs, net, deductions, effective, marginal := sampleIncomes(from, to)
+		amounts.set(xs, income, []chartSeries{
+			{"Net Pay", netPayColor, net},
+			{"Total Deductions", deductionsColor, deductions},
+		})
+		rates.set(xs, income, []chartSeries{
+			{"Effective Tax Rate", effectiveColor, effective},
+			{"Marginal Take-Home Rate", marginalColor, marginal},
+		})
+	}
+
+	v.setIncome = func(d decimal.Decimal) {
+		income, _ = d.Float64()
+		// Widen the range so the current income is always on the chart
+		if to, err := decimal.NewFromString(toEntry.Text); err == nil && d.GreaterThan(to) {
+			toEntry.SetText(d.Mul(decimal.NewFromFloat(1.5)).Round(0).String())
+		}
+		plot()
+	}
+
+	plot()
+	v.content = container.NewBorder(
+		container.NewHBox(
+			widget.NewLabelWithStyle("Income From", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+			container.New(layout.NewGridWrapLayout(fyne.NewSize(120, fromEntry.MinSize().Height)), fromEntry),
+			widget.NewLabelWithStyle("To", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+			container.New(layout.NewGridWrapLayout(fyne.NewSize(120, toEntry.MinSize().Height)), toEntry),
+			widget.NewButton("Plot", plot),
+		),
+		nil, nil, nil,
+		container.NewGridWithRows(2, amounts, rates))
+	return v
+}
+
+// sampleIncomes runs incomes across the range through the calculators. The marginal
+// take-home rate is the share of the next peso earned that is kept, so it drops at
+// every SSS salary credit step and tax bracket
+func sampleIncomes(from, to decimal.Decimal) (xs, net, deductions, effective, marginal []float64) {
+	step := to.Sub(from).Div(decimal.NewFromInt(chartSamples))
+	prev := payroll.Compute(from)
+	for i := 0; i <= chartSamples; i++ {
+		x := from.Add(step.Mul(decimal.NewFromInt(int64(i))))
+		r := payroll.Compute(x)
+
+		xf, _ := x.Float64()
+		netf, _ := r.NetPayAfterDeductions.Float64()
+		dedf, _ := r.TotalDeductions.Float64()
+		xs = append(xs, xf)
+		net = append(net, netf)
+		deductions = append(deductions, dedf)
+
+		rate := 0.0
+		if x.GreaterThan(decimal.Zero) {
+			rate, _ = r.Tax.Div(x).Float64()
+		}
+		effective = append(effective, rate)
+
+		keep := 1.0
+		if i > 0 {
+			keep, _ = r.NetPayAfterDeductions.Sub(prev.NetPayAfterDeductions).Div(step).Float64()
+		}
+		marginal = append(marginal, keep)
+		prev = r
+	}
+	return
+}
+
+type chartSeries struct {
+	name   string
+	color  color.Color
+	values []float64
+}
+
+// lineChart draws series of values against incomes using canvas lines
+type lineChart struct {
+	widget.BaseWidget
+	title     string
+	formatY   func(float64) string
+	xs        []float64
+	series    []chartSeries
+	highlight float64 // income to mark on the curves, negative for none
+
+	// Fixed bounds of the value axis, both zero to fit the data
+	minY, maxY float64
+}
+
+func newLineChart(title string, formatY func(float64) string) *lineChart {
+	c := &lineChart{title: title, formatY: formatY, highlight: -1}
+	c.ExtendBaseWidget(c)
+	return c
+}
+
+func (c *lineChart) set(xs []float64, highlight float64, series []chartSeries) {
+	c.xs, c.highlight, c.series = xs, highlight, series
+	c.Refresh()
+}
+
+func (c *lineChart) CreateRenderer() fyne.WidgetRenderer {
+	return &lineChartRenderer{chart: c}
+}
+
+type lineChartRenderer struct {
+	chart   *lineChart
+	size    fyne.Size
+	objects []fyne.CanvasObject
+}
+
+func (r *lineChartRenderer) Layout(size fyne.Size) {
+	r.size = size
+	r.build()
+}
+
+func (r *lineChartRenderer) MinSize() fyne.Size { return fyne.NewSize(400, 200) }
+
+func (r *lineChartRenderer) Refresh() {
+	r.build()
+	canvas.Refresh(r.chart)
+}
+
+func (r *lineChartRenderer) Objects() []fyne.CanvasObject { return r.objects }
+
+func (r *lineChartRenderer) Destroy() {}
+
+// build redraws every primitive of the chart for the current size
+func (r *lineChartRenderer) build() {
+	c := r.chart
+	const right, top, bottom = 30, 40, 25
+	r.objects = nil
+	if len(c.xs) < 2 {
+		return
+	}
+
+	// Scale the axes to the data unless the bounds are fixed
+	minX, maxX := c.xs[0], c.xs[len(c.xs)-1]
+	minY, maxY := c.minY, c.maxY
+	if minY == 0 && maxY == 0 {
+		minY, maxY = math.Inf(1), math.Inf(-1)
+		for _, s := range c.series {
+			for _, y := range s.values {
+				minY, maxY = math.Min(minY, y), math.Max(maxY, y)
+			}
+		}
+		minY = math.Min(minY, 0)
+	}
+	if maxY <= minY {
+		maxY = minY + 1
+	}
+
+	// Value labels for five ticks, the widest one sets the left margin
+	var yLabels []*canvas.Text
+	var left float32
+	for i := 0; i <= 4; i++ {
+		label := canvas.NewText(c.formatY(minY+(maxY-minY)*float64(i)/4), axisColor)
+		label.TextSize = 10
+		yLabels = append(yLabels, label)
+		left = float32(math.Max(float64(left), float64(label.MinSize().Width)))
+	}
+	left += 10
+
+	title := canvas.NewText(c.title, axisColor)
+	title.TextStyle = fyne.TextStyle{Bold: true}
+	title.Move(fyne.NewPos(left, 2))
+	r.objects = append(r.objects, title)
+
+	width, height := r.size.Width-left-right, r.size.Height-top-bottom
+	if width <= 0 || height <= 0 {
+		return
+	}
+	toPos := func(x, y float64) fyne.Position {
+		y = math.Max(minY, math.Min(maxY, y))
+		return fyne.NewPos(
+			left+float32((x-minX)/(maxX-minX))*width,
+			top+height-float32((y-minY)/(maxY-minY))*height)
+	}
+
+	// Axes with five ticks each
+	r.line(toPos(minX, minY), toPos(maxX, minY), axisColor, 1)
+	r.line(toPos(minX, minY), toPos(minX, maxY), axisColor, 1)
+	for i, label := range yLabels {
+		pos := toPos(minX, minY+(maxY-minY)*float64(i)/4)
+		label.Move(fyne.NewPos(pos.X-label.MinSize().Width-4, pos.Y-label.MinSize().Height/2))
+		r.objects = append(r.objects, label)
+
+		x := minX + (maxX-minX)*float64(i)/4
+		xLabel := canvas.NewText(wholePeso.FormatMoney(int64(x)), axisColor)
+		xLabel.TextSize = 10
+		pos = toPos(x, minY)
+		xLabel.Move(fyne.NewPos(pos.X-xLabel.MinSize().Width/2, pos.Y+4))
+		r.objects = append(r.objects, xLabel)
+	}
+
+	// Curves, with a legend above the plot
+	legendX := float32(left + title.MinSize().Width + 20)
+	for _, s := range c.series {
+		for i := 1; i < len(c.xs); i++ {
+			r.line(toPos(c.xs[i-1], s.values[i-1]), toPos(c.xs[i], s.values[i]), s.color, 2)
+		}
+		legend := canvas.NewText(s.name, s.color)
+		legend.TextSize = 11
+		legend.Move(fyne.NewPos(legendX, 4))
+		legendX += legend.MinSize().Width + 16
+		r.objects = append(r.objects, legend)
+	}
+
+	// Mark the current income on every curve
+	if c.highlight < minX || c.highlight > maxX {
+		return
+	}
+	r.line(toPos(c.highlight, minY), toPos(c.highlight, maxY), highlightColor, 1)
+	i := int(math.Round((c.highlight - minX) / (maxX - minX) * float64(len(c.xs)-1)))
+	for _, s := range c.series {
+		pos := toPos(c.highlight, s.values[i])
+		dot := canvas.NewCircle(highlightColor)
+		dot.Resize(fyne.NewSize(8, 8))
+		dot.Move(fyne.NewPos(pos.X-4, pos.Y-4))
+		value := canvas.NewText(c.formatY(s.values[i]), highlightColor)
+		value.TextSize = 10
+		value.Move(fyne.NewPos(pos.X+6, pos.Y-14))
+		r.objects = append(r.objects, dot, value)
+	}
+}
+
+func (r *lineChartRenderer) line(from, to fyne.Position, c color.Color, width float32) {
+	l := canvas.NewLine(c)
+	l.StrokeWidth = width
+	l.Position1, l.Position2 = from, to
+	r.objects = append(r.objects, l)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shopspring/decimal"
+	"runfyne/payroll"
+)
+
+// compareTab holds several income scenarios and shows their breakdowns side by side
+func compareTab(win fyne.Window) fyne.CanvasObject {
+	var scenarios []decimal.Decimal
+
+	incomeEntry := widget.NewEntry()
+	incomeEntry.SetPlaceHolder("Enter a monthly income to compare")
+
+	grid := container.NewMax()
+	refresh := func() {
+		if len(scenarios) == 0 {
+			grid.Objects = []fyne.CanvasObject{widget.NewLabel("Add at least one scenario")}
+			grid.Refresh()
+			return
+		}
+		headers := make([]string, len(scenarios))
+		results := make([]payroll.TaxInputs, len(scenarios))
+		for i, income := range scenarios {
+			headers[i] = fmt.Sprintf("Scenario %d", i+1)
+			results[i] = payroll.Compute(income)
+		}
+		grid.Objects = []fyne.CanvasObject{container.NewScroll(comparisonGrid(headers, results))}
+		grid.Refresh()
+	}
+
+	addBtn := widget.NewButton("Add Scenario", func() {
+		income, err := decimal.NewFromString(incomeEntry.Text)
+		if err != nil || income.LessThan(decimal.Zero) {
+			dialog.ShowInformation("Compare", "Invalid monthly income input", win)
+			return
+		}
+		scenarios = append(scenarios, income)
+		incomeEntry.SetText("")
+		refresh()
+	})
+	removeBtn := widget.NewButton("Remove Last", func() {
+		if len(scenarios) > 0 {
+			scenarios = scenarios[:len(scenarios)-1]
+			refresh()
+		}
+	})
+	clearBtn := widget.NewButton("Clear", func() {
+		scenarios = nil
+		refresh()
+	})
+
+	refresh()
+	return container.NewBorder(
+		container.NewVBox(
+			widget.NewLabelWithStyle("Compare Scenarios",
+				fyne.TextAlignLeading,
+				fyne.TextStyle{Bold: true}),
+			container.NewBorder(nil, nil, nil, container.NewHBox(addBtn, removeBtn, clearBtn), incomeEntry),
+		),
+		nil, nil, nil,
+		grid)
+}
+
+// comparisonGrid lays out the breakdowns in columns, each followed by its
+// change from the column before, with the tax bracket and SSS range at the bottom
+func comparisonGrid(headers []string, results []payroll.TaxInputs) fyne.CanvasObject {
+	bold := fyne.TextStyle{Bold: true}
+	amount := func(d decimal.Decimal) fyne.CanvasObject {
+		return widget.NewLabelWithStyle(peso.FormatMoney(d), fyne.TextAlignTrailing, fyne.TextStyle{})
+	}
+
+	// Header row
+	cells := []fyne.CanvasObject{widget.NewLabel("")}
+	for i, h := range headers {
+		if i > 0 {
+			cells = append(cells, widget.NewLabelWithStyle("Change", fyne.TextAlignTrailing, bold))
+		}
+		cells = append(cells, widget.NewLabelWithStyle(h, fyne.TextAlignTrailing, bold))
+	}
+
+	// One row per field of the breakdown
+	fields := make([][]payroll.Field, len(results))
+	for i, r := range results {
+		fields[i] = r.Fields()
+	}
+	for f := range fields[0] {
+		cells = append(cells, widget.NewLabel(fields[0][f].Name))
+		for i := range results {
+			if i > 0 {
+				cells = append(cells, amount(fields[i][f].Value.Sub(fields[i-1][f].Value)))
+			}
+			cells = append(cells, amount(fields[i][f].Value))
+		}
+	}
+
+	// Where each scenario lands in the tax and SSS tables
+	textRow := func(name string, describe func(payroll.TaxInputs) string) {
+		cells = append(cells, widget.NewLabel(name))
+		for i, r := range results {
+			if i > 0 {
+				change := ""
+				if describe(r) != describe(results[i-1]) {
+					change = "changed"
+				}
+				cells = append(cells, widget.NewLabelWithStyle(change, fyne.TextAlignTrailing, fyne.TextStyle{Italic: true}))
+			}
+			label := widget.NewLabelWithStyle(describe(r), fyne.TextAlignTrailing, fyne.TextStyle{})
+			label.Wrapping = fyne.TextWrapWord
+			cells = append(cells, label)
+		}
+	}
+	textRow("Tax Bracket", func(r payroll.TaxInputs) string {
+		return payroll.MonthlyTaxBracket(r.TaxableIncome).String()
+	})
+	textRow("SSS Range", func(r payroll.TaxInputs) string {
+		return payroll.SSSRangeOf(r.MonthlyIncome).String()
+	})
+
+	return container.New(layout.NewGridLayout(2*len(results)), cells...)
+}
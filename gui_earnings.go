@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shopspring/decimal"
+	"runfyne/payroll"
+)
+
+// earningsEditor holds the earning lines entered besides the basic pay, such as
+// allowances, commissions and reimbursements
+type earningsEditor struct {
+	content *fyne.Container
+	rows    *fyne.Container
+	lines   []*earningRow
+}
+
+// earningRow is the widgets of one earning line
+type earningRow struct {
+	name     *widget.Entry
+	kind     *widget.Select
+	amount   *widget.Entry
+	taxable  *widget.Check
+	base     *widget.Check
+	row      *fyne.Container
+	typeName payroll.EarningType
+}
+
+func newEarningsEditor() *earningsEditor {
+	e := &earningsEditor{rows: container.NewVBox()}
+	addBtn := widget.NewButton("Add Earning", func() {
+		e.add(payroll.NewEarning(payroll.TaxableAllowance, decimal.Zero))
+	})
+	e.content = container.NewVBox(e.rows, container.NewHBox(addBtn))
+	return e
+}
+
+// add appends a row for an earning line
+func (e *earningsEditor) add(earning payroll.Earning) {
+	r := &earningRow{
+		name:     widget.NewEntry(),
+		amount:   widget.NewEntry(),
+		taxable:  widget.NewCheck("Taxable", nil),
+		base:     widget.NewCheck("SSS/PhilHealth/Pag-IBIG", nil),
+		typeName: earning.Type,
+	}
+	r.name.SetPlaceHolder("Name")
+	r.name.SetText(earning.Name)
+	r.amount.SetPlaceHolder("Amount")
+	if !earning.Amount.IsZero() {
+		r.amount.SetText(earning.Amount.String())
+	}
+	r.taxable.SetChecked(earning.Taxable)
+	r.base.SetChecked(earning.ContributionBase)
+
+	var types []string
+	for _, t := range payroll.EarningTypes {
+		if t != payroll.BasicPay {
+			types = append(types, t.String())
+		}
+	}
+	r.kind = widget.NewSelect(types, nil)
+	r.kind.SetSelected(earning.Type.String())
+
+	// Picking a type fills in its usual name and treatment, which can still be changed
+	r.kind.OnChanged = func(s string) {
+		t, _ := payroll.ParseEarningType(s)
+		if r.name.Text == "" || r.name.Text == r.typeName.String() {
+			r.name.SetText(t.String())
+		}
+		r.typeName = t
+		taxable, base := t.Defaults()
+		r.taxable.SetChecked(taxable)
+		r.base.SetChecked(base)
+	}
+
+	removeBtn := widget.NewButton("Remove", nil)
+	r.row = container.NewGridWithColumns(6, r.kind, r.name, r.amount, r.taxable, r.base, removeBtn)
+	removeBtn.OnTapped = func() {
+		for i, line := range e.lines {
+			if line == r {
+				e.lines = append(e.lines[:i], e.lines[i+1:]...)
+				break
+			}
+		}
+		e.rows.Remove(r.row)
+	}
+
+	e.lines = append(e.lines, r)
+	e.rows.Add(r.row)
+}
+
+// set replaces the rows with the given earning lines
+func (e *earningsEditor) set(earnings []payroll.Earning) {
+	e.lines = nil
+	e.rows.RemoveAll()
+	for _, earning := range earnings {
+		e.add(earning)
+	}
+}
+
+// earnings returns the basic pay followed by the lines entered, nil when
+// there are no other lines so the pay is computed as a single income
+func (e *earningsEditor) earnings(basic decimal.Decimal) ([]payroll.Earning, error) {
+	if len(e.lines) == 0 {
+		return nil, nil
+	}
+	earnings := []payroll.Earning{payroll.NewEarning(payroll.BasicPay, basic)}
+	for i, r := range e.lines {
+		amount, err := decimal.NewFromString(r.amount.Text)
+		if err != nil || amount.LessThan(decimal.Zero) {
+			return nil, fmt.Errorf("invalid amount for earning %d", i+1)
+		}
+		name := strings.TrimSpace(r.name.Text)
+		if name == "" {
+			name = r.typeName.String()
+		}
+		earnings = append(earnings, payroll.Earning{
+			Name:             name,
+			Type:             r.typeName,
+			Amount:           amount,
+			Taxable:          r.taxable.Checked,
+			ContributionBase: r.base.Checked,
+		})
+	}
+	return earnings, nil
+}
+
+// earningTreatment describes how an earning line is taxed and contributed on
+func earningTreatment(e payroll.Earning) string {
+	s := "non-taxable"
+	if e.Taxable {
+		s = "taxable"
+	}
+	if e.ContributionBase {
+		s += ", in contribution base"
+	}
+	return s
+}
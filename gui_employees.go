@@ -0,0 +1,227 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shopspring/decimal"
+	"runfyne/employee"
+	"runfyne/payroll"
+)
+
+// Dates are typed in the GUI as YYYY-MM-DD
+const dateLayout = "2006-01-02"
+
+// employeesTab builds the screen for adding, editing and searching employees
+func employeesTab(win fyne.Window, store *employee.Store) fyne.CanvasObject {
+	var shown []employee.Employee
+	selected := -1
+
+	list := widget.NewList(
+		func() int { return len(shown) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			e := shown[i]
+			o.(*widget.Label).SetText(fmt.Sprintf("%s\t%s\t%s", e.Name, e.TIN, e.PayFrequency))
+		})
+	list.OnSelected = func(i widget.ListItemID) { selected = i }
+	list.OnUnselected = func(widget.ListItemID) { selected = -1 }
+
+	searchEntry := widget.NewEntry()
+	searchEntry.SetPlaceHolder("Search by name or government number")
+	refresh := func() {
+		shown = store.Search(searchEntry.Text)
+		selected = -1
+		list.UnselectAll()
+		list.Refresh()
+	}
+	searchEntry.OnChanged = func(string) { refresh() }
+
+	addBtn := widget.NewButton("Add", func() {
+		showEmployeeForm(win, employee.Employee{PayFrequency: payroll.Monthly, TaxStatus: "S"}, func(e employee.Employee) error {
+			_, err := store.Add(e)
+			return err
+		}, refresh)
+	})
+	editBtn := widget.NewButton("Edit", func() {
+		if selected < 0 || selected >= len(shown) {
+			dialog.ShowInformation("Edit Employee", "Select an employee to edit", win)
+			return
+		}
+		showEmployeeForm(win, shown[selected], func(e employee.Employee) error {
+			_, err := store.Update(e)
+			return err
+		}, refresh)
+	})
+
+	refresh()
+	return container.NewBorder(
+		container.NewVBox(
+			widget.NewLabelWithStyle("Employees",
+				fyne.TextAlignLeading,
+				fyne.TextStyle{Bold: true}),
+			container.NewBorder(nil, nil, nil, container.NewHBox(addBtn, editBtn), searchEntry),
+		),
+		nil, nil, nil,
+		list)
+}
+
+// showEmployeeForm opens a dialog to edit e and hands the result to save
+func showEmployeeForm(win fyne.Window, e employee.Employee, save func(employee.Employee) error, done func()) {
+	nameEntry := widget.NewEntry()
+	nameEntry.SetText(e.Name)
+	nameEntry.Validator = func(s string) error {
+		if strings.TrimSpace(s) == "" {
+			return errors.New("name is required")
+		}
+		return nil
+	}
+
+	tinEntry := idEntry(e.TIN, "123-456-789-000", employee.NormalizeTIN)
+	sssEntry := idEntry(e.SSS, "34-1234567-8", employee.NormalizeSSS)
+	philhealthEntry := idEntry(e.PhilHealth, "12-345678901-2", employee.NormalizePhilHealth)
+	pagibigEntry := idEntry(e.PagIbig, "1234-5678-9012", employee.NormalizePagIbig)
+
+	birthEntry := dateEntry(e.BirthDate, false)
+	hireEntry := dateEntry(e.HireDate, false)
+	separatedEntry := dateEntry(e.SeparatedOn, true)
+
+	salaryEntry := widget.NewEntry()
+	salaryEntry.SetPlaceHolder("Monthly basic salary")
+	if !e.MonthlySalary.IsZero() {
+		salaryEntry.SetText(e.MonthlySalary.String())
+	}
+	salaryEntry.Validator = func(s string) error {
+		salary, err := decimal.NewFromString(s)
+		if err != nil || salary.LessThan(decimal.Zero) {
+			return errors.New("invalid salary")
+		}
+		return nil
+	}
+
+	frequencySelect := widget.NewSelect(frequencyOptions(), nil)
+	frequencySelect.SetSelected(string(e.PayFrequency))
+
+	statuses := make([]string, len(employee.TaxStatuses))
+	for i, s := range employee.TaxStatuses {
+		statuses[i] = string(s)
+	}
+	statusSelect := widget.NewSelect(statuses, nil)
+	statusSelect.SetSelected(string(e.TaxStatus))
+
+	types := make([]string, len(payroll.EmployeeTypes))
+	for i, t := range payroll.EmployeeTypes {
+		types[i] = string(t)
+	}
+	typeSelect := widget.NewSelect(types, nil)
+	typeSelect.SetSelected(string(payroll.Regular))
+	if e.EmployeeType != "" {
+		typeSelect.SetSelected(string(e.EmployeeType))
+	}
+	regionSelect := widget.NewSelect(append([]string{noRegion}, payroll.Current().Kasambahay.Regions()...), nil)
+	regionSelect.SetSelected(noRegion)
+	if e.Region != "" {
+		regionSelect.SetSelected(e.Region)
+	}
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Name", nameEntry),
+		widget.NewFormItem("TIN", tinEntry),
+		widget.NewFormItem("SSS Number", sssEntry),
+		widget.NewFormItem("PhilHealth PIN", philhealthEntry),
+		widget.NewFormItem("Pag-IBIG MID", pagibigEntry),
+		widget.NewFormItem("Birthdate", birthEntry),
+		widget.NewFormItem("Hire Date", hireEntry),
+		widget.NewFormItem("Separation Date", separatedEntry),
+		widget.NewFormItem("Pay Frequency", frequencySelect),
+		widget.NewFormItem("Monthly Salary", salaryEntry),
+		widget.NewFormItem("Tax Status", statusSelect),
+		widget.NewFormItem("Employee Type", typeSelect),
+		widget.NewFormItem("Kasambahay Region", regionSelect),
+	}
+
+	title := "Add Employee"
+	if e.ID != 0 {
+		title = "Edit Employee"
+	}
+	form := dialog.NewForm(title, "Save", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		// The entry validators already ran, so parsing cannot fail here
+		e.Name = nameEntry.Text
+		e.TIN = tinEntry.Text
+		e.SSS = sssEntry.Text
+		e.PhilHealth = philhealthEntry.Text
+		e.PagIbig = pagibigEntry.Text
+		e.BirthDate, _ = time.Parse(dateLayout, birthEntry.Text)
+		e.HireDate, _ = time.Parse(dateLayout, hireEntry.Text)
+		e.SeparatedOn, _ = time.Parse(dateLayout, separatedEntry.Text)
+		e.PayFrequency = payroll.PayFrequency(frequencySelect.Selected)
+		e.MonthlySalary, _ = decimal.NewFromString(salaryEntry.Text)
+		e.TaxStatus = employee.TaxStatus(statusSelect.Selected)
+		e.EmployeeType = payroll.EmployeeType(typeSelect.Selected)
+		e.Region = ""
+		if e.EmployeeType == payroll.Kasambahay && regionSelect.Selected != noRegion {
+			e.Region = regionSelect.Selected
+		}
+
+		if err := save(e); err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		done()
+	}, win)
+	form.Resize(fyne.NewSize(450, 0))
+	form.Show()
+}
+
+// frequencyOptions lists the pay frequencies for a select widget
+func frequencyOptions() []string {
+	options := make([]string, len(payroll.PayFrequencies))
+	for i, f := range payroll.PayFrequencies {
+		options[i] = string(f)
+	}
+	return options
+}
+
+// idEntry is an entry for an optional government number
+func idEntry(value, placeholder string, normalize func(string) (string, error)) *widget.Entry {
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder(placeholder)
+	entry.SetText(value)
+	entry.Validator = func(s string) error {
+		if strings.TrimSpace(s) == "" {
+			return nil
+		}
+		_, err := normalize(s)
+		return err
+	}
+	return entry
+}
+
+// dateEntry is an entry for a date, optional dates may be left blank
+func dateEntry(value time.Time, optional bool) *widget.Entry {
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder("YYYY-MM-DD")
+	if !value.IsZero() {
+		entry.SetText(value.Format(dateLayout))
+	}
+	entry.Validator = func(s string) error {
+		if optional && strings.TrimSpace(s) == "" {
+			return nil
+		}
+		if _, err := time.Parse(dateLayout, s); err != nil {
+			return errors.New("use the format YYYY-MM-DD")
+		}
+		return nil
+	}
+	return entry
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shopspring/decimal"
+	"runfyne/payroll"
+)
+
+// employerCostView shows what the salary on the calculator costs the employer,
+// monthly and for a year
+type employerCostView struct {
+	content fyne.CanvasObject
+	salary  decimal.Decimal
+	kind    payroll.EmployeeType
+	update  func()
+}
+
+func newEmployerCostView() *employerCostView {
+	v := &employerCostView{}
+
+	amount := func(text string) *widget.Entry {
+		entry := widget.NewEntry()
+		entry.SetText(text)
+		entry.Validator = func(s string) error {
+			if d, err := decimal.NewFromString(s); err != nil || d.IsNegative() {
+				return errors.New("invalid amount")
+			}
+			return nil
+		}
+		return entry
+	}
+	leaveEntry := amount(decimal.NewFromInt(payroll.ServiceIncentiveLeaveDays).String())
+	hmoEntry := amount("0")
+
+	grid := container.NewGridWithColumns(3)
+	v.update = func() {
+		leaveDays, err1 := decimal.NewFromString(leaveEntry.Text)
+		hmo, err2 := decimal.NewFromString(hmoEntry.Text)
+		if err1 != nil || err2 != nil {
+			return
+		}
+		monthly, err := payroll.ComputeEmployerCost(payroll.EmployerCostInputs{
+			MonthlySalary: v.salary,
+			LeaveDays:     leaveDays,
+			HMO:           hmo,
+			EmployeeType:  v.kind,
+		})
+		if err != nil {
+			return
+		}
+
+		grid.RemoveAll()
+		grid.Add(widget.NewLabel(""))
+		grid.Add(widget.NewLabelWithStyle("Monthly", fyne.TextAlignTrailing, fyne.TextStyle{Bold: true}))
+		grid.Add(widget.NewLabelWithStyle("Annual", fyne.TextAlignTrailing, fyne.TextStyle{Bold: true}))
+		annual := monthly.Annual().Fields()
+		for i, f := range monthly.Fields() {
+			grid.Add(widget.NewLabel(f.Name))
+			grid.Add(widget.NewLabelWithStyle(peso.FormatMoney(f.Value), fyne.TextAlignTrailing, fyne.TextStyle{}))
+			grid.Add(widget.NewLabelWithStyle(peso.FormatMoney(annual[i].Value), fyne.TextAlignTrailing, fyne.TextStyle{}))
+		}
+	}
+	leaveEntry.OnChanged = func(string) { v.update() }
+	hmoEntry.OnChanged = func(string) { v.update() }
+
+	v.content = container.NewVBox(
+		widget.NewLabelWithStyle("Employer Cost",
+			fyne.TextAlignLeading,
+			fyne.TextStyle{Bold: true}),
+		widget.NewForm(
+			widget.NewFormItem("Leave Days a Year", leaveEntry),
+			widget.NewFormItem("HMO per Month", hmoEntry),
+		),
+		grid,
+	)
+	v.update()
+	return v
+}
+
+// show computes the employer cost of a monthly salary paid to an employee of the given type
+func (v *employerCostView) show(monthlySalary decimal.Decimal, kind payroll.EmployeeType) {
+	v.salary = monthlySalary
+	v.kind = kind
+	v.update()
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"runfyne/payroll"
+)
+
+// explanationView shows, for each deduction, the rules applied to arrive at it
+type explanationView struct {
+	accordion *widget.Accordion
+}
+
+func newExplanationView() *explanationView {
+	return &explanationView{accordion: widget.NewAccordion()}
+}
+
+// show replaces the explanation with the traces of a computation
+func (v *explanationView) show(traces []payroll.Trace) {
+	for len(v.accordion.Items) > 0 {
+		v.accordion.RemoveIndex(0)
+	}
+	for _, t := range traces {
+		v.accordion.Append(widget.NewAccordionItem(t.Name+"  "+peso.FormatMoney(t.Result), traceDetail(t)))
+	}
+}
+
+// traceDetail lists the steps of a trace with their amounts on the right
+func traceDetail(t payroll.Trace) fyne.CanvasObject {
+	rows := container.NewVBox()
+	for _, s := range t.Steps {
+		rule := s.Rule
+		if s.Capped {
+			rule += " (limit reached)"
+		}
+		ruleLabel := widget.NewLabel(rule)
+		ruleLabel.Wrapping = fyne.TextWrapWord
+		amount := widget.NewLabelWithStyle(peso.FormatMoney(s.Amount), fyne.TextAlignTrailing, fyne.TextStyle{})
+		rows.Add(container.NewBorder(nil, nil, nil, amount, ruleLabel))
+	}
+	total := widget.NewLabelWithStyle(peso.FormatMoney(t.Result), fyne.TextAlignTrailing, fyne.TextStyle{Bold: true})
+	rows.Add(container.NewBorder(nil, nil, nil, total,
+		widget.NewLabelWithStyle(t.Name, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})))
+	return rows
+}
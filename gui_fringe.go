@@ -0,0 +1,132 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shopspring/decimal"
+	"runfyne/payroll"
+)
+
+// fringeTab computes the fringe benefits tax on managers' benefits, with the
+// quarterly totals filed on BIR Form 1603Q
+func fringeTab(win fyne.Window) fyne.CanvasObject {
+	var names []string
+	for _, t := range payroll.FringeBenefitTypes {
+		names = append(names, t.String())
+	}
+	typeSelect := widget.NewSelect(names, nil)
+	typeSelect.SetSelected(payroll.CompanyCar.String())
+	monthEntry := widget.NewEntry()
+	monthEntry.SetText(time.Now().Format("2006-01"))
+	amountEntry := widget.NewEntry()
+	amountEntry.SetPlaceHolder("Cost, monthly rent, market value or fees")
+	monthsEntry := widget.NewEntry()
+	monthsEntry.SetPlaceHolder("Months used, for cars and housing")
+	shareEntry := widget.NewEntry()
+	shareEntry.SetPlaceHolder("Paid by the employee")
+	descriptionEntry := widget.NewEntry()
+
+	benefitsEntry := widget.NewMultiLineEntry()
+	benefitsEntry.SetPlaceHolder("One benefit per line as YYYY-MM,type,amount,months,employee share,description\n2023-01,company-car,1500000,12")
+	benefitsEntry.SetMinRowsVisible(6)
+
+	// Adds the benefit in the form as a line of the list
+	addBtn := widget.NewButton("Add Benefit", func() {
+		t, err := payroll.ParseFringeBenefitType(typeSelect.Selected)
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		fields := []string{monthEntry.Text, string(t), amountEntry.Text, monthsEntry.Text, shareEntry.Text,
+			strings.ReplaceAll(descriptionEntry.Text, ",", " ")}
+		line := strings.TrimRight(strings.Join(fields, ","), ",")
+		parsed, err := payroll.ParseFringeBenefits(strings.NewReader(line))
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		if len(parsed) == 0 {
+			dialog.ShowInformation("Fringe Benefits", "Enter the month as YYYY-MM", win)
+			return
+		}
+		text := benefitsEntry.Text
+		if text != "" && !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+		benefitsEntry.SetText(text + line + "\n")
+		amountEntry.SetText("")
+		descriptionEntry.SetText("")
+	})
+
+	explanation := newExplanationView()
+	quarters := container.NewVBox()
+	computeBtn := widget.NewButton("Compute", func() {
+		benefits, err := payroll.ParseFringeBenefits(strings.NewReader(benefitsEntry.Text))
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		st, err := payroll.ComputeFringeBenefits(benefits)
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		explanation.show([]payroll.Trace{st.Trace()})
+		explanation.accordion.OpenAll()
+		quarters.RemoveAll()
+		quarters.Add(fringeQuarters(st.Quarters))
+	})
+
+	return container.NewVScroll(container.NewVBox(
+		widget.NewLabelWithStyle("Fringe Benefits Tax",
+			fyne.TextAlignLeading,
+			fyne.TextStyle{Bold: true}),
+		widget.NewForm(
+			widget.NewFormItem("Benefit", typeSelect),
+			widget.NewFormItem("Month", monthEntry),
+			widget.NewFormItem("Amount", amountEntry),
+			widget.NewFormItem("Months", monthsEntry),
+			widget.NewFormItem("Employee Share", shareEntry),
+			widget.NewFormItem("Description", descriptionEntry),
+		),
+		addBtn,
+		benefitsEntry,
+		computeBtn,
+		explanation.accordion,
+		quarters,
+	))
+}
+
+// fringeQuarters lays out the tax of each quarter month by month, as filed on BIR Form 1603Q
+func fringeQuarters(qs []payroll.FringeQuarter) fyne.CanvasObject {
+	grid := container.NewGridWithColumns(6)
+	for _, h := range []string{"Quarter", "Month", "Monetary Value", "Grossed-Up", "Tax", "File By"} {
+		grid.Add(widget.NewLabelWithStyle(h, fyne.TextAlignTrailing, fyne.TextStyle{Bold: true}))
+	}
+	cell := func(s string, bold bool) *widget.Label {
+		return widget.NewLabelWithStyle(s, fyne.TextAlignTrailing, fyne.TextStyle{Bold: bold})
+	}
+	row := func(quarter, month string, value, grossedUp, tax decimal.Decimal, fileBy string, bold bool) {
+		grid.Add(cell(quarter, bold))
+		grid.Add(cell(month, bold))
+		grid.Add(cell(peso.FormatMoney(value), bold))
+		grid.Add(cell(peso.FormatMoney(grossedUp), bold))
+		grid.Add(cell(peso.FormatMoney(tax), bold))
+		grid.Add(cell(fileBy, bold))
+	}
+	for _, q := range qs {
+		for _, m := range q.Months {
+			row("", m.Month.Format("Jan 2006"), m.Value, m.GrossedUp, m.Tax, "", false)
+		}
+		row(q.Name(), "", q.Value, q.GrossedUp, q.Tax, q.Deadline.Format(dateLayout), true)
+	}
+	return container.NewVBox(
+		widget.NewLabelWithStyle("Quarterly Remittance (BIR Form 1603Q)", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		grid,
+	)
+}
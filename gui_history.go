@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"runfyne/history"
+	"runfyne/payroll"
+)
+
+// historyView lists past computations so they can be reopened, compared or deleted
+type historyView struct {
+	content  fyne.CanvasObject
+	onReopen func(history.Entry)
+	refresh  func()
+}
+
+// Refresh reloads the list after a computation was saved
+func (v *historyView) Refresh() { v.refresh() }
+
+func newHistoryView(win fyne.Window, store *history.Store) *historyView {
+	v := &historyView{}
+
+	var shown []history.Entry
+	selected := -1
+	marked := map[int]bool{} // entries ticked for comparison
+
+	list := widget.NewList(
+		func() int { return len(shown) },
+		func() fyne.CanvasObject {
+			return container.NewHBox(widget.NewCheck("", nil), widget.NewLabel(""))
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			e := shown[i]
+			row := o.(*fyne.Container)
+			check := row.Objects[0].(*widget.Check)
+			check.OnChanged = nil
+			check.SetChecked(marked[e.ID])
+			check.OnChanged = func(on bool) { marked[e.ID] = on }
+			row.Objects[1].(*widget.Label).SetText(fmt.Sprintf("%s   Income %s   Net Pay %s   (rules %s)",
+				e.Time.Format("2006-01-02 15:04"),
+				peso.FormatMoney(e.Income),
+				peso.FormatMoney(e.Result.NetPayAfterDeductions),
+				e.Rules))
+		})
+	list.OnSelected = func(i widget.ListItemID) { selected = i }
+	list.OnUnselected = func(widget.ListItemID) { selected = -1 }
+
+	v.refresh = func() {
+		shown = store.All()
+		for id := range marked {
+			if _, ok := store.Get(id); !ok {
+				delete(marked, id)
+			}
+		}
+		selected = -1
+		list.UnselectAll()
+		list.Refresh()
+	}
+
+	// withSelected runs f on the highlighted entry
+	withSelected := func(f func(history.Entry)) {
+		if selected < 0 || selected >= len(shown) {
+			dialog.ShowInformation("History", "Select a computation first", win)
+			return
+		}
+		f(shown[selected])
+	}
+
+	reopenBtn := widget.NewButton("Reopen", func() {
+		withSelected(func(e history.Entry) {
+			if v.onReopen != nil {
+				v.onReopen(e)
+			}
+		})
+	})
+	deleteBtn := widget.NewButton("Delete", func() {
+		withSelected(func(e history.Entry) {
+			dialog.ShowConfirm("Delete Computation",
+				fmt.Sprintf("Delete the computation from %s?", e.Time.Format("2006-01-02 15:04")),
+				func(ok bool) {
+					if !ok {
+						return
+					}
+					if err := store.Delete(e.ID); err != nil {
+						dialog.ShowError(err, win)
+					}
+					v.refresh()
+				}, win)
+		})
+	})
+	compareBtn := widget.NewButton("Compare", func() {
+		var entries []history.Entry
+		for _, e := range shown {
+			if marked[e.ID] {
+				entries = append(entries, e)
+			}
+		}
+		if len(entries) < 2 {
+			dialog.ShowInformation("Compare", "Tick at least two computations to compare", win)
+			return
+		}
+		showHistoryComparison(win, entries)
+	})
+
+	v.refresh()
+	v.content = container.NewBorder(
+		container.NewVBox(
+			widget.NewLabelWithStyle("Calculation History",
+				fyne.TextAlignLeading,
+				fyne.TextStyle{Bold: true}),
+			container.NewHBox(reopenBtn, compareBtn, deleteBtn),
+		),
+		nil, nil, nil,
+		list)
+	return v
+}
+
+// showHistoryComparison puts the breakdowns side by side, oldest first
+func showHistoryComparison(win fyne.Window, entries []history.Entry) {
+	var headers []string
+	var results []payroll.TaxInputs
+	for i := len(entries) - 1; i >= 0; i-- {
+		headers = append(headers, entries[i].Time.Format("01-02 15:04"))
+		results = append(results, entries[i].Result)
+	}
+
+	scroll := container.NewScroll(comparisonGrid(headers, results))
+	scroll.SetMinSize(fyne.NewSize(700, 400))
+	dialog.ShowCustom("Compare Computations", "Close", scroll, win)
+}
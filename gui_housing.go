@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shopspring/decimal"
+	"runfyne/loan"
+	"runfyne/payroll"
+)
+
+// housingTab finds the Pag-IBIG housing loan a monthly income affords and
+// lists its amortization schedule
+func housingTab(win fyne.Window) fyne.CanvasObject {
+	incomeEntry := widget.NewEntry()
+	incomeEntry.SetPlaceHolder("Monthly gross income")
+	desiredEntry := widget.NewEntry()
+	desiredEntry.SetPlaceHolder("Amount to borrow, the most allowed when empty")
+	termEntry := widget.NewEntry()
+	termEntry.SetText("30")
+	ageEntry := widget.NewEntry()
+	ageEntry.SetPlaceHolder("Borrower's age, not checked when empty")
+
+	fixingSelect := widget.NewSelect(housingPeriods(payroll.Current()), nil)
+	if len(fixingSelect.Options) > 1 {
+		fixingSelect.SetSelectedIndex(1)
+	} else {
+		fixingSelect.SetSelectedIndex(0)
+	}
+
+	explanation := newExplanationView()
+	scheduleLabel := widget.NewLabel("")
+	computeBtn := widget.NewButton("Compute", func() {
+		income, err := decimal.NewFromString(incomeEntry.Text)
+		if err != nil {
+			dialog.ShowInformation("Housing Loan", "Enter the monthly gross income", win)
+			return
+		}
+		desired := decimal.Zero
+		if desiredEntry.Text != "" {
+			if desired, err = decimal.NewFromString(desiredEntry.Text); err != nil {
+				dialog.ShowInformation("Housing Loan", "Invalid amount to borrow", win)
+				return
+			}
+		}
+		term, err := strconv.Atoi(termEntry.Text)
+		if err != nil {
+			dialog.ShowInformation("Housing Loan", "Enter the term in years", win)
+			return
+		}
+		age := 0
+		if ageEntry.Text != "" {
+			if age, err = strconv.Atoi(ageEntry.Text); err != nil {
+				dialog.ShowInformation("Housing Loan", "Invalid age", win)
+				return
+			}
+		}
+		rules := payroll.Current()
+
+		// The rules may have been reloaded since the periods were listed
+		fixingYears := 0
+		for _, r := range rules.PagIbig.Housing.Rates {
+			if housingPeriod(r) == fixingSelect.Selected {
+				fixingYears = r.FixingYears
+			}
+		}
+		if fixingYears == 0 {
+			fixingSelect.Options = housingPeriods(rules)
+			fixingSelect.ClearSelected()
+			dialog.ShowInformation("Housing Loan", "Choose a repricing period from the current rates", win)
+			return
+		}
+
+		netPay, err := loan.MonthlyNetPay(rules, income, payroll.Options{})
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		st, err := loan.ComputeHousing(rules, loan.HousingInputs{
+			NetPay:      netPay,
+			TermYears:   term,
+			FixingYears: fixingYears,
+			Age:         age,
+			Desired:     desired,
+		})
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		explanation.show([]payroll.Trace{st.Trace()})
+		explanation.accordion.OpenAll()
+		scheduleLabel.SetText(housingSchedule(st))
+	})
+
+	return container.NewVScroll(container.NewVBox(
+		widget.NewLabelWithStyle("Pag-IBIG Housing Loan",
+			fyne.TextAlignLeading,
+			fyne.TextStyle{Bold: true}),
+		widget.NewForm(
+			widget.NewFormItem("Monthly Income", incomeEntry),
+			widget.NewFormItem("Amount", desiredEntry),
+			widget.NewFormItem("Term (years)", termEntry),
+			widget.NewFormItem("Repricing Period", fixingSelect),
+			widget.NewFormItem("Age", ageEntry),
+		),
+		computeBtn,
+		explanation.accordion,
+		scheduleLabel,
+	))
+}
+
+// housingPeriods lists the repricing periods of the housing loan rates
+func housingPeriods(rules *payroll.Rules) []string {
+	var periods []string
+	for _, r := range rules.PagIbig.Housing.Rates {
+		periods = append(periods, housingPeriod(r))
+	}
+	return periods
+}
+
+// housingPeriod labels a repricing period with its rate
+func housingPeriod(r payroll.HousingRate) string {
+	return fmt.Sprintf("%d years at %s%%", r.FixingYears, r.Rate.Mul(decimal.NewFromInt(100)))
+}
+
+// housingSchedule lays out the amortization schedule of a housing loan as text
+func housingSchedule(st loan.HousingStatement) string {
+	var b strings.Builder
+	b.WriteString("Amortization Schedule, the rate assumed fixed for the whole term\n\n")
+	b.WriteString("No.\tDue\t\tAmount\t\tInterest\tPrincipal\tBalance\n")
+	for _, in := range st.Schedule() {
+		fmt.Fprintf(&b, "%d\t%s\t%s\t%s\t%s\t%s\n", in.Number,
+			in.Due.Format(dateLayout),
+			peso.FormatMoney(in.Amount),
+			peso.FormatMoney(in.Interest),
+			peso.FormatMoney(in.Principal),
+			peso.FormatMoney(in.Balance))
+	}
+	return b.String()
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shopspring/decimal"
+	"runfyne/employee"
+	"runfyne/loan"
+)
+
+// loansView lists the employees' loans with their remaining balances
+type loansView struct {
+	content fyne.CanvasObject
+	refresh func()
+}
+
+// Refresh reloads the balances after a payroll run was locked or reopened
+func (v *loansView) Refresh() { v.refresh() }
+
+func newLoansView(win fyne.Window, store *loan.Store, employees *employee.Store) *loansView {
+	v := &loansView{}
+
+	var shown []loan.Loan
+	selected := -1
+
+	employeeName := func(id int) string {
+		if e, ok := employees.Get(id); ok {
+			return e.Name
+		}
+		return fmt.Sprintf("employee %d", id)
+	}
+
+	list := widget.NewList(
+		func() int { return len(shown) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			l := shown[i]
+			status := "Balance " + peso.FormatMoney(l.Balance())
+			if l.PaidOff() {
+				status = "Paid off"
+			}
+			o.(*widget.Label).SetText(fmt.Sprintf("%s   %s\n  Principal %s   Amortization %s/month   %s",
+				employeeName(l.EmployeeID), l.Label(),
+				peso.FormatMoney(l.Principal),
+				peso.FormatMoney(l.MonthlyAmortization()),
+				status))
+		})
+	list.OnSelected = func(i widget.ListItemID) { selected = i }
+	list.OnUnselected = func(widget.ListItemID) { selected = -1 }
+
+	v.refresh = func() {
+		shown = store.All()
+		selected = -1
+		list.UnselectAll()
+		list.Refresh()
+	}
+
+	addBtn := widget.NewButton("Add", func() {
+		showLoanForm(win, employees.All(), func(l loan.Loan) error {
+			_, err := store.Add(l)
+			return err
+		}, v.refresh)
+	})
+	scheduleBtn := widget.NewButton("Schedule", func() {
+		if selected < 0 || selected >= len(shown) {
+			dialog.ShowInformation("Loans", "Select a loan first", win)
+			return
+		}
+		showLoanSchedule(win, shown[selected], employeeName(shown[selected].EmployeeID))
+	})
+
+	v.refresh()
+	v.content = container.NewBorder(
+		container.NewVBox(
+			widget.NewLabelWithStyle("Loans",
+				fyne.TextAlignLeading,
+				fyne.TextStyle{Bold: true}),
+			container.NewHBox(addBtn, scheduleBtn),
+		),
+		nil, nil, nil,
+		list)
+	return v
+}
+
+// showLoanForm asks for the terms of a new loan and hands it to save
+func showLoanForm(win fyne.Window, staff []employee.Employee, save func(loan.Loan) error, done func()) {
+	if len(staff) == 0 {
+		dialog.ShowInformation("Add Loan", "Add the employee to the register first", win)
+		return
+	}
+	names := make([]string, len(staff))
+	for i, e := range staff {
+		names[i] = e.Name
+	}
+	employeeSelect := widget.NewSelect(names, nil)
+
+	descriptionEntry := widget.NewEntry()
+	descriptionEntry.SetPlaceHolder("Optional, e.g. the loan reference number")
+
+	amountEntry := func(placeholder string) *widget.Entry {
+		entry := widget.NewEntry()
+		entry.SetPlaceHolder(placeholder)
+		entry.Validator = func(s string) error {
+			d, err := decimal.NewFromString(s)
+			if err != nil || d.IsNegative() {
+				return errors.New("invalid amount")
+			}
+			return nil
+		}
+		return entry
+	}
+	principalEntry := amountEntry("Amount borrowed")
+	rateEntry := amountEntry("Annual interest in percent")
+	termEntry := amountEntry("Number of monthly installments")
+
+	kinds := make([]string, len(loan.Kinds))
+	for i, k := range loan.Kinds {
+		kinds[i] = k.String()
+	}
+	kindSelect := widget.NewSelect(kinds, func(s string) {
+		k, _ := loan.ParseKind(s)
+		rateEntry.SetText(k.DefaultRate().Mul(decimal.NewFromInt(100)).String())
+	})
+	kindSelect.SetSelected(loan.SSSSalary.String())
+	termEntry.SetText("24")
+
+	startEntry := dateEntry(time.Now(), false)
+
+	form := dialog.NewForm("Add Loan", "Save", "Cancel", []*widget.FormItem{
+		widget.NewFormItem("Employee", employeeSelect),
+		widget.NewFormItem("Kind", kindSelect),
+		widget.NewFormItem("Description", descriptionEntry),
+		widget.NewFormItem("Principal", principalEntry),
+		widget.NewFormItem("Interest (%/year)", rateEntry),
+		widget.NewFormItem("Term (months)", termEntry),
+		widget.NewFormItem("First Deduction", startEntry),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		// The entry validators already ran, Validate catches the rest
+		l := loan.Loan{Description: descriptionEntry.Text}
+		if i := employeeSelect.SelectedIndex(); i >= 0 {
+			l.EmployeeID = staff[i].ID
+		}
+		l.Kind, _ = loan.ParseKind(kindSelect.Selected)
+		l.Principal, _ = decimal.NewFromString(principalEntry.Text)
+		rate, _ := decimal.NewFromString(rateEntry.Text)
+		l.AnnualRate = rate.Div(decimal.NewFromInt(100))
+		term, _ := decimal.NewFromString(termEntry.Text)
+		l.TermMonths = int(term.IntPart())
+		l.StartDate, _ = time.Parse(dateLayout, startEntry.Text)
+
+		if err := save(l); err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		done()
+	}, win)
+	form.Resize(fyne.NewSize(450, 0))
+	form.Show()
+}
+
+// showLoanSchedule shows the amortization schedule of a loan and what has been paid
+func showLoanSchedule(win fyne.Window, l loan.Loan, name string) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s, %s\n", name, l.Label())
+	fmt.Fprintf(&b, "Principal %s at %s%% a year over %d months\n",
+		peso.FormatMoney(l.Principal),
+		l.AnnualRate.Mul(decimal.NewFromInt(100)).String(),
+		l.TermMonths)
+	fmt.Fprintf(&b, "Total payable %s   Paid %s   Balance %s\n\n",
+		peso.FormatMoney(l.TotalPayable()),
+		peso.FormatMoney(l.Paid()),
+		peso.FormatMoney(l.Balance()))
+
+	b.WriteString("No.\tDue\t\tAmount\t\tInterest\tPrincipal\tBalance\n")
+	for _, in := range l.Schedule() {
+		fmt.Fprintf(&b, "%d\t%s\t%s\t%s\t%s\t%s\n", in.Number,
+			in.Due.Format(dateLayout),
+			peso.FormatMoney(in.Amount),
+			peso.FormatMoney(in.Interest),
+			peso.FormatMoney(in.Principal),
+			peso.FormatMoney(in.Balance))
+	}
+
+	if len(l.Payments) > 0 {
+		b.WriteString("\nPayments\n")
+		for _, p := range l.Payments {
+			fmt.Fprintf(&b, "%s\t%s\tpayroll run %d\n", p.Date.Format(dateLayout), peso.FormatMoney(p.Amount), p.RunID)
+		}
+	}
+
+	scroll := container.NewVScroll(widget.NewLabel(b.String()))
+	scroll.SetMinSize(fyne.NewSize(650, 400))
+	dialog.ShowCustom("Amortization Schedule", "Close", scroll, win)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shopspring/decimal"
+	"runfyne/payroll"
+)
+
+// membersTab computes the contributions of members who pay their own, such as
+// the self-employed, voluntary members and OFWs, on the earnings they declare
+func membersTab(win fyne.Window) fyne.CanvasObject {
+	earningsEntry := widget.NewEntry()
+	earningsEntry.SetPlaceHolder("Declared monthly earnings")
+
+	var sssTypes []string
+	for _, m := range payroll.SSSMemberTypes {
+		sssTypes = append(sssTypes, m.String())
+	}
+	sssSelect := widget.NewSelect(sssTypes, nil)
+	sssSelect.SetSelected(payroll.SelfEmployed.String())
+
+	var categories []string
+	for _, c := range payroll.PhilHealthCategories {
+		categories = append(categories, c.String())
+	}
+	philHealthSelect := widget.NewSelect(categories, nil)
+	philHealthSelect.SetSelected(payroll.SelfEarning.String())
+
+	creditLabel := widget.NewLabel("")
+	rateLabel := widget.NewLabel("")
+	sssLabel := widget.NewLabel("")
+	philHealthLabel := widget.NewLabel("")
+	totalLabel := widget.NewLabel("")
+	explanation := newExplanationView()
+
+	computeBtn := widget.NewButton("Compute", func() {
+		earnings, err := decimal.NewFromString(earningsEntry.Text)
+		if err != nil || earnings.LessThan(decimal.Zero) {
+			dialog.ShowInformation("Members", "Invalid monthly earnings input", win)
+			return
+		}
+		m, err := payroll.ParseSSSMemberType(sssSelect.Selected)
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		c, err := payroll.ParsePhilHealthCategory(philHealthSelect.Selected)
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+
+		rules := payroll.Current()
+		sss, err := rules.ComputeSSSMember(m, earnings)
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		sssTrace, err := rules.ExplainSSSMember(m, earnings)
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		philHealthTrace, err := rules.ExplainPhilHealthMember(c, earnings)
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		creditLabel.SetText(peso.FormatMoney(sss.Range.SalaryCredit))
+		rateLabel.SetText(sss.Rate.Mul(decimal.NewFromInt(100)).String() + "%")
+		sssLabel.SetText(peso.FormatMoney(sss.Contribution))
+		philHealthLabel.SetText(peso.FormatMoney(philHealthTrace.Result))
+		totalLabel.SetText(peso.FormatMoney(sss.Contribution.Add(philHealthTrace.Result)))
+		explanation.show([]payroll.Trace{sssTrace, philHealthTrace})
+	})
+
+	return container.NewVScroll(container.NewVBox(
+		widget.NewLabelWithStyle("Contributions Paid by the Member",
+			fyne.TextAlignLeading,
+			fyne.TextStyle{Bold: true}),
+		widget.NewForm(
+			widget.NewFormItem("Monthly Earnings", earningsEntry),
+			widget.NewFormItem("SSS Member Type", sssSelect),
+			widget.NewFormItem("PhilHealth Category", philHealthSelect),
+		),
+		computeBtn,
+		widget.NewForm(
+			widget.NewFormItem("SSS Salary Credit", creditLabel),
+			widget.NewFormItem("SSS Rate", rateLabel),
+			widget.NewFormItem("SSS Contribution", sssLabel),
+			widget.NewFormItem("PhilHealth Premium", philHealthLabel),
+			widget.NewFormItem("Total", totalLabel),
+		),
+		explanation.accordion,
+	))
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shopspring/decimal"
+	"runfyne/mp2"
+)
+
+// mp2Tab projects MP2 savings year by year, with the dividends compounded and
+// paid out yearly side by side
+func mp2Tab(win fyne.Window) fyne.CanvasObject {
+	monthlyEntry := widget.NewEntry()
+	monthlyEntry.SetPlaceHolder("Saved every month")
+	lumpSumEntry := widget.NewEntry()
+	lumpSumEntry.SetPlaceHolder("Saved once at the start")
+	startEntry := widget.NewEntry()
+	startEntry.SetText(strconv.Itoa(time.Now().Year()))
+	yearsEntry := widget.NewEntry()
+	yearsEntry.SetText(strconv.Itoa(mp2.Term))
+	declared := mp2.DividendHistory(nil)
+	last := declared[len(declared)-1]
+	assumedEntry := widget.NewEntry()
+	assumedEntry.SetText(last.Rate.Mul(decimal.NewFromInt(100)).String())
+
+	var history string
+	for _, r := range declared {
+		history += fmt.Sprintf("%d: %s%%   ", r.Year, r.Rate.Mul(decimal.NewFromInt(100)))
+	}
+	historyLabel := widget.NewLabel("Declared dividend rates  " + history)
+	historyLabel.Wrapping = fyne.TextWrapWord
+
+	results := container.NewVBox()
+	var schedules []mp2.Schedule
+
+	amount := func(s string) (decimal.Decimal, error) {
+		if s == "" {
+			return decimal.Zero, nil
+		}
+		return decimal.NewFromString(s)
+	}
+	projectBtn := widget.NewButton("Project", func() {
+		monthly, err1 := amount(monthlyEntry.Text)
+		lumpSum, err2 := amount(lumpSumEntry.Text)
+		assumed, err3 := decimal.NewFromString(assumedEntry.Text)
+		start, err4 := strconv.Atoi(startEntry.Text)
+		years, err5 := strconv.Atoi(yearsEntry.Text)
+		if err := errors.Join(err1, err2, err3, err4, err5); err != nil {
+			dialog.ShowInformation("MP2", "Enter amounts, years and a rate in numbers", win)
+			return
+		}
+		if years <= 0 {
+			dialog.ShowInformation("MP2", "Keep the savings for at least one year", win)
+			return
+		}
+		compounded, yearly, err := mp2.Compare(mp2.Plan{
+			StartYear:   start,
+			Years:       years,
+			Monthly:     monthly,
+			LumpSum:     lumpSum,
+			AssumedRate: assumed.Div(decimal.NewFromInt(100)),
+		})
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		schedules = []mp2.Schedule{compounded, yearly}
+
+		results.RemoveAll()
+		for _, s := range schedules {
+			results.Add(mp2Schedule(s))
+		}
+	})
+
+	exportBtn := widget.NewButton("Export CSV", func() {
+		if len(schedules) == 0 {
+			dialog.ShowInformation("MP2", "Project the savings first", win)
+			return
+		}
+		save := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			if w == nil {
+				return // cancelled
+			}
+			defer w.Close()
+			if err := mp2.WriteCSV(w, schedules...); err != nil {
+				dialog.ShowError(err, win)
+			}
+		}, win)
+		save.SetFileName("mp2.csv")
+		save.Show()
+	})
+
+	return container.NewVScroll(container.NewVBox(
+		widget.NewLabelWithStyle("Pag-IBIG MP2 Savings",
+			fyne.TextAlignLeading,
+			fyne.TextStyle{Bold: true}),
+		widget.NewForm(
+			widget.NewFormItem("Monthly Contribution", monthlyEntry),
+			widget.NewFormItem("Lump Sum", lumpSumEntry),
+			widget.NewFormItem("Start Year", startEntry),
+			widget.NewFormItem("Years", yearsEntry),
+			widget.NewFormItem("Assumed Rate (%)", assumedEntry),
+		),
+		historyLabel,
+		container.NewHBox(projectBtn, exportBtn),
+		results,
+	))
+}
+
+// mp2Schedule lays out a projection as a table, marking the years at the assumed rate
+func mp2Schedule(s mp2.Schedule) fyne.CanvasObject {
+	grid := container.NewGridWithColumns(7)
+	for _, h := range []string{"Year", "Rate", "Opening", "Contributions", "Dividend", "Paid Out", "Closing"} {
+		grid.Add(widget.NewLabelWithStyle(h, fyne.TextAlignTrailing, fyne.TextStyle{Bold: true}))
+	}
+	cell := func(s string) *widget.Label {
+		return widget.NewLabelWithStyle(s, fyne.TextAlignTrailing, fyne.TextStyle{})
+	}
+	for _, y := range s.Years {
+		rate := y.Rate.Mul(decimal.NewFromInt(100)).String() + "%"
+		if y.Assumed {
+			rate += "*"
+		}
+		grid.Add(cell(strconv.Itoa(y.Year)))
+		grid.Add(cell(rate))
+		grid.Add(cell(peso.FormatMoney(y.Opening)))
+		grid.Add(cell(peso.FormatMoney(y.Contributions)))
+		grid.Add(cell(peso.FormatMoney(y.Dividend)))
+		grid.Add(cell(peso.FormatMoney(y.PaidOut)))
+		grid.Add(cell(peso.FormatMoney(y.Closing)))
+	}
+	return container.NewVBox(
+		widget.NewLabelWithStyle(s.Payout.String(), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		grid,
+		widget.NewLabel(fmt.Sprintf("Total received %s, * assumed rate", peso.FormatMoney(s.Total))),
+	)
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/user"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"runfyne/employee"
+	"runfyne/loan"
+	"runfyne/payroll"
+	"runfyne/payrun"
+)
+
+// currentUser is the name recorded in audit trails
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return "unknown"
+}
+
+// payrollTab builds the screen for creating, computing, approving and locking payroll runs
+func payrollTab(win fyne.Window, runs *payrun.Store, employees *employee.Store, loans *loan.Store) fyne.CanvasObject {
+	var (
+		shown   []*payrun.Run
+		current *payrun.Run
+	)
+
+	statusLabel := widget.NewLabel("Select or create a payroll run")
+	totalsLabel := widget.NewLabel("")
+	lines := widget.NewList(
+		func() int {
+			if current == nil {
+				return 0
+			}
+			return len(current.Lines)
+		},
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			l := current.Lines[i]
+			text := fmt.Sprintf("%s\n  Gross %s   Deductions %s   Net %s", l.Name,
+				peso.FormatMoney(l.MonthlyIncome),
+				peso.FormatMoney(l.TotalDeductions),
+				peso.FormatMoney(l.NetPayAfterDeductions))
+			if len(l.Loans) > 0 {
+				text += fmt.Sprintf("   Loans %s   Take-Home %s",
+					peso.FormatMoney(l.LoanDeductions()),
+					peso.FormatMoney(l.TakeHomePay))
+			}
+			o.(*widget.Label).SetText(text)
+		})
+
+	showRun := func(r *payrun.Run) {
+		current = r
+		if r == nil {
+			statusLabel.SetText("Select or create a payroll run")
+			totalsLabel.SetText("")
+		} else {
+			status := fmt.Sprintf("%s   Status: %s", runTitle(r), strings.ToUpper(string(r.Status)))
+			if r.Rules != "" {
+				status += "   Rules: " + r.Rules
+			}
+			statusLabel.SetText(status)
+			t := r.Totals
+			totalsLabel.SetText(fmt.Sprintf(
+				"Employees\t%d\nGross Pay\t%s\nSSS\t\t%s\nPhilHealth\t%s\nPag-IBIG\t%s\nIncome Tax\t%s\nNet Pay\t%s\nLoans\t\t%s\nTake-Home Pay\t%s",
+				t.Employees,
+				peso.FormatMoney(t.GrossPay),
+				peso.FormatMoney(t.SSSContributions),
+				peso.FormatMoney(t.PhilHealthContributions),
+				peso.FormatMoney(t.PagIbigContributions),
+				peso.FormatMoney(t.Tax),
+				peso.FormatMoney(t.NetPay),
+				peso.FormatMoney(t.LoanDeductions),
+				peso.FormatMoney(t.TakeHomePay)))
+		}
+		lines.Refresh()
+	}
+
+	runList := widget.NewList(
+		func() int { return len(shown) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(fmt.Sprintf("%s [%s]", runTitle(shown[i]), shown[i].Status))
+		})
+	runList.OnSelected = func(i widget.ListItemID) { showRun(shown[i]) }
+
+	refresh := func(selectID int) {
+		shown = runs.All()
+		runList.UnselectAll()
+		runList.Refresh()
+		showRun(nil)
+		for i, r := range shown {
+			if r.ID == selectID {
+				runList.Select(i)
+			}
+		}
+	}
+
+	// update applies an action to the selected run and saves it. Saving a
+	// locked or reopened run updates the loan balances as well
+	update := func(action func(r *payrun.Run) error) {
+		if current == nil {
+			dialog.ShowInformation("Payroll", "Select a payroll run first", win)
+			return
+		}
+		r, ok := runs.Get(current.ID)
+		if !ok {
+			dialog.ShowInformation("Payroll", "The payroll run no longer exists", win)
+			refresh(0)
+			return
+		}
+		if err := action(r); err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		if err := runs.Save(r); err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		refresh(r.ID)
+	}
+
+	newBtn := widget.NewButton("New Run", func() {
+		showNewRunForm(win, func(r *payrun.Run) {
+			if err := runs.Save(r); err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			refresh(r.ID)
+		})
+	})
+	computeBtn := widget.NewButton("Compute", func() {
+		update(func(r *payrun.Run) error { return r.Compute(employees.All(), loans.All(), currentUser()) })
+	})
+	approveBtn := widget.NewButton("Approve", func() {
+		update(func(r *payrun.Run) error { return r.Approve(currentUser()) })
+	})
+	lockBtn := widget.NewButton("Lock", func() {
+		update(func(r *payrun.Run) error { return r.Lock(currentUser()) })
+	})
+	reopenBtn := widget.NewButton("Reopen", func() {
+		reasonEntry := widget.NewEntry()
+		reasonEntry.Validator = func(s string) error {
+			if strings.TrimSpace(s) == "" {
+				return errors.New("a reason is required")
+			}
+			return nil
+		}
+		dialog.ShowForm("Reopen Payroll Run", "Reopen", "Cancel",
+			[]*widget.FormItem{widget.NewFormItem("Reason", reasonEntry)},
+			func(ok bool) {
+				if ok {
+					update(func(r *payrun.Run) error { return r.Reopen(currentUser(), reasonEntry.Text) })
+				}
+			}, win)
+	})
+	auditBtn := widget.NewButton("Audit Trail", func() {
+		if current == nil {
+			return
+		}
+		var b strings.Builder
+		for _, a := range current.Audit {
+			fmt.Fprintf(&b, "%s  %s  %s", a.Time.Format("2006-01-02 15:04"), a.User, a.Action)
+			if a.Detail != "" {
+				fmt.Fprintf(&b, ": %s", a.Detail)
+			}
+			b.WriteString("\n")
+		}
+		scroll := container.NewVScroll(widget.NewLabel(b.String()))
+		scroll.SetMinSize(fyne.NewSize(500, 300))
+		dialog.ShowCustom("Audit Trail", "Close", scroll, win)
+	})
+
+	details := container.NewBorder(
+		container.NewVBox(
+			statusLabel,
+			container.NewHBox(computeBtn, approveBtn, lockBtn, reopenBtn, auditBtn),
+			totalsLabel,
+			widget.NewLabelWithStyle("Employees",
+				fyne.TextAlignLeading,
+				fyne.TextStyle{Bold: true}),
+		),
+		nil, nil, nil,
+		lines)
+
+	refresh(0)
+	split := container.NewHSplit(
+		container.NewBorder(newBtn, nil, nil, nil, runList),
+		details)
+	split.Offset = 0.3
+	return split
+}
+
+// showNewRunForm asks for the cutoff period of a new run
+func showNewRunForm(win fyne.Window, created func(*payrun.Run)) {
+	startEntry := dateEntry(time.Time{}, false)
+	endEntry := dateEntry(time.Time{}, false)
+
+	frequencySelect := widget.NewSelect(frequencyOptions(), nil)
+	frequencySelect.SetSelected(string(payroll.Monthly))
+
+	dialog.ShowForm("New Payroll Run", "Create", "Cancel", []*widget.FormItem{
+		widget.NewFormItem("Cutoff Start", startEntry),
+		widget.NewFormItem("Cutoff End", endEntry),
+		widget.NewFormItem("Pay Frequency", frequencySelect),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+		start, _ := time.Parse(dateLayout, startEntry.Text)
+		end, _ := time.Parse(dateLayout, endEntry.Text)
+		r, err := payrun.New(start, end, payroll.PayFrequency(frequencySelect.Selected), currentUser())
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		created(r)
+	}, win)
+}
+
+func runTitle(r *payrun.Run) string {
+	return fmt.Sprintf("%s to %s %s", r.PeriodStart.Format(dateLayout), r.PeriodEnd.Format(dateLayout), r.PayFrequency)
+}
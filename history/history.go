@@ -0,0 +1,128 @@
+// Package history keeps every computation made in the calculator so past results can be revisited.
+package history
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"runfyne/payroll"
+	"runfyne/storage"
+)
+
+// Entry is one saved computation
+type Entry struct {
+	ID     int
+	Time   time.Time
+	Income decimal.Decimal // the monthly income as entered
+	Rules  string          // version of the payroll rules at the time of the computation
+	Result payroll.TaxInputs
+
+	// Options the income was computed with, the employee type and region
+	Options payroll.Options
+}
+
+// Store keeps the saved computations, written to a JSON file after every change
+type Store struct {
+	mu      sync.Mutex
+	path    string
+	nextID  int
+	entries map[int]Entry
+}
+
+// storeFile is the layout of the history on disk
+type storeFile struct {
+	NextID  int
+	Entries []Entry
+}
+
+// NewStore returns an empty history backed by the file at path
+func NewStore(path string) *Store {
+	return &Store{path: path, nextID: 1, entries: map[int]Entry{}}
+}
+
+// Load reads the history from disk, a missing file gives an empty history
+func (s *Store) Load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file := storeFile{NextID: 1}
+	if err := storage.ReadJSON(s.path, &file); err != nil {
+		return fmt.Errorf("loading history: %w", err)
+	}
+	s.nextID = file.NextID
+	s.entries = make(map[int]Entry, len(file.Entries))
+	for _, e := range file.Entries {
+		s.entries[e.ID] = e
+		if e.ID >= s.nextID {
+			s.nextID = e.ID + 1
+		}
+	}
+	return nil
+}
+
+// Add saves a computation made with the given rules and returns it with its
+// ID and timestamp filled in
+func (s *Store) Add(income decimal.Decimal, rules *payroll.Rules, opts payroll.Options, result payroll.TaxInputs) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e := Entry{ID: s.nextID, Time: time.Now(), Income: income, Rules: rules.Version, Options: opts, Result: result}
+	s.entries[e.ID] = e
+	s.nextID++
+	if err := s.save(); err != nil {
+		delete(s.entries, e.ID)
+		s.nextID--
+		return Entry{}, err
+	}
+	return e, nil
+}
+
+// Delete removes a saved computation
+func (s *Store) Delete(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, ok := s.entries[id]
+	if !ok {
+		return fmt.Errorf("history entry %d does not exist", id)
+	}
+	delete(s.entries, id)
+	if err := s.save(); err != nil {
+		s.entries[id] = old
+		return err
+	}
+	return nil
+}
+
+// Get returns the saved computation with the given ID
+func (s *Store) Get(id int) (Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[id]
+	return e, ok
+}
+
+// All returns every saved computation, the latest first
+func (s *Store) All() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := make([]Entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID > entries[j].ID })
+	return entries
+}
+
+func (s *Store) save() error {
+	file := storeFile{NextID: s.nextID}
+	for _, e := range s.entries {
+		file.Entries = append(file.Entries, e)
+	}
+	sort.Slice(file.Entries, func(i, j int) bool { return file.Entries[i].ID < file.Entries[j].ID })
+	return storage.WriteJSON(s.path, file)
+}
@@ -0,0 +1,71 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"runfyne/payroll"
+)
+
+// addComputed computes a monthly income and records it in the store
+func addComputed(t *testing.T, s *Store, income int64) Entry {
+	t.Helper()
+	rules := payroll.Current()
+	opts := payroll.Options{PayFrequency: payroll.Monthly}
+	result, err := rules.ComputeWith(decimal.NewFromInt(income), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := s.Add(decimal.NewFromInt(income), rules, opts, result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	s := NewStore(path)
+	for _, income := range []int64{20000, 33333, 50000} {
+		addComputed(t, s, income)
+	}
+	if err := s.Delete(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete(1); err == nil {
+		t.Error("deleted entry 1 twice")
+	}
+
+	// Entries read back from disk keep their IDs, rules and results, latest first
+	loaded := NewStore(path)
+	if err := loaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	all := loaded.All()
+	want := []struct {
+		id     int
+		income int64
+		netPay string
+	}{
+		{3, 50000, "42731.60"},
+		{2, 33333, "29588.01"},
+	}
+	if len(all) != len(want) {
+		t.Fatalf("%d entries, want %d", len(all), len(want))
+	}
+	for i, w := range want {
+		e := all[i]
+		if e.ID != w.id || e.Income.IntPart() != w.income || e.Rules != payroll.Current().Version ||
+			e.Result.NetPayAfterDeductions.StringFixed(2) != w.netPay {
+			t.Errorf("entry %d: %+v", i, e)
+		}
+	}
+
+	if e := addComputed(t, loaded, 1000); e.ID != 4 {
+		t.Errorf("new entry got ID %d, want 4 after the deleted ones", e.ID)
+	}
+	if _, ok := loaded.Get(1); ok {
+		t.Error("deleted entry 1 is still there")
+	}
+}
@@ -0,0 +1,167 @@
+package loan
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"runfyne/payroll"
+)
+
+// HousingInputs is a borrower's pay and the Pag-IBIG housing loan terms asked for
+type HousingInputs struct {
+	NetPay      decimal.Decimal // monthly net pay after deductions
+	TermYears   int
+	FixingYears int             // repricing period the interest is fixed for
+	Age         int             // borrower's age when applying, not checked when zero
+	Desired     decimal.Decimal // amount asked for, zero for the most the pay allows
+	StartDate   time.Time       // first amortization, the first of next month when zero
+}
+
+// HousingStatement is the result of ComputeHousing
+type HousingStatement struct {
+	NetPay          decimal.Decimal
+	CapacityRatio   decimal.Decimal
+	MaxAmortization decimal.Decimal // the most of the net pay the amortization may take
+	FixingYears     int
+	Rate            decimal.Decimal
+	TermYears       int
+	Capacity        decimal.Decimal // principal the largest amortization repays over the term
+	MaxLoan         decimal.Decimal
+	Desired         decimal.Decimal
+	Loanable        decimal.Decimal // the lowest of the capacity, the program limit and the amount asked for
+	Limit           string          // which of them set the loanable amount
+	Loan            Loan            // the loan at the loanable amount
+	Amortization    decimal.Decimal
+	TotalInterest   decimal.Decimal
+}
+
+// MonthlyNetPay computes the monthly net pay after deductions left by a gross
+// income for one pay period, the pay ComputeHousing weighs the amortization
+// against. Rules are the current ones when nil
+func MonthlyNetPay(rules *payroll.Rules, income decimal.Decimal, opts payroll.Options) (decimal.Decimal, error) {
+	if rules == nil {
+		rules = payroll.Current()
+	}
+	r, err := rules.ComputeWith(income, opts)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return opts.PayFrequency.ToMonthly(r.NetPayAfterDeductions).Round(2), nil
+}
+
+// ComputeHousing finds the most Pag-IBIG lends for a home on the given net pay
+// and the loan's amortization. The rate of the repricing period is assumed to
+// hold for the whole term, though Pag-IBIG resets it when the period ends.
+// Rules are the current ones when nil
+func ComputeHousing(rules *payroll.Rules, in HousingInputs) (HousingStatement, error) {
+	if rules == nil {
+		rules = payroll.Current()
+	}
+	h := rules.PagIbig.Housing
+
+	var errs []error
+	if !in.NetPay.IsPositive() {
+		errs = append(errs, errors.New("net pay must be more than zero"))
+	}
+	if in.TermYears < 1 || in.TermYears > h.MaxTermYears {
+		errs = append(errs, fmt.Errorf("term must be from 1 to %d years", h.MaxTermYears))
+	} else if in.Age > 0 && in.Age+in.TermYears > h.MaxAge {
+		errs = append(errs, fmt.Errorf("the loan must be repaid by age %d, at most %d years at age %d",
+			h.MaxAge, max0(h.MaxAge-in.Age), in.Age))
+	}
+	if in.FixingYears > in.TermYears && in.TermYears > 0 {
+		errs = append(errs, errors.New("repricing period cannot be longer than the term"))
+	}
+	if in.Desired.IsNegative() {
+		errs = append(errs, errors.New("amount asked for cannot be negative"))
+	}
+	rate, err := h.RateFor(in.FixingYears)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return HousingStatement{}, err
+	}
+
+	st := HousingStatement{
+		NetPay:        in.NetPay,
+		CapacityRatio: h.CapacityRatio,
+		FixingYears:   in.FixingYears,
+		Rate:          rate,
+		TermYears:     in.TermYears,
+		MaxLoan:       h.MaxLoan,
+		Desired:       in.Desired,
+	}
+	st.MaxAmortization = in.NetPay.Mul(h.CapacityRatio).RoundFloor(2)
+
+	// The principal an amortization A repays over n months at the monthly rate i
+	// is A × ((1 + i)^n − 1) / (i × (1 + i)^n), rounded down to the peso
+	months := in.TermYears * 12
+	n := decimal.NewFromInt(int64(months))
+	if rate.IsZero() {
+		st.Capacity = st.MaxAmortization.Mul(n).RoundFloor(0)
+	} else {
+		i := rate.Div(twelve)
+		growth := i.Add(decimal.NewFromInt(1)).Pow(n)
+		st.Capacity = st.MaxAmortization.Mul(growth.Sub(decimal.NewFromInt(1))).
+			Div(i.Mul(growth)).RoundFloor(0)
+	}
+
+	st.Loanable, st.Limit = st.Capacity, "capacity to pay"
+	if st.MaxLoan.LessThan(st.Loanable) {
+		st.Loanable, st.Limit = st.MaxLoan, "program limit"
+	}
+	if st.Desired.IsPositive() && st.Desired.LessThan(st.Loanable) {
+		st.Loanable, st.Limit = st.Desired, "amount asked for"
+	}
+
+	start := in.StartDate
+	if start.IsZero() {
+		now := time.Now()
+		start = time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.Local)
+	}
+	st.Loan = Loan{
+		Description: fmt.Sprintf("%d-year repricing", in.FixingYears),
+		Principal:   st.Loanable,
+		AnnualRate:  rate,
+		TermMonths:  months,
+		StartDate:   start,
+	}
+	st.Amortization = st.Loan.MonthlyAmortization()
+	st.TotalInterest = st.Loan.TotalPayable().Sub(st.Loanable)
+	return st, nil
+}
+
+// Schedule lists every monthly installment of the loan
+func (st HousingStatement) Schedule() []Installment {
+	return st.Loan.Schedule()
+}
+
+// Trace lists how the loanable amount was arrived at
+func (st HousingStatement) Trace() payroll.Trace {
+	t := payroll.Trace{Name: "Pag-IBIG Housing Loan"}
+	step := func(s payroll.Step) { t.Steps = append(t.Steps, s) }
+	step(payroll.Step{Rule: "Net pay after deductions", Amount: st.NetPay})
+	step(payroll.Step{Rule: "Largest amortization, share of net pay", Amount: st.MaxAmortization, Rate: st.CapacityRatio})
+	step(payroll.Step{Rule: fmt.Sprintf("Repaid over %d years, fixed for %d", st.TermYears, st.FixingYears),
+		Amount: st.Capacity, Rate: st.Rate})
+	step(payroll.Step{Rule: "Program limit", Amount: st.MaxLoan, Capped: st.Limit == "program limit"})
+	if st.Desired.IsPositive() {
+		step(payroll.Step{Rule: "Amount asked for", Amount: st.Desired})
+	}
+	step(payroll.Step{Rule: "Loanable amount, set by the " + st.Limit, Amount: st.Loanable})
+	step(payroll.Step{Rule: "Monthly amortization", Amount: st.Amortization})
+	step(payroll.Step{Rule: "Total interest over the term", Amount: st.TotalInterest})
+	t.Result = st.Loanable
+	return t
+}
+
+// max0 is n, or zero when n is negative
+func max0(n int) int {
+	if n < 0 {
+		return 0
+	}
+	return n
+}
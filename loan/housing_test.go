@@ -0,0 +1,90 @@
+package loan
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"runfyne/payroll"
+)
+
+func TestComputeHousing(t *testing.T) {
+	// 35% of 42,731.60 is 14,956.06, which repays 2,429,046 at 6.25% over 30 years
+	netPay := decimal.RequireFromString("42731.60")
+
+	limits := []struct {
+		name     string
+		in       HousingInputs
+		limit    string
+		loanable int64
+	}{
+		{"capacity", HousingInputs{NetPay: netPay, TermYears: 30, FixingYears: 3}, "capacity to pay", 2429046},
+		{"program limit", HousingInputs{NetPay: decimal.NewFromInt(200000), TermYears: 30, FixingYears: 3}, "program limit", 6000000},
+		{"amount asked for", HousingInputs{NetPay: netPay, TermYears: 30, FixingYears: 3, Desired: decimal.NewFromInt(1000000)}, "amount asked for", 1000000},
+	}
+	for _, tt := range limits {
+		t.Run(tt.name, func(t *testing.T) {
+			st, err := ComputeHousing(nil, tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if st.Limit != tt.limit || !st.Loanable.Equal(decimal.NewFromInt(tt.loanable)) {
+				t.Errorf("%s set by %q, want %d by %q", st.Loanable, st.Limit, tt.loanable, tt.limit)
+			}
+		})
+	}
+
+	refused := map[string]HousingInputs{
+		"past the age limit":       {NetPay: netPay, TermYears: 30, FixingYears: 3, Age: 45},
+		"unknown repricing period": {NetPay: netPay, TermYears: 30, FixingYears: 2},
+		"no net pay":               {TermYears: 30, FixingYears: 3},
+	}
+	for name, in := range refused {
+		t.Run(name, func(t *testing.T) {
+			if st, err := ComputeHousing(nil, in); err == nil {
+				t.Errorf("lent %s", st.Loanable)
+			}
+		})
+	}
+}
+
+// TestHousingPeriodFromRules checks the repricing period is looked up in the
+// rules passed in rather than the current ones
+func TestHousingPeriodFromRules(t *testing.T) {
+	rules := *payroll.Current()
+	rules.PagIbig.Housing.Rates = []payroll.HousingRate{{FixingYears: 5, Rate: decimal.RequireFromString("0.07")}}
+
+	in := HousingInputs{NetPay: decimal.NewFromInt(40000), TermYears: 20, FixingYears: 5}
+	st, err := ComputeHousing(&rules, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !st.Rate.Equal(decimal.RequireFromString("0.07")) {
+		t.Errorf("rate %s, want 0.07", st.Rate)
+	}
+
+	in.FixingYears = 3
+	if _, err := ComputeHousing(&rules, in); err == nil || !strings.Contains(err.Error(), "use 5") {
+		t.Errorf("period missing from the rules: error %v", err)
+	}
+}
+
+func TestMonthlyNetPay(t *testing.T) {
+	for _, tt := range []struct {
+		income    int64
+		frequency payroll.PayFrequency
+		want      string
+	}{
+		{50000, payroll.Monthly, "42731.60"},
+		{25000, payroll.SemiMonthly, "42731.60"}, // the same monthly pay in two halves
+		{33333, payroll.Monthly, "29588.01"},
+	} {
+		got, err := MonthlyNetPay(nil, decimal.NewFromInt(tt.income), payroll.Options{PayFrequency: tt.frequency})
+		if err != nil {
+			t.Fatalf("%d %s: %v", tt.income, tt.frequency, err)
+		}
+		if got.StringFixed(2) != tt.want {
+			t.Errorf("%d %s: net pay %s, want %s", tt.income, tt.frequency, got.StringFixed(2), tt.want)
+		}
+	}
+}
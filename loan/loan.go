@@ -0,0 +1,210 @@
+// Package loan keeps the salary loans and cash advances repaid through payroll,
+// with their amortization schedules and remaining balances.
+package loan
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"runfyne/payroll"
+)
+
+// Kind is who the loan was taken from
+type Kind string
+
+const (
+	SSSSalary  Kind = "sss-salary"
+	PagIbigMPL Kind = "pagibig-mpl"
+	Company    Kind = "company"
+)
+
+// Kinds lists the loan kinds in display order
+var Kinds = []Kind{SSSSalary, PagIbigMPL, Company}
+
+// String names the kind on screens and payslips
+func (k Kind) String() string {
+	switch k {
+	case SSSSalary:
+		return "SSS Salary Loan"
+	case PagIbigMPL:
+		return "Pag-IBIG Multi-Purpose Loan"
+	case Company:
+		return "Company Loan"
+	}
+	return string(k)
+}
+
+// DefaultRate is the usual annual interest of a kind of loan, diminishing
+// balance. Company cash advances are usually interest free
+func (k Kind) DefaultRate() decimal.Decimal {
+	switch k {
+	case SSSSalary:
+		return decimal.RequireFromString("0.10")
+	case PagIbigMPL:
+		return decimal.RequireFromString("0.105")
+	}
+	return decimal.Zero
+}
+
+// ParseKind converts a name such as "sss-salary" into a Kind
+func ParseKind(s string) (Kind, error) {
+	for _, k := range Kinds {
+		if string(k) == s || k.String() == s {
+			return k, nil
+		}
+	}
+	return "", fmt.Errorf("unknown loan kind %q", s)
+}
+
+// Payment is an installment taken from a locked payroll run
+type Payment struct {
+	RunID  int
+	Date   time.Time
+	Amount decimal.Decimal
+}
+
+// Loan is one loan of an employee, repaid in equal monthly amortizations
+// starting with the first payroll on or after StartDate
+type Loan struct {
+	ID          int
+	EmployeeID  int
+	Kind        Kind
+	Description string
+	Principal   decimal.Decimal
+	AnnualRate  decimal.Decimal // interest on the diminishing balance, 0.10 for 10%
+	TermMonths  int
+	StartDate   time.Time
+	Payments    []Payment
+}
+
+// Validate checks the loan's terms
+func (l *Loan) Validate() error {
+	var errs []error
+
+	l.Description = strings.TrimSpace(l.Description)
+	if l.EmployeeID == 0 {
+		errs = append(errs, errors.New("employee is required"))
+	}
+	if _, err := ParseKind(string(l.Kind)); err != nil {
+		errs = append(errs, err)
+	}
+	if !l.Principal.IsPositive() {
+		errs = append(errs, errors.New("principal must be more than zero"))
+	}
+	if l.AnnualRate.IsNegative() || !l.AnnualRate.LessThan(decimal.NewFromInt(1)) {
+		errs = append(errs, errors.New("annual interest must be from 0% to below 100%"))
+	}
+	if l.TermMonths < 1 || l.TermMonths > 360 {
+		errs = append(errs, errors.New("term must be from 1 to 360 months"))
+	}
+	if l.StartDate.IsZero() {
+		errs = append(errs, errors.New("start date is required"))
+	}
+	return errors.Join(errs...)
+}
+
+// Label names the loan on payslips, its description when it has one
+func (l Loan) Label() string {
+	if l.Description != "" {
+		return fmt.Sprintf("%s (%s)", l.Kind, l.Description)
+	}
+	return l.Kind.String()
+}
+
+var twelve = decimal.NewFromInt(12)
+
+// MonthlyAmortization is the equal monthly payment that repays the principal
+// and interest over the term: P × i / (1 − (1 + i)^−n) with i the monthly rate
+func (l Loan) MonthlyAmortization() decimal.Decimal {
+	if l.TermMonths < 1 {
+		return decimal.Zero
+	}
+	n := decimal.NewFromInt(int64(l.TermMonths))
+	if l.AnnualRate.IsZero() {
+		return l.Principal.Div(n).RoundUp(2)
+	}
+	i := l.AnnualRate.Div(twelve)
+	growth := i.Add(decimal.NewFromInt(1)).Pow(n)
+	return l.Principal.Mul(i).Mul(growth).Div(growth.Sub(decimal.NewFromInt(1))).Round(2)
+}
+
+// Installment is one month of the amortization schedule
+type Installment struct {
+	Number    int
+	Due       time.Time
+	Amount    decimal.Decimal
+	Interest  decimal.Decimal
+	Principal decimal.Decimal
+	Balance   decimal.Decimal // principal left after this installment
+}
+
+// Schedule lists every monthly installment. The last one is adjusted so the
+// rounding of the others leaves nothing owing
+func (l Loan) Schedule() []Installment {
+	amortization := l.MonthlyAmortization()
+	i := l.AnnualRate.Div(twelve)
+	balance := l.Principal
+
+	schedule := make([]Installment, 0, l.TermMonths)
+	for n := 1; n <= l.TermMonths; n++ {
+		in := Installment{Number: n, Due: l.StartDate.AddDate(0, n-1, 0)}
+		in.Interest = balance.Mul(i).Round(2)
+		in.Principal = amortization.Sub(in.Interest)
+		if n == l.TermMonths || in.Principal.GreaterThan(balance) {
+			in.Principal = balance
+		}
+		in.Amount = in.Interest.Add(in.Principal)
+		balance = balance.Sub(in.Principal)
+		in.Balance = balance
+		schedule = append(schedule, in)
+		if balance.IsZero() {
+			break
+		}
+	}
+	return schedule
+}
+
+// TotalPayable is the principal plus all interest over the term
+func (l Loan) TotalPayable() decimal.Decimal {
+	total := decimal.Zero
+	for _, in := range l.Schedule() {
+		total = total.Add(in.Amount)
+	}
+	return total
+}
+
+// Paid is the sum of the installments taken from payroll so far
+func (l Loan) Paid() decimal.Decimal {
+	paid := decimal.Zero
+	for _, p := range l.Payments {
+		paid = paid.Add(p.Amount)
+	}
+	return paid
+}
+
+// Balance is what is still owed, principal and interest
+func (l Loan) Balance() decimal.Decimal {
+	return decimal.Max(decimal.Zero, l.TotalPayable().Sub(l.Paid()))
+}
+
+// PaidOff reports whether nothing is owed any more
+func (l Loan) PaidOff() bool {
+	return !l.Balance().IsPositive()
+}
+
+// Due is the installment to take from a payroll with the given frequency and
+// cutoff end: the monthly amortization split across the paydays of a month,
+// never more than the balance. Nothing is due before the loan starts
+func (l Loan) Due(freq payroll.PayFrequency, periodEnd time.Time) decimal.Decimal {
+	if periodEnd.Before(l.StartDate) {
+		return decimal.Zero
+	}
+	due := l.MonthlyAmortization()
+	if freq != payroll.Monthly && freq != "" {
+		due = due.Mul(twelve).Div(freq.PeriodsPerYear()).Round(2)
+	}
+	return decimal.Min(due, l.Balance())
+}
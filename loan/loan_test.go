@@ -0,0 +1,111 @@
+package loan
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"runfyne/payroll"
+)
+
+var january = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// cashAdvance is a 12,000 company loan repaid over a year without interest
+func cashAdvance() Loan {
+	return Loan{EmployeeID: 1, Kind: Company, Principal: decimal.NewFromInt(12000), TermMonths: 12, StartDate: january}
+}
+
+func TestMonthlyAmortization(t *testing.T) {
+	loans := map[string]Loan{
+		"14956.05": {Principal: decimal.NewFromInt(2429046), AnnualRate: decimal.RequireFromString("0.0625"), TermMonths: 360},
+		"1000":     cashAdvance(),
+		"3333.34":  {Principal: decimal.NewFromInt(10000), TermMonths: 3}, // rounded up so the term repays it
+		"0":        {Principal: decimal.NewFromInt(10000), TermMonths: 0},
+	}
+	for want, l := range loans {
+		if got := l.MonthlyAmortization(); got.String() != want {
+			t.Errorf("%s at %s over %d months: %s, want %s", l.Principal, l.AnnualRate, l.TermMonths, got, want)
+		}
+	}
+}
+
+func TestScheduleRepaysThePrincipal(t *testing.T) {
+	for _, l := range []Loan{
+		{Principal: decimal.NewFromInt(2429046), AnnualRate: decimal.RequireFromString("0.0625"), TermMonths: 360, StartDate: january},
+		{Principal: decimal.NewFromInt(30000), AnnualRate: decimal.RequireFromString("0.10"), TermMonths: 24, StartDate: january},
+		{Principal: decimal.NewFromInt(10000), TermMonths: 3, StartDate: january},
+	} {
+		schedule := l.Schedule()
+		repaid := decimal.Zero
+		for _, in := range schedule {
+			repaid = repaid.Add(in.Principal)
+		}
+		last := schedule[len(schedule)-1]
+		if len(schedule) != l.TermMonths || !repaid.Equal(l.Principal) || !last.Balance.IsZero() {
+			t.Errorf("%s over %d months: %d installments repay %s, %s left",
+				l.Principal, l.TermMonths, len(schedule), repaid, last.Balance)
+		}
+		if !last.Due.Equal(january.AddDate(0, l.TermMonths-1, 0)) {
+			t.Errorf("%s over %d months: last due %s", l.Principal, l.TermMonths, last.Due.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestDue(t *testing.T) {
+	l := cashAdvance()
+	if got := l.Due(payroll.Monthly, january.AddDate(0, 0, -1)); !got.IsZero() {
+		t.Errorf("due before the loan starts: %s", got)
+	}
+	if got := l.Due(payroll.SemiMonthly, january.AddDate(0, 0, 14)); got.String() != "500" {
+		t.Errorf("semi-monthly installment %s, want 500", got)
+	}
+
+	// Only the balance is taken at the end
+	l.Payments = []Payment{{RunID: 1, Amount: decimal.NewFromInt(11400)}}
+	if got := l.Due(payroll.Monthly, january.AddDate(0, 11, 0)); got.String() != "600" {
+		t.Errorf("last installment %s, want the 600 left", got)
+	}
+}
+
+func TestStorePayments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "loans.json")
+	s := NewStore(path)
+	l, err := s.Add(cashAdvance())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paid := func(want int64) {
+		t.Helper()
+		got, _ := s.Get(l.ID)
+		if !got.Paid().Equal(decimal.NewFromInt(want)) {
+			t.Errorf("%s paid, want %d", got.Paid(), want)
+		}
+	}
+	if err := s.RecordPayments(7, january, map[int]decimal.Decimal{l.ID: decimal.NewFromInt(1000)}); err != nil {
+		t.Fatal(err)
+	}
+	paid(1000)
+	// Recording the same run again replaces its payment rather than adding one
+	if err := s.RecordPayments(7, january, map[int]decimal.Decimal{l.ID: decimal.NewFromInt(1000)}); err != nil {
+		t.Fatal(err)
+	}
+	paid(1000)
+	if err := s.RecordPayments(8, january, map[int]decimal.Decimal{99: decimal.NewFromInt(1)}); err == nil {
+		t.Error("payment to a loan that does not exist")
+	}
+
+	reloaded := NewStore(path)
+	if err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := reloaded.Get(l.ID); !got.Paid().Equal(decimal.NewFromInt(1000)) {
+		t.Errorf("%s paid after a reload, want 1000", got.Paid())
+	}
+
+	if err := s.RemovePayments(7); err != nil {
+		t.Fatal(err)
+	}
+	paid(0)
+}
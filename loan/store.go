@@ -0,0 +1,209 @@
+package loan
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"runfyne/storage"
+)
+
+// Store keeps every loan and its payments, saved to a JSON file after every change
+type Store struct {
+	mu     sync.Mutex
+	path   string
+	nextID int
+	loans  map[int]Loan
+}
+
+// storeFile is the layout of the loans on disk
+type storeFile struct {
+	NextID int
+	Loans  []Loan
+}
+
+// NewStore returns an empty store backed by the file at path
+func NewStore(path string) *Store {
+	return &Store{path: path, nextID: 1, loans: map[int]Loan{}}
+}
+
+// Load reads the loans from disk, a missing file gives an empty store
+func (s *Store) Load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file := storeFile{NextID: 1}
+	if err := storage.ReadJSON(s.path, &file); err != nil {
+		return fmt.Errorf("loading loans: %w", err)
+	}
+	s.nextID = file.NextID
+	s.loans = make(map[int]Loan, len(file.Loans))
+	for _, l := range file.Loans {
+		s.loans[l.ID] = l
+		if l.ID >= s.nextID {
+			s.nextID = l.ID + 1
+		}
+	}
+	return nil
+}
+
+// Add validates a new loan, assigns its ID and saves it
+func (s *Store) Add(l Loan) (Loan, error) {
+	if err := l.Validate(); err != nil {
+		return Loan{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l.ID = s.nextID
+	l.Payments = nil
+	s.nextID++
+	s.loans[l.ID] = l
+	if err := s.save(); err != nil {
+		delete(s.loans, l.ID)
+		return Loan{}, err
+	}
+	return l, nil
+}
+
+// Update validates and saves changes to the terms of a loan. Terms can only
+// change until the first installment is taken
+func (s *Store) Update(l Loan) (Loan, error) {
+	if err := l.Validate(); err != nil {
+		return Loan{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, ok := s.loans[l.ID]
+	if !ok {
+		return Loan{}, fmt.Errorf("loan %d does not exist", l.ID)
+	}
+	if len(old.Payments) > 0 {
+		return Loan{}, fmt.Errorf("loan %d already has payments, its terms can no longer change", l.ID)
+	}
+	l.Payments = nil
+	s.loans[l.ID] = l
+	if err := s.save(); err != nil {
+		s.loans[l.ID] = old
+		return Loan{}, err
+	}
+	return l, nil
+}
+
+// Get returns the loan with the given ID
+func (s *Store) Get(id int) (Loan, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, ok := s.loans[id]
+	if !ok {
+		return Loan{}, false
+	}
+	return l.clone(), true
+}
+
+// All returns every loan, the oldest first
+func (s *Store) All() []Loan {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	loans := make([]Loan, 0, len(s.loans))
+	for _, l := range s.loans {
+		loans = append(loans, l.clone())
+	}
+	sort.Slice(loans, func(i, j int) bool {
+		if !loans[i].StartDate.Equal(loans[j].StartDate) {
+			return loans[i].StartDate.Before(loans[j].StartDate)
+		}
+		return loans[i].ID < loans[j].ID
+	})
+	return loans
+}
+
+// RecordPayments takes the installments of a locked payroll run, by loan ID,
+// from the balances. Recording the same run again replaces its payments
+func (s *Store) RecordPayments(runID int, date time.Time, amounts map[int]decimal.Decimal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id := range amounts {
+		if _, ok := s.loans[id]; !ok {
+			return fmt.Errorf("loan %d does not exist", id)
+		}
+	}
+	before := s.snapshot()
+	s.removePayments(runID)
+	for id, amount := range amounts {
+		l := s.loans[id]
+		l.Payments = append(l.Payments, Payment{RunID: runID, Date: date, Amount: amount})
+		s.loans[id] = l
+	}
+	if err := s.save(); err != nil {
+		s.loans = before
+		return err
+	}
+	return nil
+}
+
+// RemovePayments puts back the installments of a payroll run that was reopened
+func (s *Store) RemovePayments(runID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := s.snapshot()
+	if !s.removePayments(runID) {
+		return nil
+	}
+	if err := s.save(); err != nil {
+		s.loans = before
+		return err
+	}
+	return nil
+}
+
+// removePayments drops a run's payments and reports whether it had any
+func (s *Store) removePayments(runID int) bool {
+	removed := false
+	for id, l := range s.loans {
+		var kept []Payment
+		for _, p := range l.Payments {
+			if p.RunID != runID {
+				kept = append(kept, p)
+			}
+		}
+		if len(kept) != len(l.Payments) {
+			removed = true
+			l.Payments = kept
+			s.loans[id] = l
+		}
+	}
+	return removed
+}
+
+// snapshot copies every loan so a failed save can be rolled back
+func (s *Store) snapshot() map[int]Loan {
+	loans := make(map[int]Loan, len(s.loans))
+	for id, l := range s.loans {
+		loans[id] = l.clone()
+	}
+	return loans
+}
+
+func (s *Store) save() error {
+	file := storeFile{NextID: s.nextID}
+	for _, l := range s.loans {
+		file.Loans = append(file.Loans, l)
+	}
+	sort.Slice(file.Loans, func(i, j int) bool { return file.Loans[i].ID < file.Loans[j].ID })
+	return storage.WriteJSON(s.path, file)
+}
+
+// clone copies the loan so callers cannot change stored payments behind the store's back
+func (l Loan) clone() Loan {
+	l.Payments = append([]Payment(nil), l.Payments...)
+	return l
+}
@@ -0,0 +1,241 @@
+// Package mp2 projects the savings of a Pag-IBIG Modified Pag-IBIG II (MP2)
+// account, with the dividends either compounded or paid out every year.
+package mp2
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/shopspring/decimal"
+	"runfyne/payroll"
+)
+
+// Term is the years an MP2 account runs before it matures
+const Term = 5
+
+// MinimumContribution is the smallest amount Pag-IBIG accepts per remittance
+var MinimumContribution = decimal.NewFromInt(500)
+
+// DividendRate is the dividend Pag-IBIG declared for a year's MP2 savings
+type DividendRate struct {
+	Year int
+	Rate decimal.Decimal // 0.0705 for 7.05%
+}
+
+// DividendHistory returns the MP2 dividend rates declared in the rules, oldest
+// first. Years after the last one are projected at an assumed rate. Rules are
+// the current ones when nil
+func DividendHistory(rules *payroll.Rules) []DividendRate {
+	if rules == nil {
+		rules = payroll.Current()
+	}
+	var rates []DividendRate
+	for _, d := range rules.PagIbig.MP2Dividends {
+		rates = append(rates, DividendRate{d.Year, d.Rate})
+	}
+	return rates
+}
+
+// Payout is what happens to the dividends each year
+type Payout string
+
+const (
+	// Compounded dividends are added to the savings and earn dividends themselves
+	Compounded Payout = "compounded"
+	// Yearly dividends are paid out, only the contributions keep earning
+	Yearly Payout = "yearly"
+)
+
+// Payouts lists the dividend options in display order
+var Payouts = []Payout{Compounded, Yearly}
+
+// String names the option on screens
+func (p Payout) String() string {
+	switch p {
+	case Compounded:
+		return "Annual Compounding"
+	case Yearly:
+		return "Yearly Payout"
+	}
+	return string(p)
+}
+
+// ParsePayout converts a name such as "yearly" into a Payout
+func ParsePayout(s string) (Payout, error) {
+	for _, p := range Payouts {
+		if string(p) == s || p.String() == s {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("unknown dividend payout %q", s)
+}
+
+// Plan is how much is saved in an MP2 account and for how long
+type Plan struct {
+	StartYear   int
+	Years       int             // Term when zero
+	Monthly     decimal.Decimal // saved every month, zero when none
+	LumpSum     decimal.Decimal // saved once at the start, zero when none
+	AssumedRate decimal.Decimal // dividend rate of the years without a declared rate
+	Rates       []DividendRate  // declared rates, those of the current rules when nil
+}
+
+// Validate reports every problem with the plan
+func (p Plan) Validate() error {
+	var errs []error
+	if p.StartYear < 2000 || p.StartYear > 2100 {
+		errs = append(errs, fmt.Errorf("start year %d is out of range", p.StartYear))
+	}
+	if p.Years < 0 || p.Years > 30 {
+		errs = append(errs, errors.New("years must be between 1 and 30"))
+	}
+	if !p.Monthly.IsPositive() && !p.LumpSum.IsPositive() {
+		errs = append(errs, errors.New("enter a monthly contribution, a lump sum or both"))
+	}
+	for _, c := range []struct {
+		name   string
+		amount decimal.Decimal
+	}{{"monthly contribution", p.Monthly}, {"lump sum", p.LumpSum}} {
+		if c.amount.IsNegative() {
+			errs = append(errs, fmt.Errorf("%s cannot be negative", c.name))
+		} else if c.amount.IsPositive() && c.amount.LessThan(MinimumContribution) {
+			errs = append(errs, fmt.Errorf("%s must be at least %s", c.name, MinimumContribution))
+		}
+	}
+	if p.AssumedRate.IsNegative() || p.AssumedRate.GreaterThan(decimal.NewFromInt(1)) {
+		errs = append(errs, errors.New("assumed dividend rate must be between 0 and 1"))
+	}
+	return errors.Join(errs...)
+}
+
+// RateFor returns the dividend rate of a year, and whether it was assumed
+// because no rate was declared for it
+func (p Plan) RateFor(year int) (rate decimal.Decimal, assumed bool) {
+	rates := p.Rates
+	if rates == nil {
+		rates = DividendHistory(nil)
+	}
+	for _, r := range rates {
+		if r.Year == year {
+			return r.Rate, false
+		}
+	}
+	return p.AssumedRate, true
+}
+
+func (p Plan) years() int {
+	if p.Years == 0 {
+		return Term
+	}
+	return p.Years
+}
+
+// Year is one year of a projection
+type Year struct {
+	Year          int
+	Rate          decimal.Decimal
+	Assumed       bool // no dividend rate was declared for the year
+	Opening       decimal.Decimal
+	Contributions decimal.Decimal
+	Dividend      decimal.Decimal
+	PaidOut       decimal.Decimal // dividend paid to the member
+	Closing       decimal.Decimal
+}
+
+// Schedule is the year by year projection of a plan under one payout option
+type Schedule struct {
+	Payout        Payout
+	Years         []Year
+	Contributions decimal.Decimal
+	Dividends     decimal.Decimal
+	PaidOut       decimal.Decimal
+	Maturity      decimal.Decimal // savings left in the account at the end
+	Total         decimal.Decimal // maturity value plus the dividends paid out
+}
+
+// monthsWeight is the sum of the months each of twelve monthly contributions,
+// made at the start of the month, earns in the year: 12 + 11 + ... + 1
+var monthsWeight = decimal.NewFromInt(78)
+
+// Project computes the savings year by year. Dividends are earned on the
+// balance at the start of the year and the lump sum in full, and on each
+// monthly contribution for the months left in the year
+func Project(p Plan, payout Payout) (Schedule, error) {
+	if err := p.Validate(); err != nil {
+		return Schedule{}, err
+	}
+	if _, err := ParsePayout(string(payout)); err != nil {
+		return Schedule{}, err
+	}
+
+	s := Schedule{Payout: payout}
+	twelve := decimal.NewFromInt(12)
+	balance := decimal.Zero
+	for i := 0; i < p.years(); i++ {
+		y := Year{Year: p.StartYear + i, Opening: balance}
+		y.Rate, y.Assumed = p.RateFor(y.Year)
+
+		earning := balance
+		if i == 0 {
+			earning = earning.Add(p.LumpSum)
+			y.Contributions = p.LumpSum
+		}
+		y.Contributions = y.Contributions.Add(p.Monthly.Mul(twelve))
+		y.Dividend = earning.Mul(y.Rate).
+			Add(p.Monthly.Mul(y.Rate).Mul(monthsWeight).Div(twelve)).
+			Round(2)
+
+		y.Closing = y.Opening.Add(y.Contributions)
+		if payout == Yearly {
+			y.PaidOut = y.Dividend
+		} else {
+			y.Closing = y.Closing.Add(y.Dividend)
+		}
+		balance = y.Closing
+
+		s.Years = append(s.Years, y)
+		s.Contributions = s.Contributions.Add(y.Contributions)
+		s.Dividends = s.Dividends.Add(y.Dividend)
+		s.PaidOut = s.PaidOut.Add(y.PaidOut)
+	}
+	s.Maturity = balance
+	s.Total = s.Maturity.Add(s.PaidOut)
+	return s, nil
+}
+
+// Compare projects a plan with the dividends compounded and with them paid out yearly
+func Compare(p Plan) (compounded, yearly Schedule, err error) {
+	if compounded, err = Project(p, Compounded); err != nil {
+		return Schedule{}, Schedule{}, err
+	}
+	if yearly, err = Project(p, Yearly); err != nil {
+		return Schedule{}, Schedule{}, err
+	}
+	return compounded, yearly, nil
+}
+
+// WriteCSV writes the schedules as CSV, one row per year of each
+func WriteCSV(w io.Writer, schedules ...Schedule) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"Payout", "Year", "Dividend Rate", "Rate Assumed", "Opening Balance",
+		"Contributions", "Dividend", "Paid Out", "Closing Balance"})
+	for _, s := range schedules {
+		for _, y := range s.Years {
+			out.Write([]string{
+				string(s.Payout),
+				fmt.Sprint(y.Year),
+				y.Rate.String(),
+				fmt.Sprint(y.Assumed),
+				y.Opening.StringFixed(2),
+				y.Contributions.StringFixed(2),
+				y.Dividend.StringFixed(2),
+				y.PaidOut.StringFixed(2),
+				y.Closing.StringFixed(2),
+			})
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,119 @@
+package mp2
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"runfyne/payroll"
+)
+
+var thousand = decimal.NewFromInt(1000)
+
+func TestProject(t *testing.T) {
+	saver := Plan{StartYear: 2022, Monthly: thousand, AssumedRate: decimal.RequireFromString("0.0705")}
+
+	t.Run("compounded", func(t *testing.T) {
+		s, err := Project(saver, Compounded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Dividends.String() != "11713.95" || !s.PaidOut.IsZero() || s.Total.String() != "71713.95" {
+			t.Errorf("dividends %s paid out %s total %s", s.Dividends, s.PaidOut, s.Total)
+		}
+	})
+
+	t.Run("paid out yearly", func(t *testing.T) {
+		s, err := Project(saver, Yearly)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !s.PaidOut.Equal(s.Dividends) || s.Dividends.String() != "10749.95" || s.Total.String() != "70749.95" {
+			t.Errorf("dividends %s paid out %s total %s", s.Dividends, s.PaidOut, s.Total)
+		}
+	})
+
+	t.Run("lump sum at the declared 2020 and 2021 rates", func(t *testing.T) {
+		lump := Plan{StartYear: 2020, Years: 2, LumpSum: decimal.NewFromInt(100000), AssumedRate: decimal.RequireFromString("0.05")}
+		s, err := Project(lump, Compounded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Dividends.String() != "12487.2" {
+			t.Errorf("dividends %s, want 12487.20", s.Dividends)
+		}
+		for _, y := range s.Years {
+			if y.Assumed {
+				t.Errorf("%d rate was assumed", y.Year)
+			}
+		}
+	})
+
+	t.Run("rates given with the plan", func(t *testing.T) {
+		own := Plan{StartYear: 2020, Years: 1, LumpSum: decimal.NewFromInt(100000),
+			Rates: []DividendRate{{2020, decimal.RequireFromString("0.10")}}}
+		s, err := Project(own, Yearly)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Dividends.String() != "10000" || s.Total.String() != "110000" {
+			t.Errorf("dividends %s total %s", s.Dividends, s.Total)
+		}
+	})
+}
+
+func TestPlanValidate(t *testing.T) {
+	valid := Plan{StartYear: 2024, Monthly: decimal.NewFromInt(500)}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("minimum monthly savings: %v", err)
+	}
+
+	invalid := map[string]func(p *Plan){
+		"nothing saved":     func(p *Plan) { p.Monthly = decimal.Zero },
+		"below the minimum": func(p *Plan) { p.Monthly = decimal.NewFromInt(499) },
+		"rate above 100%":   func(p *Plan) { p.AssumedRate = decimal.RequireFromString("1.5") },
+		"too many years":    func(p *Plan) { p.Years = 31 },
+		"start year":        func(p *Plan) { p.StartYear = 1999 },
+	}
+	for name, change := range invalid {
+		p := valid
+		change(&p)
+		if err := p.Validate(); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
+
+func TestDividendHistoryFromRules(t *testing.T) {
+	rate, assumed := Plan{}.RateFor(2023)
+	if assumed || rate.String() != "0.0705" {
+		t.Errorf("2023 rate %s assumed %v, want the declared 0.0705", rate, assumed)
+	}
+
+	// The history is whatever the rules declare
+	rules := *payroll.Current()
+	rules.PagIbig.MP2Dividends = []payroll.MP2Dividend{{Year: 2030, Rate: decimal.RequireFromString("0.08")}}
+	if got := DividendHistory(&rules); len(got) != 1 || got[0].Year != 2030 || got[0].Rate.String() != "0.08" {
+		t.Errorf("history %v, want 8%% in 2030 only", got)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	s, err := Project(Plan{StartYear: 2022, Monthly: thousand, AssumedRate: decimal.RequireFromString("0.0705")}, Compounded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := WriteCSV(&out, s); err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range []string{
+		"compounded,2022,0.0703,false,0.00,12000.00,456.95,0.00,12456.95",
+		"compounded,2024,0.0705,true,25793.41,12000.00,2276.69,0.00,40070.10",
+	} {
+		if !strings.Contains(out.String(), row) {
+			t.Errorf("no row %s in\n%s", row, out.String())
+		}
+	}
+}
@@ -1,45 +1,829 @@
+// Command no_gui is the command-line version of the tax calculator, for
+// scripting payroll on machines without a display.
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/shopspring/decimal"
+	"runfyne/loan"
+	"runfyne/mp2"
+	"runfyne/payroll"
+	"runfyne/server"
+	"runfyne/storage"
 )
 
-func calculateTax(taxableIncome decimal.Decimal) decimal.Decimal {
-	var brackets = []decimal.Decimal{
-		decimal.NewFromFloat(20833),
-		decimal.NewFromFloat(33333),
-		decimal.NewFromFloat(66667),
-		decimal.NewFromFloat(166667),
-		decimal.NewFromFloat(666667),
-	}
-
-	var rates = []decimal.Decimal{
-		decimal.NewFromFloat(0.0),
-		decimal.NewFromFloat(0.15),
-		decimal.NewFromFloat(0.20),
-		decimal.NewFromFloat(0.25),
-		decimal.NewFromFloat(0.30),
-		decimal.NewFromFloat(0.35),
-	}
-
-	tax := decimal.Zero
-
-	if taxableIncome.GreaterThan(brackets[0]) {
-		for i := 1; i < len(brackets); i++ {
-			if taxableIncome.LessThanOrEqual(brackets[i]) {
-				tax = tax.Add((taxableIncome.Sub(brackets[i-1])).Mul(rates[i]))
-				break
-			} else {
-				tax = tax.Add(brackets[i].Sub(brackets[i-1]).Mul(rates[i]))
+const usageText = `Usage: no_gui <command> [flags]
+
+Commands:
+  compute   compute the deductions and net pay for an income
+  reverse   find the gross income that leaves a given net pay
+  batch     compute many incomes read from a file or standard input
+  tables    list the tax and contribution tables
+  explain   show step by step how each deduction is computed
+  cost      compute what employing someone costs the employer
+  member    compute the contributions of members who pay their own
+  mp2       project Pag-IBIG MP2 savings year by year
+  benefit   estimate an SSS maternity, sickness or unemployment benefit
+  pension   estimate an SSS monthly retirement pension
+  housing   find the Pag-IBIG housing loan a net pay affords, with its amortization
+  fringe    compute the fringe benefits tax on managers' benefits, by quarter
+  serve     serve the calculator page and JSON API over HTTP
+
+Run "no_gui <command> -h" for the flags of a command.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usageText)
+		os.Exit(2)
+	}
+
+	commands := map[string]func(args []string, out io.Writer) error{
+		"compute": runCompute,
+		"reverse": runReverse,
+		"batch":   runBatch,
+		"tables":  runTables,
+		"explain": runExplain,
+		"cost":    runCost,
+		"member":  runMember,
+		"mp2":     runMP2,
+		"benefit": runBenefit,
+		"pension": runPension,
+		"housing": runHousing,
+		"fringe":  runFringe,
+		"serve":   runServe,
+	}
+
+	name, args := os.Args[1], os.Args[2:]
+	if name == "help" || name == "-h" || name == "--help" {
+		fmt.Print(usageText)
+		return
+	}
+	run, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", name, usageText)
+		os.Exit(2)
+	}
+
+	if err := run(args, os.Stdout); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+// commonFlags are the flags every computing command accepts
+type commonFlags struct {
+	frequency    string
+	year         int
+	employeeType string
+	region       string
+	json         bool
+}
+
+func addCommonFlags(fs *flag.FlagSet) *commonFlags {
+	c := &commonFlags{}
+	fs.StringVar(&c.frequency, "frequency", string(payroll.Monthly), "pay frequency: monthly, semi-monthly, weekly or daily")
+	fs.IntVar(&c.year, "year", 0, "year of the tax and contribution rules, the latest when 0")
+	fs.StringVar(&c.employeeType, "type", string(payroll.Regular), "employee type: regular, minimum-wage or kasambahay")
+	fs.StringVar(&c.region, "region", "", "region where a kasambahay works, to check the minimum wage, e.g. NCR")
+	fs.BoolVar(&c.json, "json", false, "print JSON instead of a table")
+	return c
+}
+
+// options returns the options chosen with the flags and the rules of the chosen year
+func (c *commonFlags) options() (payroll.Options, *payroll.Rules, error) {
+	frequency, err := payroll.ParsePayFrequency(c.frequency)
+	if err != nil {
+		return payroll.Options{}, nil, err
+	}
+	employeeType, err := payroll.ParseEmployeeType(c.employeeType)
+	if err != nil {
+		return payroll.Options{}, nil, err
+	}
+	rules, err := payroll.ForYear(c.year)
+	if err != nil {
+		return payroll.Options{}, nil, err
+	}
+	opts := payroll.Options{PayFrequency: frequency, Year: c.year, EmployeeType: employeeType, Region: c.region}
+	return opts, rules, nil
+}
+
+// amountFlag is a flag holding a peso amount
+type amountFlag struct {
+	value decimal.Decimal
+	set   bool
+}
+
+func (a *amountFlag) String() string { return a.value.String() }
+
+func (a *amountFlag) Set(s string) error {
+	d, err := decimal.NewFromString(s)
+	if err != nil {
+		return errors.New("not a valid amount")
+	}
+	a.value, a.set = d, true
+	return nil
+}
+
+// earningsFlag collects the repeated -earning flag, each "type:amount" or
+// "type:amount:name", e.g. "reimbursement:1500:Transportation"
+type earningsFlag []payroll.Earning
+
+func (e *earningsFlag) String() string { return fmt.Sprint(len(*e), " earnings") }
+
+func (e *earningsFlag) Set(s string) error {
+	parts := strings.SplitN(s, ":", 3)
+	if len(parts) < 2 {
+		return errors.New("use type:amount or type:amount:name")
+	}
+	t, err := payroll.ParseEarningType(parts[0])
+	if err != nil {
+		return err
+	}
+	amount, err := decimal.NewFromString(parts[1])
+	if err != nil {
+		return errors.New("not a valid amount")
+	}
+	earning := payroll.NewEarning(t, amount)
+	if len(parts) == 3 && parts[2] != "" {
+		earning.Name = parts[2]
+	}
+	*e = append(*e, earning)
+	return nil
+}
+
+// dividendsFlag collects the repeated -dividend flag, each "year:percent",
+// e.g. "2024:7.1", replacing or adding to the declared MP2 dividend rates
+type dividendsFlag []mp2.DividendRate
+
+func (d *dividendsFlag) String() string { return fmt.Sprint(len(*d), " rates") }
+
+func (d *dividendsFlag) Set(s string) error {
+	year, rate, ok := strings.Cut(s, ":")
+	if !ok {
+		return errors.New("use year:percent")
+	}
+	var r mp2.DividendRate
+	if _, err := fmt.Sscan(year, &r.Year); err != nil {
+		return fmt.Errorf("%q is not a year", year)
+	}
+	percent, err := decimal.NewFromString(rate)
+	if err != nil {
+		return errors.New("not a valid rate")
+	}
+	r.Rate = percent.Div(decimal.NewFromInt(100))
+	*d = append(*d, r)
+	return nil
+}
+
+// rates returns the declared dividend rates with the flags' rates in place of theirs
+func (d dividendsFlag) rates(declared []mp2.DividendRate) []mp2.DividendRate {
+	rates := append([]mp2.DividendRate(nil), declared...)
+	for _, r := range d {
+		replaced := false
+		for i := range rates {
+			if rates[i].Year == r.Year {
+				rates[i], replaced = r, true
 			}
 		}
+		if !replaced {
+			rates = append(rates, r)
+		}
+	}
+	return rates
+}
+
+// rulesDir is the folder the rule tables were loaded from
+var rulesDir string
+
+// parse reads the flags of a command, requiring the named amount flag when given,
+// and loads the rule tables and company deductions
+func parse(fs *flag.FlagSet, args []string, required string, amount *amountFlag) error {
+	fs.SetOutput(os.Stderr)
+	fs.StringVar(&rulesDir, "rules", storage.Path("rules"), "folder of rule files overriding the built-in tables")
+	deductions := fs.String("deductions", storage.Path("deductions.toml"), "file of company deductions taken besides the statutory contributions")
+	if err := fs.Parse(args); err != nil {
+		return err
 	}
+	if err := payroll.RegisterFile(*deductions); err != nil {
+		return err
+	}
+	set, err := payroll.LoadRules(rulesDir)
+	if err != nil {
+		return err
+	}
+	payroll.Use(set)
 
-	return tax.Round(2)
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	if amount != nil && !amount.set {
+		return fmt.Errorf("-%s is required", required)
+	}
+	return nil
 }
 
-func main() {
-	fmt.Println(calculateTax(decimal.NewFromFloat(33333)))
+// result is what compute and reverse print
+type result struct {
+	Rules        string
+	PayFrequency payroll.PayFrequency
+	EmployeeType payroll.EmployeeType
+	Result       payroll.TaxInputs
+}
+
+func runCompute(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("compute", flag.ContinueOnError)
+	var income amountFlag
+	var earnings earningsFlag
+	fs.Var(&income, "income", "gross income for one pay period")
+	fs.Var(&earnings, "earning", "earning line for one pay period as type:amount[:name], repeat for each line instead of -income.\nTypes: "+earningTypeList())
+	common := addCommonFlags(fs)
+	if err := parse(fs, args, "", nil); err != nil {
+		return err
+	}
+	switch {
+	case income.set && len(earnings) > 0:
+		return errors.New("give either -income or -earning, not both")
+	case !income.set && len(earnings) == 0:
+		return errors.New("-income or -earning is required")
+	}
+
+	opts, rules, err := common.options()
+	if err != nil {
+		return err
+	}
+	var r payroll.TaxInputs
+	if len(earnings) > 0 {
+		r, err = rules.ComputeEarningsWith(earnings, opts)
+	} else {
+		r, err = rules.ComputeWith(income.value, opts)
+	}
+	if err != nil {
+		return err
+	}
+	return printResult(out, common, result{rules.Version, opts.PayFrequency, opts.EmployeeType, r})
+}
+
+func earningTypeList() string {
+	var types []string
+	for _, t := range payroll.EarningTypes {
+		types = append(types, string(t))
+	}
+	return strings.Join(types, ", ")
+}
+
+func runReverse(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("reverse", flag.ContinueOnError)
+	var net amountFlag
+	fs.Var(&net, "net", "net pay wanted for one pay period")
+	common := addCommonFlags(fs)
+	if err := parse(fs, args, "net", &net); err != nil {
+		return err
+	}
+
+	opts, rules, err := common.options()
+	if err != nil {
+		return err
+	}
+	r, err := rules.Reverse(net.value, opts)
+	if err != nil {
+		return err
+	}
+	return printResult(out, common, result{rules.Version, opts.PayFrequency, opts.EmployeeType, r})
+}
+
+func runExplain(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("explain", flag.ContinueOnError)
+	var income amountFlag
+	fs.Var(&income, "income", "gross income for one pay period")
+	common := addCommonFlags(fs)
+	if err := parse(fs, args, "income", &income); err != nil {
+		return err
+	}
+
+	opts, rules, err := common.options()
+	if err != nil {
+		return err
+	}
+	traces, err := rules.ExplainWith(income.value, opts)
+	if err != nil {
+		return err
+	}
+	if common.json {
+		return writeJSON(out, traces)
+	}
+
+	if opts.PayFrequency != payroll.Monthly {
+		fmt.Fprintf(out, "Computed on the monthly equivalent of %s\n\n",
+			money.FormatMoney(opts.PayFrequency.ToMonthly(income.value)))
+	}
+	for _, t := range traces {
+		fmt.Fprintln(out, t)
+	}
+	return nil
+}
+
+func runCost(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("cost", flag.ContinueOnError)
+	var salary, hmo amountFlag
+	fs.Var(&salary, "salary", "gross monthly salary")
+	fs.Var(&hmo, "hmo", "monthly HMO premium paid by the employer")
+	leaveDays := fs.Int("leave-days", payroll.ServiceIncentiveLeaveDays, "paid leave days earned a year")
+	employeeType := fs.String("type", string(payroll.Regular), "employee type: regular, minimum-wage or kasambahay")
+	year := fs.Int("year", 0, "year of the contribution rules, the latest when 0")
+	asJSON := fs.Bool("json", false, "print JSON instead of a table")
+	if err := parse(fs, args, "salary", &salary); err != nil {
+		return err
+	}
+
+	t, err := payroll.ParseEmployeeType(*employeeType)
+	if err != nil {
+		return err
+	}
+	rules, err := payroll.ForYear(*year)
+	if err != nil {
+		return err
+	}
+	monthly, err := rules.ComputeEmployerCost(payroll.EmployerCostInputs{
+		MonthlySalary: salary.value,
+		LeaveDays:     decimal.NewFromInt(int64(*leaveDays)),
+		HMO:           hmo.value,
+		EmployeeType:  t,
+	})
+	if err != nil {
+		return err
+	}
+	annual := monthly.Annual()
+	if *asJSON {
+		return writeJSON(out, struct {
+			Rules           string
+			Monthly, Annual payroll.EmployerCost
+		}{rules.Version, monthly, annual})
+	}
+
+	fmt.Fprintf(out, "Rules %s\n\n", rules.Version)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "\tMonthly\tAnnual\t")
+	annualFields := annual.Fields()
+	for i, f := range monthly.Fields() {
+		fmt.Fprintf(w, "%s\t%s\t%s\t\n", f.Name, money.FormatMoney(f.Value), money.FormatMoney(annualFields[i].Value))
+	}
+	return w.Flush()
+}
+
+func runMember(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("member", flag.ContinueOnError)
+	var earnings amountFlag
+	fs.Var(&earnings, "earnings", "declared monthly earnings")
+	sssType := fs.String("sss", string(payroll.SelfEmployed), "SSS member type: "+sssMemberTypeList())
+	category := fs.String("philhealth", string(payroll.SelfEarning), "PhilHealth member category: "+philHealthCategoryList())
+	year := fs.Int("year", 0, "year of the contribution rules, the latest when 0")
+	asJSON := fs.Bool("json", false, "print JSON instead of a table")
+	if err := parse(fs, args, "earnings", &earnings); err != nil {
+		return err
+	}
+
+	m, err := payroll.ParseSSSMemberType(*sssType)
+	if err != nil {
+		return err
+	}
+	rules, err := payroll.ForYear(*year)
+	if err != nil {
+		return err
+	}
+	c, err := payroll.ParsePhilHealthCategory(*category)
+	if err != nil {
+		return err
+	}
+	sss, err := rules.ComputeSSSMember(m, earnings.value)
+	if err != nil {
+		return err
+	}
+	philHealth, err := rules.CalculatePhilHealthMember(c, earnings.value)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return writeJSON(out, struct {
+			Rules              string
+			SSS                payroll.SSSMemberContribution
+			PhilHealthCategory payroll.PhilHealthCategory
+			PhilHealth         decimal.Decimal
+		}{rules.Version, sss, c, philHealth})
+	}
+
+	fmt.Fprintf(out, "Rules %s, %s SSS member, %s PhilHealth member\n\n", rules.Version, m, c)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "Declared Earnings\t%s\t\n", money.FormatMoney(sss.Earnings))
+	fmt.Fprintf(w, "SSS Salary Credit\t%s\t\n", money.FormatMoney(sss.Range.SalaryCredit))
+	fmt.Fprintf(w, "SSS Rate\t%s\t\n", percentOf(sss.Rate))
+	fmt.Fprintf(w, "SSS Contribution\t%s\t\n", money.FormatMoney(sss.Contribution))
+	fmt.Fprintf(w, "PhilHealth Premium\t%s\t\n", money.FormatMoney(philHealth))
+	fmt.Fprintf(w, "Total\t%s\t\n", money.FormatMoney(sss.Contribution.Add(philHealth)))
+	return w.Flush()
+}
+
+// sssMemberTypeList names the SSS member types for flag help
+func sssMemberTypeList() string {
+	var names []string
+	for _, m := range payroll.SSSMemberTypes {
+		names = append(names, string(m))
+	}
+	return strings.Join(names, ", ")
+}
+
+// philHealthCategoryList names the PhilHealth member categories for flag help
+func philHealthCategoryList() string {
+	var names []string
+	for _, c := range payroll.PhilHealthCategories {
+		names = append(names, string(c))
+	}
+	return strings.Join(names, ", ")
+}
+
+func runMP2(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("mp2", flag.ContinueOnError)
+	var monthly, lumpSum, assumed amountFlag
+	var dividends dividendsFlag
+	fs.Var(&monthly, "monthly", "amount saved every month")
+	fs.Var(&lumpSum, "lump-sum", "amount saved once at the start")
+	fs.Var(&assumed, "assumed", "dividend rate in percent for the years without a declared rate, the last declared rate when not given")
+	fs.Var(&dividends, "dividend", "declared dividend rate as year:percent, repeat for each year to replace or add")
+	start := fs.Int("start", time.Now().Year(), "year of the first contribution")
+	years := fs.Int("years", mp2.Term, "years the savings are kept")
+	payout := fs.String("payout", "", "dividend option: compounded or yearly, both when empty")
+	asCSV := fs.Bool("csv", false, "print CSV instead of a table")
+	asJSON := fs.Bool("json", false, "print JSON instead of a table")
+	if err := parse(fs, args, "", nil); err != nil {
+		return err
+	}
+
+	declared := mp2.DividendHistory(nil)
+	plan := mp2.Plan{
+		StartYear:   *start,
+		Years:       *years,
+		Monthly:     monthly.value,
+		LumpSum:     lumpSum.value,
+		AssumedRate: assumed.value.Div(decimal.NewFromInt(100)),
+		Rates:       dividends.rates(declared),
+	}
+	if !assumed.set {
+		plan.AssumedRate = declared[len(declared)-1].Rate
+	}
+	if *years <= 0 {
+		return errors.New("-years must be at least 1")
+	}
+	payouts := mp2.Payouts
+	if *payout != "" {
+		p, err := mp2.ParsePayout(*payout)
+		if err != nil {
+			return err
+		}
+		payouts = []mp2.Payout{p}
+	}
+	var schedules []mp2.Schedule
+	for _, p := range payouts {
+		s, err := mp2.Project(plan, p)
+		if err != nil {
+			return err
+		}
+		schedules = append(schedules, s)
+	}
+
+	switch {
+	case *asCSV:
+		return mp2.WriteCSV(out, schedules...)
+	case *asJSON:
+		return writeJSON(out, schedules)
+	}
+	for i, s := range schedules {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		printMP2(out, s)
+	}
+	return nil
+}
+
+// fringeFlag collects the repeated -benefit flag, each a line as read by
+// payroll.ParseFringeBenefits, e.g. "2023-02,club,250000"
+type fringeFlag []payroll.FringeBenefit
+
+func (f *fringeFlag) String() string { return fmt.Sprint(len(*f), " benefits") }
+
+func (f *fringeFlag) Set(s string) error {
+	benefits, err := payroll.ParseFringeBenefits(strings.NewReader(s))
+	if err != nil {
+		return err
+	}
+	if len(benefits) == 0 {
+		return errors.New("use YYYY-MM,type,amount[,months[,employee share[,description]]]")
+	}
+	*f = append(*f, benefits...)
+	return nil
+}
+
+// historyFlags are the flags giving an SSS contribution history, read from a
+// file or made up of the same salary every month
+type historyFlags struct {
+	file   string
+	salary amountFlag
+	months int
+}
+
+func addHistoryFlags(fs *flag.FlagSet, months int) *historyFlags {
+	h := &historyFlags{}
+	fs.StringVar(&h.file, "history", "", "file of contributions, one \"YYYY-MM,amount\" per line, - for standard input")
+	fs.Var(&h.salary, "salary", "monthly compensation contributed on every month, instead of -history")
+	fs.IntVar(&h.months, "months", months, "months contributed on -salary, up to the month before -date")
+	return h
+}
+
+// read returns the contribution history, a steady one ending with the month of last when -salary is given
+func (h *historyFlags) read(last time.Time) ([]payroll.SSSContribution, error) {
+	switch {
+	case h.file != "" && h.salary.set:
+		return nil, errors.New("give -history or -salary, not both")
+	case h.salary.set:
+		return payroll.SteadySSSHistory(h.salary.value, last, h.months), nil
+	case h.file == "":
+		return nil, errors.New("-history or -salary is required")
+	}
+	in := io.Reader(os.Stdin)
+	if h.file != "-" {
+		f, err := os.Open(h.file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		in = f
+	}
+	return payroll.ParseSSSHistory(in)
+}
+
+func runBenefit(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("benefit", flag.ContinueOnError)
+	benefit := fs.String("benefit", string(payroll.MaternityBenefit), "benefit: maternity, sickness or unemployment")
+	date := fs.String("date", "", "date of delivery, first day of confinement or date of separation, as YYYY-MM-DD")
+	history := addHistoryFlags(fs, 36)
+	miscarriage := fs.Bool("miscarriage", false, "maternity benefit for a miscarriage or emergency termination")
+	days := fs.Int("days", 0, "days of confinement for the sickness benefit")
+	year := fs.Int("year", 0, "year of the contribution rules, the latest when 0")
+	asJSON := fs.Bool("json", false, "print JSON instead of a table")
+	if err := parse(fs, args, "", nil); err != nil {
+		return err
+	}
+
+	b, err := payroll.ParseSSSBenefit(*benefit)
+	if err != nil {
+		return err
+	}
+	contingency, err := time.Parse("2006-01-02", *date)
+	if err != nil {
+		return errors.New("-date must be a date such as 2024-03-15")
+	}
+	contributions, err := history.read(contingency.AddDate(0, -1, 0))
+	if err != nil {
+		return err
+	}
+
+	rules, err := payroll.ForYear(*year)
+	if err != nil {
+		return err
+	}
+	st, err := rules.ComputeSSSBenefit(payroll.SSSBenefitInputs{
+		Benefit:     b,
+		Contingency: contingency,
+		History:     contributions,
+		Miscarriage: *miscarriage,
+		SickDays:    *days,
+	})
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return writeJSON(out, struct {
+			Rules     string
+			Statement payroll.SSSBenefitStatement
+		}{rules.Version, st})
+	}
+	fmt.Fprintf(out, "Rules %s\n\n", rules.Version)
+	fmt.Fprintln(out, st.Trace())
+	return nil
+}
+
+func runPension(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("pension", flag.ContinueOnError)
+	date := fs.String("date", "", "retirement date as YYYY-MM-DD")
+	history := addHistoryFlags(fs, 240)
+	years := fs.Int("years", 0, "credited years of service, calendar years with at least six contributions, counted from the history when 0")
+	dependents := fs.Int("dependents", 0, "dependent children under 21")
+	year := fs.Int("year", 0, "year of the contribution rules, the latest when 0")
+	asJSON := fs.Bool("json", false, "print JSON instead of a table")
+	if err := parse(fs, args, "", nil); err != nil {
+		return err
+	}
+
+	retirement, err := time.Parse("2006-01-02", *date)
+	if err != nil {
+		return errors.New("-date must be a date such as 2024-03-15")
+	}
+	contributions, err := history.read(retirement.AddDate(0, -1, 0))
+	if err != nil {
+		return err
+	}
+	rules, err := payroll.ForYear(*year)
+	if err != nil {
+		return err
+	}
+	st, err := rules.ComputeSSSPension(payroll.SSSPensionInputs{
+		History:        contributions,
+		CreditedYears:  *years,
+		Dependents:     *dependents,
+		RetirementDate: retirement,
+	})
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return writeJSON(out, struct {
+			Rules     string
+			Statement payroll.SSSPensionStatement
+		}{rules.Version, st})
+	}
+	fmt.Fprintf(out, "Rules %s\n\n", rules.Version)
+	fmt.Fprintln(out, st.Trace())
+	return nil
+}
+
+func runHousing(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("housing", flag.ContinueOnError)
+	var income, net, desired amountFlag
+	fs.Var(&income, "income", "gross income for one pay period, the net pay is computed from it")
+	fs.Var(&net, "net", "monthly net pay after deductions, instead of -income")
+	fs.Var(&desired, "amount", "amount to borrow, the most the pay allows when not given")
+	term := fs.Int("term", 30, "years to repay the loan")
+	fixing := fs.Int("repricing", 3, "years the interest is fixed for")
+	age := fs.Int("age", 0, "borrower's age, to check the loan is repaid in time")
+	start := fs.String("start", "", "date of the first amortization as YYYY-MM-DD, the first of next month when empty")
+	common := addCommonFlags(fs)
+	if err := parse(fs, args, "", nil); err != nil {
+		return err
+	}
+	if income.set == net.set {
+		return errors.New("give either -income or -net")
+	}
+
+	opts, rules, err := common.options()
+	if err != nil {
+		return err
+	}
+	in := loan.HousingInputs{
+		NetPay:      net.value,
+		TermYears:   *term,
+		FixingYears: *fixing,
+		Age:         *age,
+		Desired:     desired.value,
+	}
+	if income.set {
+		if in.NetPay, err = loan.MonthlyNetPay(rules, income.value, opts); err != nil {
+			return err
+		}
+	}
+	if *start != "" {
+		if in.StartDate, err = time.Parse("2006-01-02", *start); err != nil {
+			return errors.New("-start must be a date such as 2024-03-01")
+		}
+	}
+	st, err := loan.ComputeHousing(rules, in)
+	if err != nil {
+		return err
+	}
+	if common.json {
+		return writeJSON(out, struct {
+			Rules     string
+			Statement loan.HousingStatement
+			Schedule  []loan.Installment
+		}{rules.Version, st, st.Schedule()})
+	}
+	fmt.Fprintf(out, "Rules %s\n\n", rules.Version)
+	fmt.Fprintln(out, st.Trace())
+	printSchedule(out, st.Schedule())
+	return nil
+}
+
+func runFringe(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("fringe", flag.ContinueOnError)
+	var benefits fringeFlag
+	fs.Var(&benefits, "benefit", "benefit as YYYY-MM,type,amount[,months[,employee share[,description]]], repeat for each.\nTypes: "+fringeTypeList())
+	file := fs.String("file", "", "file of benefits, one per line as for -benefit, - for standard input")
+	year := fs.Int("year", 0, "year of the tax rules, the latest when 0")
+	asJSON := fs.Bool("json", false, "print JSON instead of a table")
+	if err := parse(fs, args, "", nil); err != nil {
+		return err
+	}
+
+	if *file != "" {
+		in := io.Reader(os.Stdin)
+		if *file != "-" {
+			f, err := os.Open(*file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			in = f
+		}
+		read, err := payroll.ParseFringeBenefits(in)
+		if err != nil {
+			return err
+		}
+		benefits = append(benefits, read...)
+	}
+	if len(benefits) == 0 {
+		return errors.New("-benefit or -file is required")
+	}
+	rules, err := payroll.ForYear(*year)
+	if err != nil {
+		return err
+	}
+	st, err := rules.ComputeFringeBenefits(benefits)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return writeJSON(out, struct {
+			Rules     string
+			Statement payroll.FringeStatement
+		}{rules.Version, st})
+	}
+	fmt.Fprintf(out, "Rules %s\n\n", rules.Version)
+	fmt.Fprintln(out, st.Trace())
+	printFringeQuarters(out, st.Quarters)
+	return nil
+}
+
+// fringeTypeList names the fringe benefit types for flag help
+func fringeTypeList() string {
+	var names []string
+	for _, t := range payroll.FringeBenefitTypes {
+		names = append(names, string(t))
+	}
+	return strings.Join(names, ", ")
+}
+
+func runTables(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("tables", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print JSON instead of a table")
+	year := fs.Int("year", 0, "year of the tables, the latest when 0")
+	if err := parse(fs, args, "", nil); err != nil {
+		return err
+	}
+
+	rules, err := payroll.ForYear(*year)
+	if err != nil {
+		return err
+	}
+	tables := rules.Tables()
+	if *asJSON {
+		return writeJSON(out, tables)
+	}
+	printTables(out, tables)
+	return nil
+}
+
+func runServe(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", "localhost:8080", "address to listen on, \":8080\" to accept other machines")
+	if err := parse(fs, args, "", nil); err != nil {
+		return err
+	}
+
+	// Corrected tables are picked up without restarting the server
+	stop, err := payroll.WatchRules(rulesDir, func(set *payroll.RuleSet, err error) {
+		if err != nil {
+			log.Printf("rules in %s not reloaded, keeping rules %s: %v", rulesDir, payroll.Current(), err)
+			return
+		}
+		log.Printf("rules reloaded from %s, now using rules %s", rulesDir, set.Latest())
+	})
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	log.Printf("serving the calculator on http://%s/ and its API on /api/ (rules %s)", *addr, payroll.Current())
+	return http.ListenAndServe(*addr, server.New())
 }
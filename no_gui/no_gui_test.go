@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"runfyne/loan"
+)
+
+// run runs a command with rule and deduction files that do not exist, so the
+// built-in rules apply whatever is in the user's config folder
+func run(t *testing.T, command func([]string, io.Writer) error, args ...string) (string, error) {
+	t.Helper()
+	dir := t.TempDir()
+	args = append(args, "-rules", filepath.Join(dir, "rules"), "-deductions", filepath.Join(dir, "deductions.toml"))
+	var out bytes.Buffer
+	err := command(args, &out)
+	return out.String(), err
+}
+
+func TestCompute(t *testing.T) {
+	out, err := run(t, runCompute, "-income", "33333", "-json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r result
+	if err := json.Unmarshal([]byte(out), &r); err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	if got := r.Result.NetPayAfterDeductions.StringFixed(2); got != "29588.01" || r.Rules == "" {
+		t.Errorf("net pay %s under rules %q", got, r.Rules)
+	}
+
+	for _, args := range [][]string{
+		{},
+		{"-income", "1000", "-earning", "basic:1000"},
+		{"-income", "abc"},
+		{"-income", "1000", "-frequency", "fortnightly"},
+	} {
+		if _, err := run(t, runCompute, args...); err == nil {
+			t.Errorf("compute %v: no error", args)
+		}
+	}
+}
+
+func TestReverseCommand(t *testing.T) {
+	out, err := run(t, runReverse, "-net", "29588.01", "-json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r result
+	if err := json.Unmarshal([]byte(out), &r); err != nil {
+		t.Fatal(err)
+	}
+	// 33,333 leaves 29,588.0075, just short of the net pay asked for
+	if gross := r.Result.MonthlyIncome.StringFixed(2); gross != "33333.01" {
+		t.Errorf("gross %s, want 33333.01", gross)
+	}
+}
+
+func TestKasambahayCommands(t *testing.T) {
+	out, err := run(t, runReverse, "-net", "6500", "-type", "kasambahay", "-region", "NCR", "-json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r result
+	if err := json.Unmarshal([]byte(out), &r); err != nil {
+		t.Fatal(err)
+	}
+	if gross := r.Result.MonthlyIncome.StringFixed(2); gross != "7140.00" {
+		t.Errorf("gross %s, want 7140.00", gross)
+	}
+
+	if _, err := run(t, runCompute, "-income", "4500", "-type", "kasambahay", "-region", "NCR"); err == nil {
+		t.Error("a wage below the NCR minimum was computed")
+	}
+}
+
+func TestBatch(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "incomes.csv")
+	if err := os.WriteFile(file, []byte("name,income\n# staff\nAna,33333\n\n50000\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := run(t, runBatch, "-file", file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Ana", "29,588.01", "42,731.60"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("no %s in\n%s", want, out)
+		}
+	}
+}
+
+func TestExplainAndTables(t *testing.T) {
+	out, err := run(t, runExplain, "-income", "16666.50", "-frequency", "semi-monthly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out, "Computed on the monthly equivalent of") || !strings.Contains(out, "PhilHealth") {
+		t.Errorf("explain output:\n%s", out)
+	}
+
+	out, err = run(t, runTables, "-json")
+	if err != nil || !json.Valid([]byte(out)) {
+		t.Errorf("tables: %v\n%s", err, out)
+	}
+}
+
+func TestMP2(t *testing.T) {
+	out, err := run(t, runMP2, "-monthly", "1000", "-start", "2022", "-payout", "compounded", "-csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "compounded,2023,0.0705,false,12456.95,12000.00,1336.46,0.00,25793.41") {
+		t.Errorf("no 2023 row at the declared rate in\n%s", out)
+	}
+}
+
+func TestHousing(t *testing.T) {
+	statement := func(args ...string) loan.HousingStatement {
+		t.Helper()
+		out, err := run(t, runHousing, append(args, "-start", "2024-03-01", "-json")...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var v struct{ Statement loan.HousingStatement }
+		if err := json.Unmarshal([]byte(out), &v); err != nil {
+			t.Fatalf("%v\n%s", err, out)
+		}
+		return v.Statement
+	}
+
+	st := statement("-net", "42731.60")
+	if st.Amortization.StringFixed(2) != "14956.05" || st.Loanable.String() != "2429046" {
+		t.Errorf("amortization %s on %s, want 14956.05 on 2429046", st.Amortization, st.Loanable)
+	}
+	// Two semi-monthly paydays of 25,000 leave the same monthly net pay
+	if st := statement("-income", "25000", "-frequency", "semi-monthly"); st.NetPay.StringFixed(2) != "42731.60" {
+		t.Errorf("net pay %s, want 42731.60", st.NetPay)
+	}
+
+	if _, err := run(t, runHousing, "-net", "42731.60", "-income", "25000"); err == nil {
+		t.Error("both -net and -income were accepted")
+	}
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/leekchan/accounting"
+	"github.com/shopspring/decimal"
+	"runfyne/loan"
+	"runfyne/mp2"
+	"runfyne/payroll"
+)
+
+// Amounts are printed without the peso sign so they line up and paste into spreadsheets
+var money = accounting.Accounting{Symbol: "", Precision: 2}
+
+func writeJSON(out io.Writer, v interface{}) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func printResult(out io.Writer, common *commonFlags, r result) error {
+	if common.json {
+		return writeJSON(out, r)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(out, "Rules %s, %s pay, %s employee\n\n", r.Rules, r.PayFrequency, r.EmployeeType)
+	if b := r.Result.Earnings; b != nil {
+		fmt.Fprintf(w, "Earnings\t\t\n")
+		for _, e := range b.Lines {
+			fmt.Fprintf(w, "%s (%s)\t%s\t\n", e.Name, treatment(e), money.FormatMoney(e.Amount))
+		}
+		fmt.Fprintf(w, "Taxable Earnings\t%s\t\n", money.FormatMoney(b.Taxable))
+		fmt.Fprintf(w, "Non-Taxable Earnings\t%s\t\n", money.FormatMoney(b.NonTaxable))
+		fmt.Fprintf(w, "Contribution Base\t%s\t\n\t\t\n", money.FormatMoney(b.ContributionBase))
+	}
+	for _, f := range r.Result.Fields() {
+		fmt.Fprintf(w, "%s\t%s\t\n", f.Name, money.FormatMoney(f.Value))
+	}
+	if len(r.Result.OtherDeductions) > 0 {
+		fmt.Fprintf(w, "\t\t\nOther Deductions\t\t\n")
+		for _, d := range r.Result.OtherDeductions {
+			fmt.Fprintf(w, "%s (%s)\t%s\t\n", d.Name, d.Timing, money.FormatMoney(d.Amount))
+		}
+	}
+	return w.Flush()
+}
+
+// treatment describes how an earning line is taxed and contributed on
+func treatment(e payroll.Earning) string {
+	s := "non-taxable"
+	if e.Taxable {
+		s = "taxable"
+	}
+	if e.ContributionBase {
+		s += ", in contribution base"
+	}
+	return s
+}
+
+// batchLine is one income read by the batch command
+type batchLine struct {
+	Name   string `json:",omitempty"`
+	Income decimal.Decimal
+	Result payroll.TaxInputs
+}
+
+func runBatch(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("batch", flag.ContinueOnError)
+	file := fs.String("file", "", "file with one income or \"name,income\" per line, standard input when empty")
+	common := addCommonFlags(fs)
+	if err := parse(fs, args, "", nil); err != nil {
+		return err
+	}
+
+	opts, rules, err := common.options()
+	if err != nil {
+		return err
+	}
+
+	in := io.Reader(os.Stdin)
+	if *file != "" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	lines, err := readBatch(in)
+	if err != nil {
+		return err
+	}
+	for i := range lines {
+		lines[i].Result, err = rules.ComputeWith(lines[i].Income, opts)
+		if err != nil {
+			return fmt.Errorf("%s: %w", lines[i].Name, err)
+		}
+	}
+
+	if common.json {
+		return writeJSON(out, struct {
+			Rules        string
+			PayFrequency payroll.PayFrequency
+			EmployeeType payroll.EmployeeType
+			Lines        []batchLine
+		}{rules.Version, opts.PayFrequency, opts.EmployeeType, lines})
+	}
+	return printBatch(out, lines)
+}
+
+// readBatch reads incomes, one per line, optionally preceded by a name and a
+// comma. Blank lines, lines starting with # and a heading line are skipped
+func readBatch(in io.Reader) ([]batchLine, error) {
+	var lines []batchLine
+	first := true
+	scanner := bufio.NewScanner(in)
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		heading := first
+		first = false
+
+		fields, err := csv.NewReader(strings.NewReader(text)).Read()
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		var line batchLine
+		amount := fields[len(fields)-1]
+		if len(fields) > 1 {
+			line.Name = strings.TrimSpace(fields[0])
+		} else {
+			line.Name = fmt.Sprintf("line %d", n)
+		}
+
+		line.Income, err = decimal.NewFromString(strings.TrimSpace(strings.ReplaceAll(amount, ",", "")))
+		if err != nil {
+			if heading {
+				continue // heading
+			}
+			return nil, fmt.Errorf("line %d: %q is not a valid income", n, amount)
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, errors.New("no incomes to compute")
+	}
+	return lines, nil
+}
+
+func printBatch(out io.Writer, lines []batchLine) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Name\tIncome\tSSS\tPhilHealth\tPag-IBIG\tTax\tDeductions\tNet Pay\t")
+
+	var total payroll.TaxInputs
+	row := func(name string, r payroll.TaxInputs) {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n", name,
+			money.FormatMoney(r.MonthlyIncome),
+			money.FormatMoney(r.SSSContributions),
+			money.FormatMoney(r.PhilHealthContributions),
+			money.FormatMoney(r.PagIbigContributions),
+			money.FormatMoney(r.Tax),
+			money.FormatMoney(r.TotalDeductions),
+			money.FormatMoney(r.NetPayAfterDeductions))
+	}
+	for _, l := range lines {
+		row(l.Name, l.Result)
+		total.MonthlyIncome = total.MonthlyIncome.Add(l.Result.MonthlyIncome)
+		total.SSSContributions = total.SSSContributions.Add(l.Result.SSSContributions)
+		total.PhilHealthContributions = total.PhilHealthContributions.Add(l.Result.PhilHealthContributions)
+		total.PagIbigContributions = total.PagIbigContributions.Add(l.Result.PagIbigContributions)
+		total.Tax = total.Tax.Add(l.Result.Tax)
+		total.TotalDeductions = total.TotalDeductions.Add(l.Result.TotalDeductions)
+		total.NetPayAfterDeductions = total.NetPayAfterDeductions.Add(l.Result.NetPayAfterDeductions)
+	}
+	row("Total", total)
+	return w.Flush()
+}
+
+func printTables(out io.Writer, t payroll.Tables) {
+	fmt.Fprintf(out, "Rules %s for %d, from %s\n\nMonthly Withholding Tax\n", t.Version, t.Year, t.Source)
+	for _, b := range t.Tax {
+		fmt.Fprintln(out, "  "+b.String())
+	}
+
+	fmt.Fprintf(out, "\nSSS, employee share %s and employer share %s of the salary credit\n",
+		percentOf(t.SSSEmployeeRate), percentOf(t.SSSEmployerRate))
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Compensation From\tTo\tSalary Credit\tEmployee Share\tEmployer Share\t")
+	for _, r := range t.SSS {
+		from, to := "", ""
+		if !r.Low.IsZero() {
+			from = money.FormatMoney(r.Low)
+		}
+		if !r.High.IsZero() {
+			to = money.FormatMoney(r.High)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t\n", from, to,
+			money.FormatMoney(r.SalaryCredit),
+			money.FormatMoney(r.SalaryCredit.Mul(t.SSSEmployeeRate)),
+			money.FormatMoney(r.SalaryCredit.Mul(t.SSSEmployerRate)))
+	}
+	w.Flush()
+	fmt.Fprintf(out, "  members paying their own contribution pay %s of the salary credit, from a floor of\n",
+		percentOf(t.SSSEmployeeRate.Add(t.SSSEmployerRate)))
+	for _, m := range t.SSSMembers {
+		fmt.Fprintf(out, "    %s for %s members\n", money.FormatMoney(m.MinCredit), m.Type)
+	}
+
+	p := t.PhilHealth
+	fmt.Fprintf(out, "\nPhilHealth\n  %s of income, %s for income up to %s, %s for income of %s or more\n",
+		percentOf(p.Rate), money.FormatMoney(p.Floor), money.FormatMoney(p.FloorIncome),
+		money.FormatMoney(p.Ceiling), money.FormatMoney(p.CeilingIncome))
+	fmt.Fprintf(out, "  direct contributors pay %s of income, %s to %s\n",
+		percentOf(p.DirectRate), money.FormatMoney(p.DirectFloor), money.FormatMoney(p.DirectCeiling))
+
+	fmt.Fprintf(out, "\nFringe benefits tax %s of the value grossed up by dividing it by %s\n",
+		percentOf(t.FringeRate), percentOf(decimal.NewFromInt(1).Sub(t.FringeRate)))
+
+	g := t.PagIbig
+	fmt.Fprintf(out, "\nPag-IBIG\n  %s of income up to %s, %s above, at most %s\n",
+		percentOf(g.LowRate), money.FormatMoney(g.LowIncome), percentOf(g.Rate), money.FormatMoney(g.Max))
+	fmt.Fprintf(out, "  employer share %s of income, at most %s\n",
+		percentOf(g.EmployerRate), money.FormatMoney(g.EmployerMax))
+	fmt.Fprintf(out, "  housing loans up to %s over at most %d years, repaid by age %d, amortization at most %s of net pay\n",
+		money.FormatMoney(g.Housing.MaxLoan), g.Housing.MaxTermYears, g.Housing.MaxAge, percentOf(g.Housing.CapacityRatio))
+	for _, r := range g.Housing.Rates {
+		fmt.Fprintf(out, "    %d-year repricing at %s\n", r.FixingYears, percentOf(r.Rate))
+	}
+
+	k := t.Kasambahay
+	fmt.Fprintf(out, "\nKasambahay\n  employer pays all contributions below %s\n", money.FormatMoney(k.EmployerPaysBelow))
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Region\tMinimum Wage\t")
+	for _, m := range k.MinimumWage {
+		fmt.Fprintf(w, "%s\t%s\t\n", m.Region, money.FormatMoney(m.Wage))
+	}
+	w.Flush()
+}
+
+func percentOf(rate decimal.Decimal) string {
+	return rate.Mul(decimal.NewFromInt(100)).String() + "%"
+}
+
+// printMP2 prints an MP2 projection, marking the years projected at the assumed rate
+func printMP2(out io.Writer, s mp2.Schedule) {
+	fmt.Fprintf(out, "MP2 savings, %s\n", s.Payout)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Year\tRate\tOpening\tContributions\tDividend\tPaid Out\tClosing\t")
+	for _, y := range s.Years {
+		rate := percentOf(y.Rate)
+		if y.Assumed {
+			rate += "*"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t\n", y.Year, rate,
+			money.FormatMoney(y.Opening),
+			money.FormatMoney(y.Contributions),
+			money.FormatMoney(y.Dividend),
+			money.FormatMoney(y.PaidOut),
+			money.FormatMoney(y.Closing))
+	}
+	fmt.Fprintf(w, "Total\t\t\t%s\t%s\t%s\t%s\t\n",
+		money.FormatMoney(s.Contributions),
+		money.FormatMoney(s.Dividends),
+		money.FormatMoney(s.PaidOut),
+		money.FormatMoney(s.Maturity))
+	w.Flush()
+	fmt.Fprintf(out, "Total received %s, * assumed rate\n", money.FormatMoney(s.Total))
+}
+
+// printSchedule prints an amortization schedule, one installment per line
+func printSchedule(out io.Writer, schedule []loan.Installment) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "No.\tDue\tAmount\tInterest\tPrincipal\tBalance\t")
+	for _, in := range schedule {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t\n", in.Number,
+			in.Due.Format("2006-01-02"),
+			money.FormatMoney(in.Amount),
+			money.FormatMoney(in.Interest),
+			money.FormatMoney(in.Principal),
+			money.FormatMoney(in.Balance))
+	}
+	w.Flush()
+}
+
+// printFringeQuarters prints the fringe benefits tax of each quarter month by
+// month, as filed on BIR Form 1603Q
+func printFringeQuarters(out io.Writer, quarters []payroll.FringeQuarter) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Quarter\tMonth\tMonetary Value\tGrossed-Up Value\tTax\tFile By\t")
+	for _, q := range quarters {
+		for _, m := range q.Months {
+			fmt.Fprintf(w, "\t%s\t%s\t%s\t%s\t\t\n", m.Month.Format("Jan 2006"),
+				money.FormatMoney(m.Value),
+				money.FormatMoney(m.GrossedUp),
+				money.FormatMoney(m.Tax))
+		}
+		fmt.Fprintf(w, "%s\t\t%s\t%s\t%s\t%s\t\n", q.Name(),
+			money.FormatMoney(q.Value),
+			money.FormatMoney(q.GrossedUp),
+			money.FormatMoney(q.Tax),
+			q.Deadline.Format("2006-01-02"))
+	}
+	w.Flush()
+}
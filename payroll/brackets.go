@@ -0,0 +1,130 @@
+package payroll
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// TaxBracket is one row of the withholding tax table
+type TaxBracket struct {
+	Lower   decimal.Decimal // taxable income above this amount falls in the bracket
+	Upper   decimal.Decimal // up to this amount, zero for the top bracket
+	Rate    decimal.Decimal // rate on the excess over Lower
+	BaseTax decimal.Decimal // tax due on Lower itself
+}
+
+func (b TaxBracket) String() string {
+	rate := b.Rate.Mul(decimal.NewFromInt(100)).String() + "%"
+	switch {
+	case b.Lower.IsZero():
+		return fmt.Sprintf("Up to %s, %s", b.Upper.StringFixed(0), rate)
+	case b.Upper.IsZero():
+		return fmt.Sprintf("Over %s, %s + %s of excess", b.Lower.StringFixed(0), b.BaseTax.StringFixed(2), rate)
+	}
+	return fmt.Sprintf("Over %s to %s, %s + %s of excess",
+		b.Lower.StringFixed(0), b.Upper.StringFixed(0), b.BaseTax.StringFixed(2), rate)
+}
+
+// MonthlyTaxBracket returns the bracket of the monthly table the taxable income falls in
+func MonthlyTaxBracket(taxableIncome decimal.Decimal) TaxBracket {
+	return Current().MonthlyTaxBracket(taxableIncome)
+}
+
+// MonthlyTaxBracket returns the bracket of these rules' monthly table
+func (r *Rules) MonthlyTaxBracket(taxableIncome decimal.Decimal) TaxBracket {
+	return findBracket(taxableIncome, r.monthlyBrackets, r.monthlyRates)
+}
+
+func findBracket(taxableIncome decimal.Decimal, brackets, rates []decimal.Decimal) TaxBracket {
+	if taxableIncome.LessThanOrEqual(brackets[0]) {
+		return TaxBracket{Upper: brackets[0], Rate: rates[0]}
+	}
+	for i := 1; i < len(brackets); i++ {
+		if taxableIncome.LessThanOrEqual(brackets[i]) {
+			return TaxBracket{
+				Lower:   brackets[i-1],
+				Upper:   brackets[i],
+				Rate:    rates[i],
+				BaseTax: graduatedTax(brackets[i-1], brackets, rates),
+			}
+		}
+	}
+	top := brackets[len(brackets)-1]
+	return TaxBracket{Lower: top, Rate: rates[len(rates)-1], BaseTax: graduatedTax(top, brackets, rates)}
+}
+
+// SSSRange is the compensation range of the SSS table a monthly income falls in
+type SSSRange struct {
+	Low          decimal.Decimal // zero for the lowest range
+	High         decimal.Decimal // incomes below this amount, zero for the highest range
+	SalaryCredit decimal.Decimal
+}
+
+func (r SSSRange) String() string {
+	switch {
+	case r.Low.IsZero():
+		return fmt.Sprintf("Below %s, MSC %s", r.High.StringFixed(0), r.SalaryCredit.StringFixed(0))
+	case r.High.IsZero():
+		return fmt.Sprintf("%s and over, MSC %s", r.Low.StringFixed(0), r.SalaryCredit.StringFixed(0))
+	}
+	return fmt.Sprintf("%s to below %s, MSC %s", r.Low.StringFixed(0), r.High.StringFixed(0), r.SalaryCredit.StringFixed(0))
+}
+
+// SSSSalaryCredit returns the monthly salary credit (MSC) for a monthly income
+func SSSSalaryCredit(monthlyIncome decimal.Decimal) decimal.Decimal {
+	return Current().SSSSalaryCredit(monthlyIncome)
+}
+
+// SSSSalaryCredit returns the monthly salary credit under these rules
+func (r *Rules) SSSSalaryCredit(monthlyIncome decimal.Decimal) decimal.Decimal {
+	return r.sssCredit(monthlyIncome, r.SSS.MinCredit)
+}
+
+// sssCredit rounds an income to a salary credit between the given floor and the maximum credit
+func (r *Rules) sssCredit(monthlyIncome, sssMinCredit decimal.Decimal) decimal.Decimal {
+	/* Notice that based on the 2023 SSS Table,
+	the salary credit based on the monthly income is
+	the nearest multiple of 500, except when it is lower than 4250 it is automatically 4000,
+	and when it is greater than or equal to 29750 it is automatically 30000 */
+	sssMaxCredit, sssStep := r.SSS.MaxCredit, r.SSS.Step
+	half := sssStep.Div(decimal.NewFromInt(2))
+	if monthlyIncome.LessThan(sssMinCredit.Add(half)) {
+		return sssMinCredit
+	} else if monthlyIncome.GreaterThanOrEqual(sssMaxCredit.Sub(half)) {
+		return sssMaxCredit
+	}
+
+	// implementation of MROUND(monthlyIncome, 500)
+	divided := monthlyIncome.Div(sssStep)
+	floor := divided.RoundDown(0)
+	ceil := divided.RoundUp(0)
+	if divided.Sub(floor).LessThan(ceil.Sub(divided)) {
+		return floor.Mul(sssStep)
+	}
+	return ceil.Mul(sssStep)
+}
+
+// SSSRangeOf returns the compensation range a monthly income falls in
+func SSSRangeOf(monthlyIncome decimal.Decimal) SSSRange {
+	return Current().SSSRangeOf(monthlyIncome)
+}
+
+// SSSRangeOf returns the compensation range under these rules
+func (r *Rules) SSSRangeOf(monthlyIncome decimal.Decimal) SSSRange {
+	return r.sssRange(monthlyIncome, r.SSS.MinCredit)
+}
+
+// sssRange returns the compensation range of a table starting at the given floor
+func (r *Rules) sssRange(monthlyIncome, minCredit decimal.Decimal) SSSRange {
+	credit := r.sssCredit(monthlyIncome, minCredit)
+	half := r.SSS.Step.Div(decimal.NewFromInt(2))
+	rng := SSSRange{Low: credit.Sub(half), High: credit.Add(half), SalaryCredit: credit}
+	if credit.Equal(minCredit) {
+		rng.Low = decimal.Zero
+	}
+	if credit.Equal(r.SSS.MaxCredit) {
+		rng.High = decimal.Zero
+	}
+	return rng
+}
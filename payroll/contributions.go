@@ -0,0 +1,209 @@
+package payroll
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// PhilHealthTable is the premium charged as a rate of income between a floor and a ceiling
+type PhilHealthTable struct {
+	Rate          decimal.Decimal `toml:"rate" yaml:"rate"`                 // employee share of the premium rate
+	FloorIncome   decimal.Decimal `toml:"floor_income" yaml:"floor_income"` // incomes up to this amount pay the Floor
+	Floor         decimal.Decimal `toml:"floor" yaml:"floor"`
+	CeilingIncome decimal.Decimal `toml:"ceiling_income" yaml:"ceiling_income"` // incomes from this amount pay the Ceiling
+	Ceiling       decimal.Decimal `toml:"ceiling" yaml:"ceiling"`
+
+	// The employer's half of the premium, between the same incomes
+	EmployerRate    decimal.Decimal `toml:"employer_rate" yaml:"employer_rate"`
+	EmployerFloor   decimal.Decimal `toml:"employer_floor" yaml:"employer_floor"`
+	EmployerCeiling decimal.Decimal `toml:"employer_ceiling" yaml:"employer_ceiling"`
+
+	// Direct contributors outside employment pay the whole premium, with no
+	// employer share, between the same incomes
+	DirectRate    decimal.Decimal `toml:"direct_rate" yaml:"direct_rate"`
+	DirectFloor   decimal.Decimal `toml:"direct_floor" yaml:"direct_floor"`
+	DirectCeiling decimal.Decimal `toml:"direct_ceiling" yaml:"direct_ceiling"`
+}
+
+// PagIbigTable is the Pag-IBIG contribution, a lower rate for small incomes and a maximum
+type PagIbigTable struct {
+	LowIncome decimal.Decimal `toml:"low_income" yaml:"low_income"` // incomes up to this amount pay LowRate
+	LowRate   decimal.Decimal `toml:"low_rate" yaml:"low_rate"`
+	Rate      decimal.Decimal `toml:"rate" yaml:"rate"`
+	Max       decimal.Decimal `toml:"max" yaml:"max"`
+
+	EmployerRate decimal.Decimal `toml:"employer_rate" yaml:"employer_rate"` // on every income
+	EmployerMax  decimal.Decimal `toml:"employer_max" yaml:"employer_max"`
+
+	Housing HousingLoanTable `toml:"housing" yaml:"housing"`
+
+	// Dividend rates declared on MP2 savings, oldest first
+	MP2Dividends []MP2Dividend `toml:"mp2_dividends" yaml:"mp2_dividends"`
+}
+
+// MP2Dividend is the dividend Pag-IBIG declared for a year's MP2 savings
+type MP2Dividend struct {
+	Year int             `toml:"year" yaml:"year"`
+	Rate decimal.Decimal `toml:"rate" yaml:"rate"` // 0.0705 for 7.05%
+}
+
+// HousingLoanTable is what Pag-IBIG lends for a home: the share of the pay the
+// amortization may take, the limits, and the interest rate of each repricing period
+type HousingLoanTable struct {
+	CapacityRatio decimal.Decimal `toml:"capacity_ratio" yaml:"capacity_ratio"`
+	MaxLoan       decimal.Decimal `toml:"max_loan" yaml:"max_loan"`
+	MaxTermYears  int             `toml:"max_term_years" yaml:"max_term_years"`
+	MaxAge        int             `toml:"max_age" yaml:"max_age"` // age the loan must be repaid by
+	Rates         []HousingRate   `toml:"rates" yaml:"rates"`
+}
+
+// HousingRate is the yearly interest of a housing loan fixed for a number of years
+type HousingRate struct {
+	FixingYears int             `toml:"fixing_years" yaml:"fixing_years"`
+	Rate        decimal.Decimal `toml:"rate" yaml:"rate"`
+}
+
+// RateFor returns the interest rate fixed for the given number of years
+func (h HousingLoanTable) RateFor(fixingYears int) (decimal.Decimal, error) {
+	var periods []string
+	for _, r := range h.Rates {
+		if r.FixingYears == fixingYears {
+			return r.Rate, nil
+		}
+		periods = append(periods, fmt.Sprint(r.FixingYears))
+	}
+	return decimal.Zero, fmt.Errorf("no housing loan rate fixed for %d years, use %s", fixingYears, strings.Join(periods, ", "))
+}
+
+// CalculateSSSContributions returns the employee share of the monthly SSS contribution
+func CalculateSSSContributions(monthlyIncome decimal.Decimal) decimal.Decimal {
+	return Current().CalculateSSSContributions(monthlyIncome)
+}
+
+// CalculateSSSContributions returns the employee share under these rules
+func (r *Rules) CalculateSSSContributions(monthlyIncome decimal.Decimal) decimal.Decimal {
+	return r.ExplainSSS(monthlyIncome).Result
+}
+
+// ExplainSSS computes the SSS contribution and records the salary credit used
+func ExplainSSS(monthlyIncome decimal.Decimal) Trace {
+	return Current().ExplainSSS(monthlyIncome)
+}
+
+// ExplainSSS traces the SSS contribution under these rules
+func (r *Rules) ExplainSSS(monthlyIncome decimal.Decimal) Trace {
+	/* The gross contribution is the monthly salary credit from the 2023 SSS Table.
+	This is then multiplied by 4.5% to get the employee's actual SSS contribution */
+	employeeRate := r.SSS.EmployeeRate
+
+	t := Trace{Name: "SSS Contribution"}
+	t.add(Step{Rule: "Monthly income", Amount: monthlyIncome})
+
+	msc := r.SSSRangeOf(monthlyIncome)
+	t.add(Step{
+		Rule:   "Monthly salary credit for " + msc.String(),
+		Amount: msc.SalaryCredit,
+		Capped: msc.Low.IsZero() || msc.High.IsZero(),
+	})
+
+	t.Result = msc.SalaryCredit.Mul(employeeRate)
+	t.add(Step{Rule: "Employee share, " + percent(employeeRate) + " of salary credit", Amount: t.Result, Rate: employeeRate})
+	return t
+}
+
+// CalculatePagIbigContributions returns the employee share of the monthly Pag-IBIG contribution
+func CalculatePagIbigContributions(monthlyIncome decimal.Decimal) decimal.Decimal {
+	return Current().CalculatePagIbigContributions(monthlyIncome)
+}
+
+// CalculatePagIbigContributions returns the employee share under these rules
+func (r *Rules) CalculatePagIbigContributions(monthlyIncome decimal.Decimal) decimal.Decimal {
+	return r.ExplainPagIbig(monthlyIncome).Result
+}
+
+// ExplainPagIbig computes the Pag-IBIG contribution and records the rate and cap applied
+func ExplainPagIbig(monthlyIncome decimal.Decimal) Trace {
+	return Current().ExplainPagIbig(monthlyIncome)
+}
+
+// ExplainPagIbig traces the Pag-IBIG contribution under these rules
+func (r *Rules) ExplainPagIbig(monthlyIncome decimal.Decimal) Trace {
+	/* The https://taxcalculatorphilippines.com/ still uses the 2021 Pag-Ibig contribution table
+	This takes the monthly income and multiplies it by 1% if it is less than or equal to 1500,
+	otherwise it multiplies it by 2%
+
+	The maximum pag-ibig contribution is 100.00
+	*/
+	var rate decimal.Decimal
+	max := r.PagIbig.Max
+
+	t := Trace{Name: "Pag-IBIG Contribution"}
+	t.add(Step{Rule: "Monthly income", Amount: monthlyIncome})
+
+	if monthlyIncome.LessThanOrEqual(r.PagIbig.LowIncome) {
+		rate = r.PagIbig.LowRate
+		t.add(Step{Rule: "Income of " + r.PagIbig.LowIncome.StringFixed(0) + " or less, " + percent(rate) + " of income",
+			Amount: monthlyIncome.Mul(rate), Rate: rate})
+	} else {
+		rate = r.PagIbig.Rate
+		t.add(Step{Rule: "Income over " + r.PagIbig.LowIncome.StringFixed(0) + ", " + percent(rate) + " of income",
+			Amount: monthlyIncome.Mul(rate), Rate: rate})
+	}
+
+	t.Result = decimal.Min(max, monthlyIncome.Mul(rate))
+	if monthlyIncome.Mul(rate).GreaterThan(max) {
+		t.add(Step{Rule: "Maximum contribution of " + max.StringFixed(2), Amount: max, Capped: true})
+	}
+	return t
+}
+
+// CalculatePhilHealthContributions returns the employee share of the monthly PhilHealth premium
+func CalculatePhilHealthContributions(monthlyIncome decimal.Decimal) decimal.Decimal {
+	return Current().CalculatePhilHealthContributions(monthlyIncome)
+}
+
+// CalculatePhilHealthContributions returns the employee share under these rules
+func (r *Rules) CalculatePhilHealthContributions(monthlyIncome decimal.Decimal) decimal.Decimal {
+	return r.ExplainPhilHealth(monthlyIncome).Result
+}
+
+// ExplainPhilHealth computes the PhilHealth premium and records the floor or ceiling hit
+func ExplainPhilHealth(monthlyIncome decimal.Decimal) Trace {
+	return Current().ExplainPhilHealth(monthlyIncome)
+}
+
+// ExplainPhilHealth traces the PhilHealth premium under these rules
+func (r *Rules) ExplainPhilHealth(monthlyIncome decimal.Decimal) Trace {
+	/* The 2023 contribution rate for Philhealth is 4.5%
+	which is split equally between the employee and employer.
+	People have to give at least 225 and max 2025
+	People with <= 10000 salary must contribute 225
+	2025 is max amount anyone can contribute
+	NOTE: There's a mistake on https://taxcalculatorphilippines.com/ where
+	starting salary of 90000, it outputs 4050 for Philhealth instead of 2025
+	*/
+	return r.philHealthTrace(monthlyIncome, r.PhilHealth.Rate, r.PhilHealth.Floor, r.PhilHealth.Ceiling, "Employee share")
+}
+
+// philHealthTrace traces a premium of rate times the income, between the
+// floor and ceiling premiums
+func (r *Rules) philHealthTrace(monthlyIncome, rate, min, max decimal.Decimal, share string) Trace {
+	t := Trace{Name: "PhilHealth Contribution"}
+	t.add(Step{Rule: "Monthly income", Amount: monthlyIncome})
+
+	if monthlyIncome.LessThanOrEqual(r.PhilHealth.FloorIncome) {
+		t.Result = min
+		t.add(Step{Rule: "Income of " + r.PhilHealth.FloorIncome.StringFixed(0) + " or less, minimum premium",
+			Amount: min, Capped: true})
+	} else if monthlyIncome.GreaterThanOrEqual(r.PhilHealth.CeilingIncome) {
+		t.Result = max
+		t.add(Step{Rule: "Income of " + r.PhilHealth.CeilingIncome.StringFixed(0) + " or more, maximum premium",
+			Amount: t.Result, Capped: true})
+	} else {
+		t.Result = monthlyIncome.Mul(rate)
+		t.add(Step{Rule: share + ", " + percent(rate) + " of income", Amount: t.Result, Rate: rate})
+	}
+	return t
+}
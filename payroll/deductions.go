@@ -0,0 +1,304 @@
+package payroll
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// ContributionCalculator computes one deduction from a monthly income. SSS,
+// PhilHealth and Pag-IBIG implement it, and so can a company's own deductions
+// such as cooperative share capital or union dues
+type ContributionCalculator interface {
+	// Name labels the deduction on breakdowns and must be unique in a registry
+	Name() string
+	// Calculate returns the monthly deduction for a monthly income
+	Calculate(r *Rules, monthlyIncome decimal.Decimal) decimal.Decimal
+	// Explain records how Calculate arrived at its result
+	Explain(r *Rules, monthlyIncome decimal.Decimal) Trace
+}
+
+// Timing is when a deduction is taken relative to income tax
+type Timing int
+
+const (
+	// PreTax deductions are taken before income tax and lower the taxable income
+	PreTax Timing = iota
+	// PostTax deductions are taken from the pay left after income tax
+	PostTax
+)
+
+func (t Timing) String() string {
+	if t == PostTax {
+		return "post-tax"
+	}
+	return "pre-tax"
+}
+
+// MarshalText writes the timing as "pre-tax" or "post-tax" in JSON
+func (t Timing) MarshalText() ([]byte, error) { return []byte(t.String()), nil }
+
+// UnmarshalText reads "pre-tax" or "post-tax"
+func (t *Timing) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "pre-tax":
+		*t = PreTax
+	case "post-tax":
+		*t = PostTax
+	default:
+		return fmt.Errorf("timing must be pre-tax or post-tax, not %q", text)
+	}
+	return nil
+}
+
+// Deduction is a calculator as registered, with when and in what order it applies
+type Deduction struct {
+	Calculator ContributionCalculator
+	Timing     Timing
+	Order      int // deductions run from the lowest order up
+}
+
+// DeductionLine is the amount of a deduction other than SSS, PhilHealth and Pag-IBIG
+type DeductionLine struct {
+	Name   string
+	Amount decimal.Decimal
+	Timing Timing
+}
+
+// Registry decides which deductions are taken from the pay, in what order and
+// whether before or after income tax
+type Registry struct {
+	mu         sync.RWMutex
+	deductions []Deduction
+}
+
+// NewRegistry returns a registry holding only the statutory contributions
+func NewRegistry() *Registry {
+	g := &Registry{}
+	g.Register(SSSCalculator, PreTax, 10)
+	g.Register(PhilHealthCalculator, PreTax, 20)
+	g.Register(PagIbigCalculator, PreTax, 30)
+	return g
+}
+
+// Register adds a deduction. Statutory contributions use orders 10 to 30
+func (g *Registry) Register(c ContributionCalculator, timing Timing, order int) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, d := range g.deductions {
+		if d.Calculator.Name() == c.Name() {
+			return fmt.Errorf("a deduction named %q is already registered", c.Name())
+		}
+	}
+	g.deductions = append(g.deductions, Deduction{Calculator: c, Timing: timing, Order: order})
+	sort.SliceStable(g.deductions, func(i, j int) bool { return g.deductions[i].Order < g.deductions[j].Order })
+	return nil
+}
+
+// Unregister removes the named deduction and reports whether it was registered
+func (g *Registry) Unregister(name string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for i, d := range g.deductions {
+		if d.Calculator.Name() == name {
+			g.deductions = append(g.deductions[:i], g.deductions[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Deductions lists the registered deductions in the order they run
+func (g *Registry) Deductions() []Deduction {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return append([]Deduction(nil), g.deductions...)
+}
+
+// Deductions is the registry the calculators apply
+var Deductions = NewRegistry()
+
+// Register adds a deduction to the registry the calculators apply
+func Register(c ContributionCalculator, timing Timing, order int) error {
+	return Deductions.Register(c, timing, order)
+}
+
+// The statutory contributions as calculators, so they can be ordered and
+// timed like any other deduction
+var (
+	SSSCalculator        ContributionCalculator = sssCalculator{}
+	PhilHealthCalculator ContributionCalculator = philHealthCalculator{}
+	PagIbigCalculator    ContributionCalculator = pagIbigCalculator{}
+)
+
+type sssCalculator struct{}
+
+func (sssCalculator) Name() string { return "SSS Contribution" }
+func (sssCalculator) Calculate(r *Rules, monthlyIncome decimal.Decimal) decimal.Decimal {
+	return r.CalculateSSSContributions(monthlyIncome)
+}
+func (sssCalculator) Explain(r *Rules, monthlyIncome decimal.Decimal) Trace {
+	return r.ExplainSSS(monthlyIncome)
+}
+
+type philHealthCalculator struct{}
+
+func (philHealthCalculator) Name() string { return "PhilHealth Contribution" }
+func (philHealthCalculator) Calculate(r *Rules, monthlyIncome decimal.Decimal) decimal.Decimal {
+	return r.CalculatePhilHealthContributions(monthlyIncome)
+}
+func (philHealthCalculator) Explain(r *Rules, monthlyIncome decimal.Decimal) Trace {
+	return r.ExplainPhilHealth(monthlyIncome)
+}
+
+type pagIbigCalculator struct{}
+
+func (pagIbigCalculator) Name() string { return "Pag-IBIG Contribution" }
+func (pagIbigCalculator) Calculate(r *Rules, monthlyIncome decimal.Decimal) decimal.Decimal {
+	return r.CalculatePagIbigContributions(monthlyIncome)
+}
+func (pagIbigCalculator) Explain(r *Rules, monthlyIncome decimal.Decimal) Trace {
+	return r.ExplainPagIbig(monthlyIncome)
+}
+
+// FixedDeduction takes the same amount every month, such as cooperative share
+// capital or HMO premiums for dependents. Incomes below the amount are taken whole
+type FixedDeduction struct {
+	Label  string
+	Amount decimal.Decimal
+}
+
+func (f FixedDeduction) Name() string { return f.Label }
+
+func (f FixedDeduction) Calculate(r *Rules, monthlyIncome decimal.Decimal) decimal.Decimal {
+	return f.Explain(r, monthlyIncome).Result
+}
+
+func (f FixedDeduction) Explain(r *Rules, monthlyIncome decimal.Decimal) Trace {
+	t := Trace{Name: f.Label}
+	t.add(Step{Rule: "Fixed monthly amount", Amount: f.Amount})
+	t.Result = decimal.Max(decimal.Zero, decimal.Min(f.Amount, monthlyIncome))
+	if t.Result.LessThan(f.Amount) {
+		t.add(Step{Rule: "Limited to the monthly income", Amount: t.Result, Capped: true})
+	}
+	return t
+}
+
+// RateDeduction takes a rate of the monthly income, such as union dues,
+// up to an optional maximum
+type RateDeduction struct {
+	Label string
+	Rate  decimal.Decimal
+	Max   decimal.Decimal // no maximum when zero
+}
+
+func (d RateDeduction) Name() string { return d.Label }
+
+func (d RateDeduction) Calculate(r *Rules, monthlyIncome decimal.Decimal) decimal.Decimal {
+	return d.Explain(r, monthlyIncome).Result
+}
+
+func (d RateDeduction) Explain(r *Rules, monthlyIncome decimal.Decimal) Trace {
+	t := Trace{Name: d.Label}
+	t.add(Step{Rule: "Monthly income", Amount: monthlyIncome})
+	t.Result = monthlyIncome.Mul(d.Rate)
+	t.add(Step{Rule: percent(d.Rate) + " of income", Amount: t.Result, Rate: d.Rate})
+	if d.Max.IsPositive() && t.Result.GreaterThan(d.Max) {
+		t.Result = d.Max
+		t.add(Step{Rule: "Maximum of " + d.Max.StringFixed(2), Amount: d.Max, Capped: true})
+	}
+	return t
+}
+
+// deductionsFile is the layout of a file of company deductions:
+//
+//	[[deduction]]
+//	name = "Union dues"
+//	rate = 0.01
+//	max = 300
+//	timing = "post-tax"
+//	order = 100
+//
+// A deduction has either an amount, taken every month, or a rate of the
+// monthly income with an optional max. Timing is "pre-tax" or "post-tax"
+type deductionsFile struct {
+	Deduction []struct {
+		Name   string           `toml:"name" yaml:"name"`
+		Amount *decimal.Decimal `toml:"amount" yaml:"amount"`
+		Rate   *decimal.Decimal `toml:"rate" yaml:"rate"`
+		Max    decimal.Decimal  `toml:"max" yaml:"max"`
+		Timing string           `toml:"timing" yaml:"timing"`
+		Order  int              `toml:"order" yaml:"order"`
+	} `toml:"deduction" yaml:"deduction"`
+}
+
+// RegisterFile registers the deductions listed in a TOML or YAML file. A
+// missing file registers nothing. Nothing is registered if any entry is invalid
+func RegisterFile(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	var f deductionsFile
+	if err := decodeFile(path, data, &f); err != nil {
+		return err
+	}
+
+	var deductions []Deduction
+	var errs []error
+	for i, d := range f.Deduction {
+		at := fmt.Sprintf("%s: deduction %d", path, i+1)
+		var timing Timing
+		switch d.Timing {
+		case "pre-tax":
+			timing = PreTax
+		case "post-tax", "":
+			timing = PostTax
+		default:
+			errs = append(errs, fmt.Errorf("%s: timing must be pre-tax or post-tax, not %q", at, d.Timing))
+		}
+		switch {
+		case d.Name == "":
+			errs = append(errs, fmt.Errorf("%s: name is missing", at))
+		case (d.Amount == nil) == (d.Rate == nil):
+			errs = append(errs, fmt.Errorf("%s: give either an amount or a rate", at))
+		case d.Amount != nil && !d.Amount.IsPositive():
+			errs = append(errs, fmt.Errorf("%s: amount must be more than zero", at))
+		case d.Rate != nil && (!d.Rate.IsPositive() || d.Rate.GreaterThan(one)):
+			errs = append(errs, fmt.Errorf("%s: rate must be more than 0 and at most 1", at))
+		case d.Amount != nil:
+			deductions = append(deductions, Deduction{FixedDeduction{d.Name, *d.Amount}, timing, d.Order})
+		default:
+			deductions = append(deductions, Deduction{RateDeduction{d.Name, *d.Rate, d.Max}, timing, d.Order})
+		}
+	}
+	names := map[string]bool{}
+	for _, d := range Deductions.Deductions() {
+		names[d.Calculator.Name()] = true
+	}
+	for _, d := range deductions {
+		if names[d.Calculator.Name()] {
+			errs = append(errs, fmt.Errorf("%s: a deduction named %q is already registered", path, d.Calculator.Name()))
+		}
+		names[d.Calculator.Name()] = true
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	for _, d := range deductions {
+		if err := Register(d.Calculator, d.Timing, d.Order); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
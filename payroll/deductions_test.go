@@ -0,0 +1,54 @@
+package payroll
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// register adds a company deduction for the length of a test
+func register(t *testing.T, c ContributionCalculator, timing Timing) {
+	t.Helper()
+	if err := Register(c, timing, 100); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Deductions.Unregister(c.Name()) })
+}
+
+func TestReverseWithCompanyDeductions(t *testing.T) {
+	tests := []struct {
+		name      string
+		deduction ContributionCalculator
+		timing    Timing
+		netPay    int64
+	}{
+		{"fixed deduction above the net pay", FixedDeduction{Label: "Cooperative", Amount: decimal.NewFromInt(20000)}, PreTax, 1000},
+		{"fixed post-tax deduction", FixedDeduction{Label: "HMO dependents", Amount: decimal.NewFromInt(20000)}, PostTax, 1000},
+		{"large net pay", FixedDeduction{Label: "Cooperative", Amount: decimal.NewFromInt(50000)}, PostTax, 100000},
+		{"rate deduction", RateDeduction{Label: "Union dues", Rate: decimal.NewFromFloat(0.4)}, PostTax, 20000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			register(t, tt.deduction, tt.timing)
+			target := decimal.NewFromInt(tt.netPay)
+			r, err := Reverse(target, Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.NetPayAfterDeductions.LessThan(target) {
+				t.Errorf("gross %s leaves %s, below %s", r.MonthlyIncome, r.NetPayAfterDeductions, target)
+			}
+			less := Compute(r.MonthlyIncome.Sub(decimal.NewFromFloat(0.01)))
+			if !less.NetPayAfterDeductions.LessThan(target) {
+				t.Errorf("gross %s is not where the net pay crosses %s", r.MonthlyIncome, target)
+			}
+		})
+	}
+}
+
+func TestReverseUnreachable(t *testing.T) {
+	register(t, RateDeduction{Label: "Everything", Rate: decimal.NewFromInt(1)}, PostTax)
+	if r, err := Reverse(decimal.NewFromInt(1000), Options{}); err == nil {
+		t.Errorf("gross %s accepted with net pay %s", r.MonthlyIncome, r.NetPayAfterDeductions)
+	}
+}
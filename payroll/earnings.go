@@ -0,0 +1,242 @@
+package payroll
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// EarningType is the kind of an earning line, it decides the line's default
+// tax and contribution treatment
+type EarningType string
+
+const (
+	BasicPay            EarningType = "basic"
+	Commission          EarningType = "commission"
+	TaxableAllowance    EarningType = "taxable-allowance"
+	NonTaxableAllowance EarningType = "non-taxable-allowance" // de minimis benefits
+	Reimbursement       EarningType = "reimbursement"
+)
+
+// EarningTypes lists the earning types in display order
+var EarningTypes = []EarningType{BasicPay, Commission, TaxableAllowance, NonTaxableAllowance, Reimbursement}
+
+// String names the type on screens and payslips
+func (t EarningType) String() string {
+	switch t {
+	case BasicPay:
+		return "Basic Pay"
+	case Commission:
+		return "Commission"
+	case TaxableAllowance:
+		return "Taxable Allowance"
+	case NonTaxableAllowance:
+		return "Non-Taxable Allowance"
+	case Reimbursement:
+		return "Reimbursement"
+	}
+	return string(t)
+}
+
+// ParseEarningType converts a name such as "taxable-allowance" into an EarningType
+func ParseEarningType(s string) (EarningType, error) {
+	for _, t := range EarningTypes {
+		if string(t) == s || t.String() == s {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("unknown earning type %q", s)
+}
+
+// Defaults tells whether an earning of this type is usually taxable and part
+// of the SSS, PhilHealth and Pag-IBIG base. Basic pay and commissions are both,
+// allowances are taxed but not contributed on, de minimis benefits and
+// reimbursements are neither
+func (t EarningType) Defaults() (taxable, contributionBase bool) {
+	switch t {
+	case BasicPay, Commission:
+		return true, true
+	case TaxableAllowance:
+		return true, false
+	}
+	return false, false
+}
+
+// Earning is one line of pay
+type Earning struct {
+	Name             string
+	Type             EarningType
+	Amount           decimal.Decimal
+	Taxable          bool // counted in the taxable income
+	ContributionBase bool // counted in the income SSS, PhilHealth and Pag-IBIG are computed on
+}
+
+// NewEarning returns a line of the given type named and flagged as the type's defaults
+func NewEarning(t EarningType, amount decimal.Decimal) Earning {
+	taxable, base := t.Defaults()
+	return Earning{Name: t.String(), Type: t, Amount: amount, Taxable: taxable, ContributionBase: base}
+}
+
+// isWage tells whether the line counts as pay in the minimum wage check.
+// De minimis benefits and reimbursements do not, and lines without a type
+// count when they are taxable
+func (e Earning) isWage() bool {
+	switch e.Type {
+	case BasicPay, Commission, TaxableAllowance:
+		return true
+	case NonTaxableAllowance, Reimbursement:
+		return false
+	}
+	return e.Taxable
+}
+
+// EarningsBreakdown is the pay split into its lines, with what was taxed and
+// what contributions were computed on
+type EarningsBreakdown struct {
+	Lines            []Earning
+	Gross            decimal.Decimal
+	Taxable          decimal.Decimal
+	NonTaxable       decimal.Decimal
+	ContributionBase decimal.Decimal
+}
+
+// Breakdown adds up earning lines
+func Breakdown(earnings []Earning) EarningsBreakdown {
+	b := EarningsBreakdown{Lines: append([]Earning(nil), earnings...)}
+	for _, e := range earnings {
+		b.Gross = b.Gross.Add(e.Amount)
+		if e.Taxable {
+			b.Taxable = b.Taxable.Add(e.Amount)
+		} else {
+			b.NonTaxable = b.NonTaxable.Add(e.Amount)
+		}
+		if e.ContributionBase {
+			b.ContributionBase = b.ContributionBase.Add(e.Amount)
+		}
+	}
+	return b
+}
+
+// validateEarnings reports every line that cannot be computed
+func validateEarnings(earnings []Earning) error {
+	if len(earnings) == 0 {
+		return errors.New("no earnings given")
+	}
+	var errs []error
+	for i, e := range earnings {
+		if strings.TrimSpace(e.Name) == "" {
+			errs = append(errs, fmt.Errorf("earning %d: name is missing", i+1))
+		}
+		if e.Type != "" {
+			if _, err := ParseEarningType(string(e.Type)); err != nil {
+				errs = append(errs, fmt.Errorf("earning %d: %w", i+1, err))
+			}
+		}
+		if e.Amount.IsNegative() {
+			errs = append(errs, fmt.Errorf("earning %d: amount cannot be negative", i+1))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ComputeEarnings runs monthly earning lines through the contribution and tax
+// calculators. Contributions are computed on the lines in the contribution base
+// and income tax on the taxable lines less the pre-tax deductions
+func ComputeEarnings(earnings []Earning) TaxInputs {
+	return Current().ComputeEarnings(earnings)
+}
+
+// ComputeEarnings runs monthly earning lines through the calculators of these rules
+func (r *Rules) ComputeEarnings(earnings []Earning) TaxInputs {
+	b := Breakdown(earnings)
+	t := r.compute(b.Gross, b.Taxable, b.ContributionBase, Deductions.Deductions())
+	t.Earnings = &b
+	return t
+}
+
+// ComputeEarningsWith computes the pay for one pay period from its earning
+// lines, each an amount for the period, like ComputeWith does for a single income
+func ComputeEarningsWith(earnings []Earning, opts Options) (TaxInputs, error) {
+	r, err := ForYear(opts.Year)
+	if err != nil {
+		return TaxInputs{}, err
+	}
+	return r.ComputeEarningsWith(earnings, opts)
+}
+
+// ComputeEarningsWith computes the pay for one pay period under these rules
+func (r *Rules) ComputeEarningsWith(earnings []Earning, opts Options) (TaxInputs, error) {
+	if err := opts.validate(r); err != nil {
+		return TaxInputs{}, err
+	}
+	if err := validateEarnings(earnings); err != nil {
+		return TaxInputs{}, err
+	}
+
+	monthly := toMonthly(earnings, opts.PayFrequency)
+	wage := decimal.Zero
+	for _, e := range monthly {
+		if e.isWage() {
+			wage = wage.Add(e.Amount)
+		}
+	}
+	if err := opts.checkWage(r, wage); err != nil {
+		return TaxInputs{}, err
+	}
+	result := r.applyOptions(r.ComputeEarnings(monthly), opts)
+
+	// The lines are shown as entered rather than split back from the monthly figures
+	b := Breakdown(earnings)
+	result.MonthlyIncome = b.Gross
+	result.Earnings = &b
+	return result, nil
+}
+
+// ExplainEarnings computes the deductions on monthly earning lines and records the rules applied
+func ExplainEarnings(earnings []Earning) []Trace {
+	return Current().ExplainEarnings(earnings)
+}
+
+// ExplainEarnings traces the deductions on monthly earning lines under these rules
+func (r *Rules) ExplainEarnings(earnings []Earning) []Trace {
+	b := Breakdown(earnings)
+	return r.explain(b.Taxable, b.ContributionBase)
+}
+
+// ExplainEarningsWith traces the deductions on the earning lines of one pay
+// period, with the exemptions of the employee type
+func (r *Rules) ExplainEarningsWith(earnings []Earning, opts Options) ([]Trace, error) {
+	if err := opts.validate(r); err != nil {
+		return nil, err
+	}
+	monthly := toMonthly(earnings, opts.PayFrequency)
+	b := Breakdown(monthly)
+	return r.explainOptions(r.explain(b.Taxable, b.ContributionBase), b.Gross, b.Taxable, opts), nil
+}
+
+// toMonthly converts earning lines received every pay period into their monthly equivalents
+func toMonthly(earnings []Earning, f PayFrequency) []Earning {
+	monthly := make([]Earning, len(earnings))
+	for i, e := range earnings {
+		e.Amount = f.ToMonthly(e.Amount)
+		monthly[i] = e
+	}
+	return monthly
+}
+
+// perPeriod splits the breakdown across the paydays of a month
+func (b EarningsBreakdown) perPeriod(split func(decimal.Decimal) decimal.Decimal) *EarningsBreakdown {
+	result := &EarningsBreakdown{
+		Gross:            split(b.Gross),
+		Taxable:          split(b.Taxable),
+		NonTaxable:       split(b.NonTaxable),
+		ContributionBase: split(b.ContributionBase),
+	}
+	for _, e := range b.Lines {
+		e.Amount = split(e.Amount)
+		result.Lines = append(result.Lines, e)
+	}
+	return result
+}
@@ -0,0 +1,52 @@
+package payroll
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// lines builds monthly earning lines of the default treatment from type and amount pairs
+func lines(pairs ...interface{}) []Earning {
+	var earnings []Earning
+	for i := 0; i < len(pairs); i += 2 {
+		earnings = append(earnings, NewEarning(pairs[i].(EarningType), decimal.NewFromInt(int64(pairs[i+1].(int)))))
+	}
+	return earnings
+}
+
+func TestComputeEarningsKasambahayWage(t *testing.T) {
+	opts := Options{EmployeeType: Kasambahay, Region: "NCR"} // minimum of 6,000 a month
+
+	tests := []struct {
+		name     string
+		earnings []Earning
+		below    bool
+	}{
+		{"basic pay at the minimum", lines(BasicPay, 6000), false},
+		{"basic pay below", lines(BasicPay, 5000), true},
+		{"reimbursement does not make up the wage", lines(BasicPay, 5000, Reimbursement, 2000), true},
+		{"de minimis does not make up the wage", lines(BasicPay, 5000, NonTaxableAllowance, 1000), true},
+		{"taxable allowance counts", lines(BasicPay, 5000, TaxableAllowance, 1000), false},
+		{"commission counts", lines(BasicPay, 4000, Commission, 2500), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ComputeEarningsWith(tt.earnings, opts)
+			if got := errors.Is(err, ErrBelowMinimumWage); got != tt.below {
+				t.Errorf("error %v, want below the minimum %v", err, tt.below)
+			}
+		})
+	}
+
+	// A line without a type counts when it is taxable
+	custom := Earning{Name: "Rice subsidy", Amount: decimal.NewFromInt(1500)}
+	if _, err := ComputeEarningsWith(append(lines(BasicPay, 5000), custom), opts); !errors.Is(err, ErrBelowMinimumWage) {
+		t.Errorf("non-taxable custom line made up the wage: %v", err)
+	}
+	custom.Taxable = true
+	if _, err := ComputeEarningsWith(append(lines(BasicPay, 5000), custom), opts); err != nil {
+		t.Errorf("taxable custom line: %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package payroll
+
+import (
+	"errors"
+
+	"github.com/shopspring/decimal"
+)
+
+// ServiceIncentiveLeaveDays is the paid leave the Labor Code grants every
+// employee with a year of service
+const ServiceIncentiveLeaveDays = 5
+
+// EmployerCostInputs is what an employee costs besides the salary
+type EmployerCostInputs struct {
+	MonthlySalary decimal.Decimal
+	LeaveDays     decimal.Decimal // paid leave days earned a year
+	HMO           decimal.Decimal // monthly HMO premium the employer pays, zero when none
+	EmployeeType  EmployeeType    // a low-paid kasambahay's contributions are paid by the employer
+}
+
+// EmployerCost is the cost of employing someone for a month, or a year when annualised
+type EmployerCost struct {
+	Salary          decimal.Decimal
+	SSS             decimal.Decimal // employer share
+	EC              decimal.Decimal // employees' compensation
+	PhilHealth      decimal.Decimal // employer share
+	PagIbig         decimal.Decimal // employer share
+	ThirteenthMonth decimal.Decimal // accrual, a twelfth of the salary
+	Leave           decimal.Decimal // accrual of the paid leave days
+	HMO             decimal.Decimal
+	Total           decimal.Decimal
+}
+
+// Fields lists the cost in the order it is displayed
+func (c EmployerCost) Fields() []Field {
+	return []Field{
+		{"Salary", c.Salary},
+		{"SSS Employer Share", c.SSS},
+		{"Employees' Compensation", c.EC},
+		{"PhilHealth Employer Share", c.PhilHealth},
+		{"Pag-IBIG Employer Share", c.PagIbig},
+		{"13th Month Accrual", c.ThirteenthMonth},
+		{"Leave Accrual", c.Leave},
+		{"HMO", c.HMO},
+		{"Total Cost", c.Total},
+	}
+}
+
+// Contributions is what the employer remits to SSS, PhilHealth and Pag-IBIG
+func (c EmployerCost) Contributions() decimal.Decimal {
+	return decimal.Sum(c.SSS, c.EC, c.PhilHealth, c.PagIbig)
+}
+
+// Annual is the cost over twelve months
+func (c EmployerCost) Annual() EmployerCost {
+	twelve := decimal.NewFromInt(12)
+	return EmployerCost{
+		Salary:          c.Salary.Mul(twelve),
+		SSS:             c.SSS.Mul(twelve),
+		EC:              c.EC.Mul(twelve),
+		PhilHealth:      c.PhilHealth.Mul(twelve),
+		PagIbig:         c.PagIbig.Mul(twelve),
+		ThirteenthMonth: c.ThirteenthMonth.Mul(twelve),
+		Leave:           c.Leave.Mul(twelve),
+		HMO:             c.HMO.Mul(twelve),
+		Total:           c.Total.Mul(twelve),
+	}
+}
+
+// ComputeEmployerCost computes the monthly cost of employing someone under the current rules
+func ComputeEmployerCost(in EmployerCostInputs) (EmployerCost, error) {
+	return Current().ComputeEmployerCost(in)
+}
+
+// ComputeEmployerCost computes the monthly cost of employing someone under these rules
+func (r *Rules) ComputeEmployerCost(in EmployerCostInputs) (EmployerCost, error) {
+	switch {
+	case in.MonthlySalary.IsNegative():
+		return EmployerCost{}, errors.New("salary cannot be negative")
+	case in.LeaveDays.IsNegative():
+		return EmployerCost{}, errors.New("leave days cannot be negative")
+	case in.HMO.IsNegative():
+		return EmployerCost{}, errors.New("HMO premium cannot be negative")
+	}
+
+	c := EmployerCost{Salary: in.MonthlySalary, HMO: in.HMO}
+	c.SSS, c.EC = r.SSSEmployerContributions(in.MonthlySalary)
+	c.PhilHealth = r.PhilHealthEmployerContributions(in.MonthlySalary)
+	c.PagIbig = r.PagIbigEmployerContributions(in.MonthlySalary)
+	if in.EmployeeType == Kasambahay && r.employerPaysAll(in.MonthlySalary) {
+		c.SSS = c.SSS.Add(r.CalculateSSSContributions(in.MonthlySalary))
+		c.PhilHealth = c.PhilHealth.Add(r.CalculatePhilHealthContributions(in.MonthlySalary))
+		c.PagIbig = c.PagIbig.Add(r.CalculatePagIbigContributions(in.MonthlySalary))
+	}
+	c.ThirteenthMonth = in.MonthlySalary.Div(decimal.NewFromInt(12))
+	c.Leave = DailyRate(in.MonthlySalary).Mul(in.LeaveDays).Div(decimal.NewFromInt(12))
+	c.Total = decimal.Sum(c.Salary, c.Contributions(), c.ThirteenthMonth, c.Leave, c.HMO)
+	return c, nil
+}
+
+// SSSEmployerContributions returns the employer share of the monthly SSS
+// contribution and the employees' compensation premium on top of it
+func (r *Rules) SSSEmployerContributions(monthlyIncome decimal.Decimal) (share, ec decimal.Decimal) {
+	credit := r.SSSSalaryCredit(monthlyIncome)
+	ec = r.SSS.ECHigh
+	if credit.LessThan(r.SSS.ECThreshold) {
+		ec = r.SSS.ECLow
+	}
+	return credit.Mul(r.SSS.EmployerRate), ec
+}
+
+// PhilHealthEmployerContributions returns the employer share of the monthly
+// PhilHealth premium. It has its own rates rather than the employee share's,
+// whose ceiling is the whole premium
+func (r *Rules) PhilHealthEmployerContributions(monthlyIncome decimal.Decimal) decimal.Decimal {
+	p := r.PhilHealth
+	return r.philHealthTrace(monthlyIncome, p.EmployerRate, p.EmployerFloor, p.EmployerCeiling, "Employer share").Result
+}
+
+// PagIbigEmployerContributions returns the employer share of the monthly Pag-IBIG contribution
+func (r *Rules) PagIbigEmployerContributions(monthlyIncome decimal.Decimal) decimal.Decimal {
+	return decimal.Min(r.PagIbig.EmployerMax, monthlyIncome.Mul(r.PagIbig.EmployerRate))
+}
@@ -0,0 +1,70 @@
+package payroll
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestComputeEmployerCost(t *testing.T) {
+	tests := []struct {
+		name string
+		in   EmployerCostInputs
+		// Salary, SSS, EC, PhilHealth, Pag-IBIG, 13th month, leave, HMO and total, as in Fields
+		want []string
+	}{
+		{"mid-range salary",
+			EmployerCostInputs{MonthlySalary: decimal.NewFromInt(30000), LeaveDays: decimal.NewFromInt(ServiceIncentiveLeaveDays)},
+			[]string{"30000.00", "2850.00", "30.00", "675.00", "100.00", "2500.00", "574.71", "0.00", "36729.71"}},
+		{"PhilHealth floor, Pag-IBIG maximum",
+			EmployerCostInputs{MonthlySalary: decimal.NewFromInt(8000)},
+			[]string{"8000.00", "760.00", "10.00", "225.00", "100.00", "666.67", "0.00", "0.00", "9761.67"}},
+		// The employer's half of the premium stops at 2025 though the employee share goes to 4050
+		{"PhilHealth ceiling with HMO",
+			EmployerCostInputs{MonthlySalary: decimal.NewFromInt(100000), LeaveDays: decimal.NewFromInt(ServiceIncentiveLeaveDays), HMO: decimal.NewFromInt(1500)},
+			[]string{"100000.00", "2850.00", "30.00", "2025.00", "100.00", "8333.33", "1915.71", "1500.00", "116754.04"}},
+		// Below 5,000 the employer also pays the kasambahay's 202.50, 225 and 90
+		{"low-paid kasambahay",
+			EmployerCostInputs{MonthlySalary: decimal.NewFromInt(4500), EmployeeType: Kasambahay},
+			[]string{"4500.00", "630.00", "10.00", "450.00", "180.00", "375.00", "0.00", "0.00", "6145.00"}},
+	}
+	for _, tt := range tests {
+		c, err := ComputeEmployerCost(tt.in)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		for i, f := range c.Fields() {
+			if got := f.Value.StringFixed(2); got != tt.want[i] {
+				t.Errorf("%s: %s %s, want %s", tt.name, f.Name, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestEmployerCostAnnual(t *testing.T) {
+	c, err := ComputeEmployerCost(EmployerCostInputs{MonthlySalary: decimal.NewFromInt(30000), HMO: decimal.NewFromInt(1000)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	annual := c.Annual()
+	if !annual.Total.Equal(c.Total.Mul(decimal.NewFromInt(12))) || !annual.HMO.Equal(decimal.NewFromInt(12000)) {
+		t.Errorf("annual total %s and HMO %s from a monthly %s and %s", annual.Total, annual.HMO, c.Total, c.HMO)
+	}
+	if want := decimal.NewFromInt(2850 + 30 + 675 + 100); !c.Contributions().Equal(want) {
+		t.Errorf("contributions %s, want %s", c.Contributions(), want)
+	}
+}
+
+func TestComputeEmployerCostRefusesNegatives(t *testing.T) {
+	minus := decimal.NewFromInt(-1)
+	for _, in := range []EmployerCostInputs{
+		{MonthlySalary: minus},
+		{MonthlySalary: decimal.NewFromInt(30000), LeaveDays: minus},
+		{MonthlySalary: decimal.NewFromInt(30000), HMO: minus},
+	} {
+		if _, err := ComputeEmployerCost(in); err == nil {
+			t.Errorf("%+v accepted", in)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package payroll
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// SeparationCause is the reason an employee left, which decides the separation pay owed
+type SeparationCause int
+
+const (
+	Resignation SeparationCause = iota
+	JustCause
+	Redundancy
+	LaborSavingDevice
+	Retrenchment
+	Closure
+	ClosureDueToLosses
+	Disease
+)
+
+func (c SeparationCause) String() string {
+	switch c {
+	case Resignation:
+		return "Resignation"
+	case JustCause:
+		return "Termination for just cause"
+	case Redundancy:
+		return "Redundancy"
+	case LaborSavingDevice:
+		return "Installation of labor-saving devices"
+	case Retrenchment:
+		return "Retrenchment"
+	case Closure:
+		return "Closure of business"
+	case ClosureDueToLosses:
+		return "Closure due to serious losses"
+	case Disease:
+		return "Disease"
+	}
+	return "Unknown"
+}
+
+// Separation pay for authorized causes under Articles 298 and 299 of the Labor Code.
+// Redundancy and labor-saving devices pay one month per year of service, retrenchment,
+// closure and disease pay half a month per year, with at least one month's pay either way.
+// Resignations, dismissals for just cause and closures due to serious losses get nothing
+func (c SeparationCause) monthsPerYear() decimal.Decimal {
+	switch c {
+	case Redundancy, LaborSavingDevice:
+		return decimal.NewFromInt(1)
+	case Retrenchment, Closure, Disease:
+		return decimal.NewFromFloat(0.5)
+	}
+	return decimal.Zero
+}
+
+var (
+	// WorkDaysPerYear is the factor used to turn a monthly salary into a daily rate
+	WorkDaysPerYear = decimal.NewFromInt(261)
+	// BenefitsExemptCeiling is the yearly cap on tax-exempt 13th month pay and other benefits
+	BenefitsExemptCeiling = decimal.NewFromInt(90000)
+	// ExemptLeaveDays is how many monetized vacation leave days are de minimis
+	ExemptLeaveDays = decimal.NewFromInt(10)
+)
+
+// FinalPayInputs holds everything needed to settle a separated employee's final pay
+type FinalPayInputs struct {
+	MonthlySalary  decimal.Decimal
+	HireDate       time.Time
+	SeparationDate time.Time
+	Cause          SeparationCause
+
+	UnpaidDays      decimal.Decimal // days worked but not yet paid as of separation
+	UnusedLeaveDays decimal.Decimal // convertible leave credits left
+
+	BasicSalaryYTD      decimal.Decimal // basic salary already paid this year
+	ThirteenthMonthPaid decimal.Decimal // 13th month pay already released this year
+	OtherBenefitsYTD    decimal.Decimal // other benefits counted against the 90,000 ceiling
+
+	TaxableCompensationYTD decimal.Decimal // taxable compensation already paid this year
+	TaxWithheldYTD         decimal.Decimal
+}
+
+// FinalPayItem is a single line of the final pay statement
+type FinalPayItem struct {
+	Description string
+	Amount      decimal.Decimal
+	TaxExempt   bool
+}
+
+// FinalPayStatement is the itemised result of ComputeFinalPay
+type FinalPayStatement struct {
+	Items      []FinalPayItem
+	Deductions []FinalPayItem
+
+	GrossPay     decimal.Decimal
+	ExemptPay    decimal.Decimal
+	TaxablePay   decimal.Decimal
+	YearsService int
+
+	AnnualTaxableIncome decimal.Decimal
+	AnnualTaxDue        decimal.Decimal
+	TaxWithheld         decimal.Decimal
+	TaxRefund           decimal.Decimal // paid on top of the gross pay, negative when the employee still owes tax
+
+	NetFinalPay decimal.Decimal
+}
+
+// DailyRate converts a monthly salary into a daily rate
+func DailyRate(monthlySalary decimal.Decimal) decimal.Decimal {
+	return monthlySalary.Mul(decimal.NewFromInt(12)).Div(WorkDaysPerYear).Round(2)
+}
+
+// ComputeFinalPay produces the final pay statement for a separated employee
+func ComputeFinalPay(in FinalPayInputs) FinalPayStatement {
+	var st FinalPayStatement
+	daily := DailyRate(in.MonthlySalary)
+	st.YearsService = yearsOfService(in.HireDate, in.SeparationDate)
+
+	// Salary for days worked in the last cutoff
+	unpaid := daily.Mul(in.UnpaidDays).Round(2)
+	st.add("Unpaid salary", unpaid, false)
+
+	/* Contributions for the separation month are taken from the unpaid salary,
+	so they are only deducted when there is salary left to pay */
+	contributions := decimal.Zero
+	if unpaid.GreaterThan(decimal.Zero) {
+		sss := CalculateSSSContributions(in.MonthlySalary)
+		philhealth := CalculatePhilHealthContributions(in.MonthlySalary)
+		pagibig := CalculatePagIbigContributions(in.MonthlySalary)
+		st.Deductions = append(st.Deductions,
+			FinalPayItem{Description: "SSS contribution", Amount: sss},
+			FinalPayItem{Description: "PhilHealth contribution", Amount: philhealth},
+			FinalPayItem{Description: "Pag-IBIG contribution", Amount: pagibig})
+		contributions = decimal.Sum(sss, philhealth, pagibig)
+	}
+
+	/* 13th month pay is 1/12 of the basic salary earned in the year, less what was
+	already released. Together with other benefits it is exempt up to 90,000 */
+	thirteenth := in.BasicSalaryYTD.Add(unpaid).Div(decimal.NewFromInt(12)).Sub(in.ThirteenthMonthPaid)
+	thirteenth = decimal.Max(decimal.Zero, thirteenth).Round(2)
+	ceilingLeft := BenefitsExemptCeiling.Sub(in.ThirteenthMonthPaid).Sub(in.OtherBenefitsYTD)
+	ceilingLeft = decimal.Max(decimal.Zero, ceilingLeft)
+	st.split("Prorated 13th month pay", thirteenth, ceilingLeft)
+
+	// Unused leave converted to cash, the first 10 days are de minimis
+	leave := daily.Mul(in.UnusedLeaveDays).Round(2)
+	st.split("Monetized unused leave", leave, daily.Mul(decimal.Min(in.UnusedLeaveDays, ExemptLeaveDays)))
+
+	/* Separation pay for an authorized cause is beyond the employee's control,
+	so it is exempt from income tax */
+	if perYear := in.Cause.monthsPerYear(); perYear.GreaterThan(decimal.Zero) {
+		separation := in.MonthlySalary.Mul(perYear).Mul(decimal.NewFromInt(int64(st.YearsService)))
+		separation = decimal.Max(in.MonthlySalary, separation).Round(2)
+		st.add("Separation pay ("+in.Cause.String()+")", separation, true)
+	}
+
+	// Mandatory contributions are excluded from taxable compensation
+	st.TaxablePay = decimal.Max(decimal.Zero, st.GrossPay.Sub(st.ExemptPay).Sub(contributions))
+
+	// Annualize to settle the tax for the year
+	st.AnnualTaxableIncome = in.TaxableCompensationYTD.Add(st.TaxablePay)
+	st.AnnualTaxDue = CalculateAnnualTax(st.AnnualTaxableIncome)
+	st.TaxWithheld = in.TaxWithheldYTD
+	st.TaxRefund = st.TaxWithheld.Sub(st.AnnualTaxDue)
+	if st.TaxRefund.LessThan(decimal.Zero) {
+		st.Deductions = append(st.Deductions, FinalPayItem{Description: "Tax payable", Amount: st.TaxRefund.Neg()})
+	}
+
+	/* Over-withheld tax is returned with the final pay. It is not compensation,
+	so it stays out of the gross pay and only adds to what is paid out */
+	totalDeductions := decimal.Zero
+	for _, d := range st.Deductions {
+		totalDeductions = totalDeductions.Add(d.Amount)
+	}
+	st.NetFinalPay = st.GrossPay.Sub(totalDeductions).Add(decimal.Max(decimal.Zero, st.TaxRefund))
+
+	return st
+}
+
+func (st *FinalPayStatement) add(description string, amount decimal.Decimal, exempt bool) {
+	if amount.IsZero() {
+		return
+	}
+	st.Items = append(st.Items, FinalPayItem{Description: description, Amount: amount, TaxExempt: exempt})
+	st.GrossPay = st.GrossPay.Add(amount)
+	if exempt {
+		st.ExemptPay = st.ExemptPay.Add(amount)
+	}
+}
+
+// split books the part of amount up to exemptLimit as exempt and the rest as taxable
+func (st *FinalPayStatement) split(description string, amount, exemptLimit decimal.Decimal) {
+	exempt := decimal.Min(amount, exemptLimit).Round(2)
+	st.add(description, exempt, true)
+	st.add(description+" (taxable portion)", amount.Sub(exempt), false)
+}
+
+// Years of service as the Labor Code counts them,
+// where a fraction of at least six months is one whole year
+func yearsOfService(from, to time.Time) int {
+	if to.Before(from) {
+		return 0
+	}
+	months := (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+	if to.Day() < from.Day() {
+		months--
+	}
+	years := months / 12
+	if months%12 >= 6 {
+		years++
+	}
+	return years
+}
@@ -0,0 +1,95 @@
+package payroll
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// separated is an employee paid 26,100 a month, a daily rate of 1,200, who
+// leaves on 30 June 2023 after five years and four months
+func separated(cause SeparationCause) FinalPayInputs {
+	return FinalPayInputs{
+		MonthlySalary:   decimal.NewFromInt(26100),
+		HireDate:        time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC),
+		SeparationDate:  time.Date(2023, 6, 30, 0, 0, 0, 0, time.UTC),
+		Cause:           cause,
+		UnusedLeaveDays: decimal.NewFromInt(5),
+		BasicSalaryYTD:  decimal.NewFromInt(156600),
+	}
+}
+
+// separationPay finds the separation pay line of a statement, zero when there is none
+func separationPay(st FinalPayStatement) decimal.Decimal {
+	for _, item := range st.Items {
+		if strings.HasPrefix(item.Description, "Separation pay") {
+			return item.Amount
+		}
+	}
+	return decimal.Zero
+}
+
+func TestFinalPayTaxSettlement(t *testing.T) {
+	// 13,050 of 13th month pay, 6,000 of leave and 5 years of 26,100 separation pay
+	const gross = "149550.00"
+
+	t.Run("nothing withheld or owed", func(t *testing.T) {
+		in := separated(Redundancy)
+		in.TaxableCompensationYTD = decimal.NewFromInt(120000)
+		st := ComputeFinalPay(in)
+		if st.GrossPay.StringFixed(2) != gross || !st.NetFinalPay.Equal(st.GrossPay) || len(st.Deductions) != 0 {
+			t.Errorf("gross %s net %s with %d deductions", st.GrossPay, st.NetFinalPay, len(st.Deductions))
+		}
+	})
+
+	t.Run("refund paid on top of the gross", func(t *testing.T) {
+		in := separated(Redundancy)
+		in.TaxableCompensationYTD, in.TaxWithheldYTD = decimal.NewFromInt(120000), decimal.NewFromInt(10000)
+		st := ComputeFinalPay(in)
+		if st.GrossPay.StringFixed(2) != gross {
+			t.Errorf("the refund changed the gross pay to %s", st.GrossPay)
+		}
+		if st.TaxRefund.StringFixed(2) != "10000.00" || st.NetFinalPay.StringFixed(2) != "159550.00" {
+			t.Errorf("refund %s net %s, want 10000.00 and 159550.00", st.TaxRefund, st.NetFinalPay)
+		}
+	})
+
+	t.Run("tax still owed", func(t *testing.T) {
+		in := separated(Redundancy)
+		in.TaxableCompensationYTD = decimal.NewFromInt(600000)
+		st := ComputeFinalPay(in)
+		if len(st.Deductions) != 1 || st.Deductions[0].Amount.StringFixed(2) != "62500.00" {
+			t.Fatalf("deductions %+v, want 62,500 of tax payable", st.Deductions)
+		}
+		if st.NetFinalPay.StringFixed(2) != "87050.00" {
+			t.Errorf("net %s, want 87050.00", st.NetFinalPay)
+		}
+	})
+}
+
+func TestSeparationPayByCause(t *testing.T) {
+	pay := map[SeparationCause]int64{
+		Resignation:        0,
+		JustCause:          0,
+		ClosureDueToLosses: 0,
+		Redundancy:         130500, // a month per year
+		LaborSavingDevice:  130500,
+		Retrenchment:       65250, // half a month per year
+		Closure:            65250,
+		Disease:            65250,
+	}
+	for cause, want := range pay {
+		if got := separationPay(ComputeFinalPay(separated(cause))); !got.Equal(decimal.NewFromInt(want)) {
+			t.Errorf("%s: separation pay %s, want %d", cause, got, want)
+		}
+	}
+
+	// Half a month for four months of service still pays a whole month
+	in := separated(Retrenchment)
+	in.HireDate = in.SeparationDate.AddDate(0, -4, 0)
+	if got := separationPay(ComputeFinalPay(in)); !got.Equal(in.MonthlySalary) {
+		t.Errorf("short service: separation pay %s, want a month's 26100", got)
+	}
+}
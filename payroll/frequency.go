@@ -0,0 +1,81 @@
+package payroll
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// PayFrequency is how often an employee is paid
+type PayFrequency string
+
+const (
+	Monthly     PayFrequency = "monthly"
+	SemiMonthly PayFrequency = "semi-monthly"
+	Weekly      PayFrequency = "weekly"
+	Daily       PayFrequency = "daily"
+)
+
+// PayFrequencies lists the supported frequencies in display order
+var PayFrequencies = []PayFrequency{Monthly, SemiMonthly, Weekly, Daily}
+
+// PeriodsPerYear returns the number of paydays in a year
+func (f PayFrequency) PeriodsPerYear() decimal.Decimal {
+	switch f {
+	case SemiMonthly:
+		return decimal.NewFromInt(24)
+	case Weekly:
+		return decimal.NewFromInt(52)
+	case Daily:
+		return WorkDaysPerYear
+	}
+	return decimal.NewFromInt(12)
+}
+
+// ToMonthly converts an income received every pay period into its monthly equivalent
+func (f PayFrequency) ToMonthly(income decimal.Decimal) decimal.Decimal {
+	if f == Monthly || f == "" {
+		return income
+	}
+	return income.Mul(f.PeriodsPerYear()).Div(decimal.NewFromInt(12))
+}
+
+// ParsePayFrequency converts a name such as "semi-monthly" into a PayFrequency
+func ParsePayFrequency(s string) (PayFrequency, error) {
+	for _, f := range PayFrequencies {
+		if string(f) == s {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown pay frequency %q", s)
+}
+
+// PerPeriod splits the monthly figures evenly across the paydays of a month
+func (t TaxInputs) PerPeriod(f PayFrequency) TaxInputs {
+	if f == Monthly || f == "" {
+		return t
+	}
+	perMonth := f.PeriodsPerYear().Div(decimal.NewFromInt(12))
+	split := func(d decimal.Decimal) decimal.Decimal { return d.Div(perMonth).Round(2) }
+
+	result := TaxInputs{
+		MonthlyIncome:           split(t.MonthlyIncome),
+		TaxableIncome:           split(t.TaxableIncome),
+		Tax:                     split(t.Tax),
+		NetPayAfterTax:          split(t.NetPayAfterTax),
+		SSSContributions:        split(t.SSSContributions),
+		PhilHealthContributions: split(t.PhilHealthContributions),
+		PagIbigContributions:    split(t.PagIbigContributions),
+		TotalContributions:      split(t.TotalContributions),
+		TotalDeductions:         split(t.TotalDeductions),
+		NetPayAfterDeductions:   split(t.NetPayAfterDeductions),
+	}
+	for _, d := range t.OtherDeductions {
+		d.Amount = split(d.Amount)
+		result.OtherDeductions = append(result.OtherDeductions, d)
+	}
+	if t.Earnings != nil {
+		result.Earnings = t.Earnings.perPeriod(split)
+	}
+	return result
+}
@@ -0,0 +1,392 @@
+package payroll
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// FringeBenefitType is a kind of fringe benefit given to a managerial or
+// supervisory employee, which decides how the benefit is valued
+type FringeBenefitType string
+
+const (
+	CarPurchase        FringeBenefitType = "car-purchase"
+	CarAmortization    FringeBenefitType = "car-amortization"
+	CarInstallment     FringeBenefitType = "car-installment"
+	CompanyCar         FringeBenefitType = "company-car"
+	LeasedCar          FringeBenefitType = "leased-car"
+	LeasedHousing      FringeBenefitType = "leased-housing"
+	CompanyHousing     FringeBenefitType = "company-housing"
+	HousingInstallment FringeBenefitType = "housing-installment"
+	HousingTransfer    FringeBenefitType = "housing-transfer"
+	ClubMembership     FringeBenefitType = "club"
+	OtherFringeBenefit FringeBenefitType = "other"
+)
+
+// FringeBenefitTypes lists the fringe benefit types in display order
+var FringeBenefitTypes = []FringeBenefitType{
+	CarPurchase, CarAmortization, CarInstallment, CompanyCar, LeasedCar,
+	LeasedHousing, CompanyHousing, HousingInstallment, HousingTransfer,
+	ClubMembership, OtherFringeBenefit,
+}
+
+// String names the type on screens
+func (t FringeBenefitType) String() string {
+	switch t {
+	case CarPurchase:
+		return "Car bought for the employee"
+	case CarAmortization:
+		return "Car amortization shouldered"
+	case CarInstallment:
+		return "Car bought on installment"
+	case CompanyCar:
+		return "Company car for personal use"
+	case LeasedCar:
+		return "Leased car"
+	case LeasedHousing:
+		return "Leased housing"
+	case CompanyHousing:
+		return "Company-owned housing"
+	case HousingInstallment:
+		return "Housing bought on installment"
+	case HousingTransfer:
+		return "Housing transferred to the employee"
+	case ClubMembership:
+		return "Club membership"
+	case OtherFringeBenefit:
+		return "Other fringe benefit"
+	}
+	return string(t)
+}
+
+// ParseFringeBenefitType converts a name such as "company-car" into a FringeBenefitType
+func ParseFringeBenefitType(s string) (FringeBenefitType, error) {
+	for _, t := range FringeBenefitTypes {
+		if string(t) == s || t.String() == s {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("unknown fringe benefit type %q", s)
+}
+
+var (
+	// VehicleLifeYears is the years a car's cost is spread over when it is valued by the year
+	VehicleLifeYears = decimal.NewFromInt(5)
+	// HousingValueRate is the share of a property's value taken as its yearly rent
+	HousingValueRate = decimal.NewFromFloat(0.05)
+	// PersonalUseShare is the part of a car or home used by the employee and the
+	// business both that is taken as the employee's benefit
+	PersonalUseShare = decimal.NewFromFloat(0.5)
+)
+
+// fringePeriod is what the Amount of a benefit is spread over
+type fringePeriod int
+
+const (
+	givenOnce fringePeriod = iota // valued in full in the month given
+	byMonth                       // Amount is paid every month, e.g. a rent
+	byYear                        // Amount is a cost valued a share a year
+)
+
+// valuation is how a type is valued under RR 3-98: the share of the Amount
+// that is the monetary value, what that share is taken over, and the rule
+func (t FringeBenefitType) valuation() (share decimal.Decimal, period fringePeriod, rule string) {
+	switch t {
+	case CarInstallment:
+		return one.Div(VehicleLifeYears), byYear, "Cost over 5 years"
+	case CompanyCar:
+		return PersonalUseShare.Div(VehicleLifeYears), byYear, "50% of cost over 5 years"
+	case LeasedCar, LeasedHousing:
+		return PersonalUseShare, byMonth, "50% of rent"
+	case CompanyHousing:
+		return HousingValueRate.Mul(PersonalUseShare), byYear, "50% of 5% of market value a year"
+	case HousingInstallment:
+		return HousingValueRate.Mul(PersonalUseShare), byYear, "50% of 5% of cost a year"
+	case HousingTransfer:
+		return one, givenOnce, "Market value"
+	case CarPurchase:
+		return one, givenOnce, "Cost of the car"
+	case CarAmortization:
+		return one, givenOnce, "Amortization shouldered"
+	case ClubMembership:
+		return one, givenOnce, "Membership fees"
+	}
+	return one, givenOnce, "Amount given"
+}
+
+// Recurring reports whether the benefit is valued for every month it is used
+// rather than once when given
+func (t FringeBenefitType) Recurring() bool {
+	_, period, _ := t.valuation()
+	return period != givenOnce
+}
+
+// FringeBenefit is a benefit given to a managerial or supervisory employee
+type FringeBenefit struct {
+	Type        FringeBenefitType
+	Description string
+	Month       time.Time       // month given, or the first month used for the recurring types
+	Months      int             // months used, for the recurring types, 1 when zero
+	Amount      decimal.Decimal // cost, monthly rent, fair market value or fees, as the type says
+	// Paid by the employee for the benefit, each month for the recurring types
+	EmployeeShare decimal.Decimal
+}
+
+// ParseFringeBenefits reads benefits, one
+// "YYYY-MM,type,amount[,months[,employee share[,description]]]" per line.
+// Blank lines, lines starting with # and a heading line are skipped
+func ParseFringeBenefits(in io.Reader) ([]FringeBenefit, error) {
+	var benefits []FringeBenefit
+	first := true
+	scanner := bufio.NewScanner(in)
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		heading := first
+		first = false
+
+		fields, err := csv.NewReader(strings.NewReader(text)).Read()
+		if err != nil || len(fields) < 3 || len(fields) > 6 {
+			return nil, fmt.Errorf("line %d: use YYYY-MM,type,amount[,months[,employee share[,description]]]", n)
+		}
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		month, err := time.Parse("2006-01", fields[0])
+		if err != nil {
+			if heading {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: %q is not a month such as 2023-01", n, fields[0])
+		}
+		b := FringeBenefit{Month: month}
+		if b.Type, err = ParseFringeBenefitType(fields[1]); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		if b.Amount, err = decimal.NewFromString(strings.ReplaceAll(fields[2], ",", "")); err != nil {
+			return nil, fmt.Errorf("line %d: %q is not a valid amount", n, fields[2])
+		}
+		if len(fields) > 3 && fields[3] != "" {
+			if b.Months, err = strconv.Atoi(fields[3]); err != nil {
+				return nil, fmt.Errorf("line %d: %q is not a number of months", n, fields[3])
+			}
+		}
+		if len(fields) > 4 && fields[4] != "" {
+			if b.EmployeeShare, err = decimal.NewFromString(strings.ReplaceAll(fields[4], ",", "")); err != nil {
+				return nil, fmt.Errorf("line %d: %q is not a valid amount", n, fields[4])
+			}
+		}
+		if len(fields) > 5 {
+			b.Description = fields[5]
+		}
+		benefits = append(benefits, b)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return benefits, nil
+}
+
+// validate reports every problem with the benefit
+func (b FringeBenefit) validate() error {
+	var errs []error
+	if _, err := ParseFringeBenefitType(string(b.Type)); err != nil {
+		errs = append(errs, err)
+	}
+	if b.Month.IsZero() {
+		errs = append(errs, errors.New("month is missing"))
+	}
+	if !b.Amount.IsPositive() {
+		errs = append(errs, errors.New("amount must be more than zero"))
+	}
+	if b.EmployeeShare.IsNegative() {
+		errs = append(errs, errors.New("employee's share cannot be negative"))
+	}
+	switch {
+	case b.Months < 0 || b.Months > 120:
+		errs = append(errs, errors.New("months must be from 1 to 120"))
+	case b.Months > 1 && !b.Type.Recurring():
+		errs = append(errs, fmt.Errorf("%s is valued once when given, not over months", b.Type))
+	}
+	return errors.Join(errs...)
+}
+
+// FringeMonth is the fringe benefits of one month
+type FringeMonth struct {
+	Month     time.Time
+	Value     decimal.Decimal // monetary value to the employee
+	GrossedUp decimal.Decimal // value divided by 1 minus the rate
+	Tax       decimal.Decimal
+}
+
+// FringeValuation is how one benefit was valued
+type FringeValuation struct {
+	Benefit FringeBenefit
+	Rule    string
+	Share   decimal.Decimal // part of the Amount taken as the value
+	Months  []FringeMonth   // each month the benefit counts in
+	Value   decimal.Decimal
+	// The benefit's share of the tax, the quarters tax each month's total so
+	// theirs may differ by rounding
+	GrossedUp decimal.Decimal
+	Tax       decimal.Decimal
+}
+
+// FringeQuarter is a quarter's fringe benefits tax, as reported on BIR Form 1603Q
+type FringeQuarter struct {
+	Year      int
+	Quarter   int
+	Deadline  time.Time     // last day of the month after the quarter
+	Months    []FringeMonth // the quarter's three months
+	Value     decimal.Decimal
+	GrossedUp decimal.Decimal
+	Tax       decimal.Decimal
+}
+
+// Name identifies the quarter, e.g. "Q1 2023"
+func (q FringeQuarter) Name() string {
+	return fmt.Sprintf("Q%d %d", q.Quarter, q.Year)
+}
+
+// FringeStatement is the result of ComputeFringeBenefits
+type FringeStatement struct {
+	Rate       decimal.Decimal
+	Divisor    decimal.Decimal // 1 minus the rate, the value is divided by it
+	Valuations []FringeValuation
+	Quarters   []FringeQuarter
+	Value      decimal.Decimal
+	GrossedUp  decimal.Decimal
+	Tax        decimal.Decimal
+}
+
+// ComputeFringeBenefits values fringe benefits and works out the fringe
+// benefits tax by quarter under the current rules
+func ComputeFringeBenefits(benefits []FringeBenefit) (FringeStatement, error) {
+	return Current().ComputeFringeBenefits(benefits)
+}
+
+// ComputeFringeBenefits values fringe benefits under RR 3-98 and works out the
+// fringe benefits tax the employer pays on them, apart from the withholding
+// tax on the compensation. The tax is the rate applied to the value grossed up
+// by dividing it by 1 minus the rate, 65% when the rate is 35%
+func (r *Rules) ComputeFringeBenefits(benefits []FringeBenefit) (FringeStatement, error) {
+	var errs []error
+	for i, b := range benefits {
+		if err := b.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("benefit %d: %w", i+1, err))
+		}
+	}
+	if len(benefits) == 0 {
+		errs = append(errs, errors.New("no fringe benefits given"))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return FringeStatement{}, err
+	}
+
+	st := FringeStatement{Rate: r.FringeBenefitRate, Divisor: one.Sub(r.FringeBenefitRate)}
+	byMonthOf := map[time.Time]decimal.Decimal{}
+	for _, b := range benefits {
+		v := valueFringeBenefit(b)
+		for i, m := range v.Months {
+			byMonthOf[m.Month] = byMonthOf[m.Month].Add(m.Value)
+			v.Months[i] = st.tax(m.Month, m.Value)
+			v.GrossedUp = v.GrossedUp.Add(v.Months[i].GrossedUp)
+			v.Tax = v.Tax.Add(v.Months[i].Tax)
+		}
+		st.Valuations = append(st.Valuations, v)
+	}
+
+	var months []time.Time
+	for m := range byMonthOf {
+		months = append(months, m)
+	}
+	sort.Slice(months, func(i, j int) bool { return months[i].Before(months[j]) })
+	for _, m := range months {
+		fm := st.tax(m, byMonthOf[m])
+		year, quarter := m.Year(), (int(m.Month())-1)/3+1
+		if n := len(st.Quarters); n == 0 || st.Quarters[n-1].Year != year || st.Quarters[n-1].Quarter != quarter {
+			st.Quarters = append(st.Quarters, FringeQuarter{
+				Year:     year,
+				Quarter:  quarter,
+				Deadline: time.Date(year, time.Month(quarter*3+2), 0, 0, 0, 0, 0, time.UTC),
+			})
+		}
+		q := &st.Quarters[len(st.Quarters)-1]
+		q.Months = append(q.Months, fm)
+		q.Value = q.Value.Add(fm.Value)
+		q.GrossedUp = q.GrossedUp.Add(fm.GrossedUp)
+		q.Tax = q.Tax.Add(fm.Tax)
+	}
+	for _, q := range st.Quarters {
+		st.Value = st.Value.Add(q.Value)
+		st.GrossedUp = st.GrossedUp.Add(q.GrossedUp)
+		st.Tax = st.Tax.Add(q.Tax)
+	}
+	return st, nil
+}
+
+// valueFringeBenefit spreads a benefit's monetary value over the months it counts in
+func valueFringeBenefit(b FringeBenefit) FringeValuation {
+	share, period, rule := b.Type.valuation()
+	v := FringeValuation{Benefit: b, Rule: rule, Share: share}
+
+	value := b.Amount.Mul(share)
+	if period == byYear {
+		value = value.Div(decimal.NewFromInt(12))
+	}
+	value = decimal.Max(decimal.Zero, value.Round(2).Sub(b.EmployeeShare))
+
+	months := b.Months
+	if months == 0 {
+		months = 1
+	}
+	first := monthOf(b.Month)
+	for i := 0; i < months; i++ {
+		v.Months = append(v.Months, FringeMonth{Month: first.AddDate(0, i, 0), Value: value})
+		v.Value = v.Value.Add(value)
+	}
+	return v
+}
+
+// tax grosses up a month's value and applies the rate to it
+func (st FringeStatement) tax(month time.Time, value decimal.Decimal) FringeMonth {
+	grossedUp := value.Div(st.Divisor).Round(2)
+	return FringeMonth{
+		Month:     month,
+		Value:     value,
+		GrossedUp: grossedUp,
+		Tax:       grossedUp.Mul(st.Rate).Round(2),
+	}
+}
+
+// Trace lists how each benefit was valued and the tax on the total
+func (st FringeStatement) Trace() Trace {
+	t := Trace{Name: "Fringe Benefits Tax"}
+	for _, v := range st.Valuations {
+		name := v.Benefit.Type.String()
+		if v.Benefit.Description != "" {
+			name = v.Benefit.Description
+		}
+		rule := fmt.Sprintf("%s, %s", name, strings.ToLower(v.Rule[:1])+v.Rule[1:])
+		if len(v.Months) > 1 {
+			rule += fmt.Sprintf(", %d months", len(v.Months))
+		}
+		t.add(Step{Rule: rule, Amount: v.Value, Rate: v.Share})
+	}
+	t.add(Step{Rule: "Monetary value", Amount: st.Value})
+	t.add(Step{Rule: "Grossed-up value, divided by 1 minus the rate", Amount: st.GrossedUp, Rate: st.Divisor})
+	t.add(Step{Rule: "Fringe benefits tax", Amount: st.Tax, Rate: st.Rate})
+	t.Result = st.Tax
+	return t
+}
@@ -0,0 +1,117 @@
+package payroll
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func month(year int, m time.Month) time.Time { return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC) }
+
+func TestFringeValuation(t *testing.T) {
+	// Value is the benefit's value in its month, tax 35% of it grossed up by dividing by 65%
+	tests := []struct {
+		typ        FringeBenefitType
+		amount     int64
+		value, tax string
+	}{
+		{CarPurchase, 1200000, "1200000.00", "646153.85"},
+		{CarAmortization, 25000, "25000.00", "13461.54"},
+		{CarInstallment, 1200000, "20000.00", "10769.23"}, // cost over 5 years, a twelfth a month
+		{CompanyCar, 1200000, "10000.00", "5384.62"},      // half of that for personal use
+		{LeasedCar, 60000, "30000.00", "16153.85"},        // half the monthly rent
+		{LeasedHousing, 60000, "30000.00", "16153.85"},
+		{CompanyHousing, 12000000, "25000.00", "13461.54"}, // half of 5% of the value a year
+		{HousingInstallment, 12000000, "25000.00", "13461.54"},
+		{HousingTransfer, 3000000, "3000000.00", "1615384.62"},
+		{ClubMembership, 250000, "250000.00", "134615.38"},
+		{OtherFringeBenefit, 10000, "10000.00", "5384.62"},
+	}
+	if len(tests) != len(FringeBenefitTypes) {
+		t.Fatalf("%d cases for %d benefit types", len(tests), len(FringeBenefitTypes))
+	}
+	for _, tt := range tests {
+		st, err := ComputeFringeBenefits([]FringeBenefit{{Type: tt.typ, Month: month(2023, time.May), Amount: decimal.NewFromInt(tt.amount)}})
+		if err != nil {
+			t.Errorf("%s: %v", tt.typ, err)
+			continue
+		}
+		if st.Value.StringFixed(2) != tt.value || st.Tax.StringFixed(2) != tt.tax {
+			t.Errorf("%s: value %s tax %s, want %s and %s", tt.typ, st.Value.StringFixed(2), st.Tax.StringFixed(2), tt.value, tt.tax)
+		}
+	}
+}
+
+func TestFringeEmployeeShare(t *testing.T) {
+	// The employee pays 4,000 of the 10,000 a month valued for a company car
+	st, err := ComputeFringeBenefits([]FringeBenefit{{Type: CompanyCar, Month: month(2023, time.May),
+		Months: 2, Amount: decimal.NewFromInt(1200000), EmployeeShare: decimal.NewFromInt(4000)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := st.Value.StringFixed(2); got != "12000.00" {
+		t.Errorf("value over two months %s, want 12000.00", got)
+	}
+}
+
+func TestFringeQuarters(t *testing.T) {
+	benefits := []FringeBenefit{
+		// February to April, across the end of the first quarter
+		{Type: LeasedHousing, Month: month(2023, time.February), Months: 3, Amount: decimal.NewFromInt(60000)},
+		{Type: ClubMembership, Month: month(2023, time.March), Amount: decimal.NewFromInt(65000)},
+		// The fourth quarter is due in January of the next year
+		{Type: CarAmortization, Month: month(2023, time.December), Amount: decimal.NewFromInt(6500)},
+	}
+	st, err := ComputeFringeBenefits(benefits)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		name     string
+		deadline time.Time
+		months   int
+		value    string
+	}{
+		{"Q1 2023", time.Date(2023, time.April, 30, 0, 0, 0, 0, time.UTC), 2, "125000.00"},
+		{"Q2 2023", time.Date(2023, time.July, 31, 0, 0, 0, 0, time.UTC), 1, "30000.00"},
+		{"Q4 2023", time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC), 1, "6500.00"},
+	}
+	if len(st.Quarters) != len(want) {
+		t.Fatalf("%d quarters, want %d", len(st.Quarters), len(want))
+	}
+	for i, w := range want {
+		q := st.Quarters[i]
+		if q.Name() != w.name || !q.Deadline.Equal(w.deadline) || len(q.Months) != w.months || q.Value.StringFixed(2) != w.value {
+			t.Errorf("quarter %d: %s due %s, %d months worth %s, want %s due %s, %d months worth %s",
+				i+1, q.Name(), q.Deadline.Format("2006-01-02"), len(q.Months), q.Value.StringFixed(2),
+				w.name, w.deadline.Format("2006-01-02"), w.months, w.value)
+		}
+	}
+
+	// March's two benefits are taxed together: 95,000 grossed up to 146,153.85
+	march := st.Quarters[0].Months[1]
+	if march.GrossedUp.StringFixed(2) != "146153.85" || march.Tax.StringFixed(2) != "51153.85" {
+		t.Errorf("March grossed up %s taxed %s", march.GrossedUp, march.Tax)
+	}
+	if q4 := st.Quarters[2]; q4.Tax.StringFixed(2) != "3500.00" {
+		t.Errorf("Q4 tax %s, want 3500.00", q4.Tax)
+	}
+}
+
+func TestFringeBenefitsRefused(t *testing.T) {
+	may := month(2023, time.May)
+	for name, b := range map[string]FringeBenefit{
+		"no amount":           {Type: CarPurchase, Month: may},
+		"no month":            {Type: CarPurchase, Amount: decimal.NewFromInt(1)},
+		"one-off over months": {Type: ClubMembership, Month: may, Months: 3, Amount: decimal.NewFromInt(1)},
+		"unknown type":        {Type: "yacht", Month: may, Amount: decimal.NewFromInt(1)},
+		"negative share":      {Type: LeasedCar, Month: may, Amount: decimal.NewFromInt(1), EmployeeShare: decimal.NewFromInt(-1)},
+		"more than ten years": {Type: LeasedCar, Month: may, Months: 121, Amount: decimal.NewFromInt(1)},
+	} {
+		if _, err := ComputeFringeBenefits([]FringeBenefit{b}); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package payroll
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrBelowMinimumWage is returned for a kasambahay paid less than the minimum wage of the region
+var ErrBelowMinimumWage = errors.New("below the kasambahay minimum wage")
+
+// KasambahayRules are the Batas Kasambahay rules for domestic workers
+type KasambahayRules struct {
+	// Below this monthly wage the employer pays the worker's contributions too
+	EmployerPaysBelow decimal.Decimal `toml:"employer_pays_below" yaml:"employer_pays_below"`
+	MinimumWage       []RegionalWage  `toml:"minimum_wage" yaml:"minimum_wage"`
+}
+
+// RegionalWage is the minimum monthly wage of domestic workers in a region
+type RegionalWage struct {
+	Region string          `toml:"region" yaml:"region"`
+	Wage   decimal.Decimal `toml:"wage" yaml:"wage"`
+}
+
+// Regions lists the regions with a kasambahay minimum wage, in the order of the rules file
+func (k KasambahayRules) Regions() []string {
+	regions := make([]string, len(k.MinimumWage))
+	for i, w := range k.MinimumWage {
+		regions[i] = w.Region
+	}
+	return regions
+}
+
+// MinimumWageIn returns the kasambahay minimum monthly wage of a region, ignoring case
+func (k KasambahayRules) MinimumWageIn(region string) (decimal.Decimal, error) {
+	for _, w := range k.MinimumWage {
+		if strings.EqualFold(w.Region, region) {
+			return w.Wage, nil
+		}
+	}
+	return decimal.Zero, fmt.Errorf("no kasambahay minimum wage for region %q, use one of %s",
+		region, strings.Join(k.Regions(), ", "))
+}
+
+// CheckKasambahayWage reports a monthly wage below the region's kasambahay minimum wage
+func (r *Rules) CheckKasambahayWage(region string, monthlyWage decimal.Decimal) error {
+	minimum, err := r.Kasambahay.MinimumWageIn(region)
+	if err != nil {
+		return err
+	}
+	if monthlyWage.LessThan(minimum) {
+		return fmt.Errorf("monthly wage of %s is %w of %s in %s",
+			monthlyWage.StringFixed(2), ErrBelowMinimumWage, minimum.StringFixed(2), region)
+	}
+	return nil
+}
+
+// employerPaysAll reports whether the employer pays the domestic worker's
+// contributions as well as its own
+func (r *Rules) employerPaysAll(monthlyWage decimal.Decimal) bool {
+	return monthlyWage.LessThan(r.Kasambahay.EmployerPaysBelow)
+}
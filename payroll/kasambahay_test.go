@@ -0,0 +1,72 @@
+package payroll
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestComputeKasambahay(t *testing.T) {
+	kasambahay := Options{EmployeeType: Kasambahay}
+	t.Run("employer pays all below 5,000", func(t *testing.T) {
+		r, err := ComputeWith(decimal.NewFromInt(4500), kasambahay)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := figures(r); got != "0.00 0.00 4500.00" {
+			t.Errorf("got %s, want nothing deducted", got)
+		}
+	})
+	t.Run("own share from 5,000", func(t *testing.T) {
+		r, err := ComputeWith(decimal.NewFromInt(6000), kasambahay)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := figures(r); got != "595.00 0.00 5405.00" {
+			t.Errorf("got %s, want contributions deducted and no tax", got)
+		}
+	})
+	t.Run("below the regional minimum", func(t *testing.T) {
+		_, err := ComputeWith(decimal.NewFromInt(4500), Options{EmployeeType: Kasambahay, Region: "NCR"})
+		if !errors.Is(err, ErrBelowMinimumWage) {
+			t.Errorf("error %v, want %v", err, ErrBelowMinimumWage)
+		}
+	})
+	t.Run("region outside the rules", func(t *testing.T) {
+		_, err := ComputeWith(decimal.NewFromInt(8000), Options{EmployeeType: Kasambahay, Region: "XX"})
+		if err == nil || errors.Is(err, ErrBelowMinimumWage) {
+			t.Errorf("error %v, want an unknown region", err)
+		}
+	})
+}
+
+func TestCheckKasambahayWage(t *testing.T) {
+	rules := Current()
+	if err := rules.CheckKasambahayWage("ncr", decimal.NewFromInt(6000)); err != nil {
+		t.Errorf("the NCR minimum itself: %v", err)
+	}
+	if err := rules.CheckKasambahayWage("NCR", decimal.RequireFromString("5999.99")); !errors.Is(err, ErrBelowMinimumWage) {
+		t.Errorf("a cent below the NCR minimum: %v", err)
+	}
+	if err := rules.CheckKasambahayWage("CAR", decimal.NewFromInt(4500)); err != nil {
+		t.Errorf("the CAR minimum: %v", err)
+	}
+}
+
+// TestReverseKasambahay checks the search passes through wages below the
+// regional minimum on its way to an answer above it
+func TestReverseKasambahay(t *testing.T) {
+	opts := Options{EmployeeType: Kasambahay, Region: "NCR"}
+	r, err := Reverse(decimal.NewFromInt(6500), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.NetPayAfterDeductions.LessThan(decimal.NewFromInt(6500)) || r.MonthlyIncome.LessThan(decimal.NewFromInt(6000)) {
+		t.Errorf("gross %s leaves %s", r.MonthlyIncome, r.NetPayAfterDeductions)
+	}
+
+	if _, err := Reverse(decimal.NewFromInt(4000), opts); !errors.Is(err, ErrBelowMinimumWage) {
+		t.Errorf("a net pay only a wage below the minimum leaves: %v", err)
+	}
+}
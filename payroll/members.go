@@ -0,0 +1,225 @@
+package payroll
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// SSSMemberType is how a member is covered by SSS. Employed members split the
+// contribution with their employer, the others pay all of it themselves on the
+// earnings they declare, from a higher or lower salary credit floor
+type SSSMemberType string
+
+const (
+	Employed     SSSMemberType = "employed"
+	SelfEmployed SSSMemberType = "self-employed"
+	Voluntary    SSSMemberType = "voluntary"
+	OFW          SSSMemberType = "ofw" // overseas Filipino worker
+)
+
+// SSSMemberTypes lists the member types in display order
+var SSSMemberTypes = []SSSMemberType{Employed, SelfEmployed, Voluntary, OFW}
+
+// String names the member type on screens
+func (m SSSMemberType) String() string {
+	switch m {
+	case Employed:
+		return "Employed"
+	case SelfEmployed:
+		return "Self-Employed"
+	case Voluntary:
+		return "Voluntary"
+	case OFW:
+		return "OFW"
+	}
+	return string(m)
+}
+
+// ParseSSSMemberType converts a name such as "self-employed" into an SSSMemberType
+func ParseSSSMemberType(s string) (SSSMemberType, error) {
+	for _, m := range SSSMemberTypes {
+		if string(m) == s || m.String() == s {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("unknown SSS member type %q", s)
+}
+
+// PaysFullRate reports whether the member pays both the employee and employer shares
+func (m SSSMemberType) PaysFullRate() bool { return m != Employed }
+
+// SSSMemberCredit is the lowest salary credit of members who pay their own contribution
+type SSSMemberCredit struct {
+	Type      SSSMemberType   `toml:"type" yaml:"type"`
+	MinCredit decimal.Decimal `toml:"min_credit" yaml:"min_credit"`
+}
+
+// MinCreditFor returns the lowest salary credit of a member type
+func (s SSSSchedule) MinCreditFor(m SSSMemberType) decimal.Decimal {
+	for _, c := range s.Members {
+		if c.Type == m {
+			return c.MinCredit
+		}
+	}
+	return s.MinCredit
+}
+
+// MemberRate is the share of the salary credit a member of the type pays
+func (s SSSSchedule) MemberRate(m SSSMemberType) decimal.Decimal {
+	if m.PaysFullRate() {
+		return s.EmployeeRate.Add(s.EmployerRate)
+	}
+	return s.EmployeeRate
+}
+
+// SSSMemberContribution is the monthly SSS contribution of a member on declared earnings
+type SSSMemberContribution struct {
+	MemberType   SSSMemberType
+	Earnings     decimal.Decimal // declared monthly earnings, or salary for employed members
+	Range        SSSRange
+	Rate         decimal.Decimal
+	Contribution decimal.Decimal
+}
+
+// ComputeSSSMember computes a member's monthly SSS contribution under the current rules
+func ComputeSSSMember(m SSSMemberType, monthlyEarnings decimal.Decimal) (SSSMemberContribution, error) {
+	return Current().ComputeSSSMember(m, monthlyEarnings)
+}
+
+// ComputeSSSMember maps the declared monthly earnings to a salary credit from
+// the member type's floor and charges the member's rate on it
+func (r *Rules) ComputeSSSMember(m SSSMemberType, monthlyEarnings decimal.Decimal) (SSSMemberContribution, error) {
+	if _, err := ParseSSSMemberType(string(m)); err != nil {
+		return SSSMemberContribution{}, err
+	}
+	if monthlyEarnings.IsNegative() {
+		return SSSMemberContribution{}, errors.New("earnings cannot be negative")
+	}
+	c := SSSMemberContribution{
+		MemberType: m,
+		Earnings:   monthlyEarnings,
+		Range:      r.sssRange(monthlyEarnings, r.SSS.MinCreditFor(m)),
+		Rate:       r.SSS.MemberRate(m),
+	}
+	c.Contribution = c.Range.SalaryCredit.Mul(c.Rate)
+	return c, nil
+}
+
+// ExplainSSSMember computes a member's SSS contribution and records the salary credit used
+func ExplainSSSMember(m SSSMemberType, monthlyEarnings decimal.Decimal) (Trace, error) {
+	return Current().ExplainSSSMember(m, monthlyEarnings)
+}
+
+// ExplainSSSMember traces a member's SSS contribution under these rules
+func (r *Rules) ExplainSSSMember(m SSSMemberType, monthlyEarnings decimal.Decimal) (Trace, error) {
+	if !m.PaysFullRate() {
+		return r.ExplainSSS(monthlyEarnings), nil
+	}
+	c, err := r.ComputeSSSMember(m, monthlyEarnings)
+	if err != nil {
+		return Trace{}, err
+	}
+
+	t := Trace{Name: "SSS Contribution"}
+	t.add(Step{Rule: "Declared monthly earnings", Amount: monthlyEarnings})
+	t.add(Step{
+		Rule:   m.String() + " monthly salary credit for " + c.Range.String(),
+		Amount: c.Range.SalaryCredit,
+		Capped: c.Range.Low.IsZero() || c.Range.High.IsZero(),
+	})
+	t.Result = c.Contribution
+	t.add(Step{Rule: "Member pays the full " + percent(c.Rate) + " of salary credit", Amount: t.Result, Rate: c.Rate})
+	return t, nil
+}
+
+// SSSMemberTable lists every compensation range of a member type under the current rules
+func SSSMemberTable(m SSSMemberType) []SSSRange { return Current().SSSMemberTable(m) }
+
+// SSSMemberTable lists every compensation range of a member type, from its floor up
+func (r *Rules) SSSMemberTable(m SSSMemberType) []SSSRange {
+	var table []SSSRange
+	min := r.SSS.MinCreditFor(m)
+	for credit := min; credit.LessThanOrEqual(r.SSS.MaxCredit); credit = credit.Add(r.SSS.Step) {
+		table = append(table, r.sssRange(credit, min))
+	}
+	return table
+}
+
+// PhilHealthCategory is how a member is covered by PhilHealth. Employed members
+// split the premium with their employer, direct contributors pay all of it on
+// the income they declare
+type PhilHealthCategory string
+
+const (
+	EmployedMember PhilHealthCategory = "employed"
+	SelfEarning    PhilHealthCategory = "self-earning"
+	Professional   PhilHealthCategory = "professional"
+	MigrantWorker  PhilHealthCategory = "migrant-worker" // land-based overseas worker
+)
+
+// PhilHealthCategories lists the member categories in display order
+var PhilHealthCategories = []PhilHealthCategory{EmployedMember, SelfEarning, Professional, MigrantWorker}
+
+// String names the category on screens
+func (c PhilHealthCategory) String() string {
+	switch c {
+	case EmployedMember:
+		return "Employed"
+	case SelfEarning:
+		return "Self-Earning"
+	case Professional:
+		return "Professional"
+	case MigrantWorker:
+		return "Land-Based Migrant Worker"
+	}
+	return string(c)
+}
+
+// ParsePhilHealthCategory converts a name such as "self-earning" into a PhilHealthCategory
+func ParsePhilHealthCategory(s string) (PhilHealthCategory, error) {
+	for _, c := range PhilHealthCategories {
+		if string(c) == s || c.String() == s {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("unknown PhilHealth member category %q", s)
+}
+
+// PaysFullPremium reports whether the member pays the whole premium
+func (c PhilHealthCategory) PaysFullPremium() bool { return c != EmployedMember }
+
+// CalculatePhilHealthMember returns a member's monthly PhilHealth premium under the current rules
+func CalculatePhilHealthMember(c PhilHealthCategory, monthlyIncome decimal.Decimal) (decimal.Decimal, error) {
+	return Current().CalculatePhilHealthMember(c, monthlyIncome)
+}
+
+// CalculatePhilHealthMember returns a member's monthly PhilHealth premium under these rules
+func (r *Rules) CalculatePhilHealthMember(c PhilHealthCategory, monthlyIncome decimal.Decimal) (decimal.Decimal, error) {
+	t, err := r.ExplainPhilHealthMember(c, monthlyIncome)
+	return t.Result, err
+}
+
+// ExplainPhilHealthMember computes a member's PhilHealth premium and records the floor or ceiling hit
+func ExplainPhilHealthMember(c PhilHealthCategory, monthlyIncome decimal.Decimal) (Trace, error) {
+	return Current().ExplainPhilHealthMember(c, monthlyIncome)
+}
+
+// ExplainPhilHealthMember traces a member's PhilHealth premium under these rules.
+// Employed members pay the employee share, direct contributors the whole
+// premium between the year's direct floor and ceiling
+func (r *Rules) ExplainPhilHealthMember(c PhilHealthCategory, monthlyIncome decimal.Decimal) (Trace, error) {
+	if _, err := ParsePhilHealthCategory(string(c)); err != nil {
+		return Trace{}, err
+	}
+	if monthlyIncome.IsNegative() {
+		return Trace{}, errors.New("income cannot be negative")
+	}
+	if !c.PaysFullPremium() {
+		return r.ExplainPhilHealth(monthlyIncome), nil
+	}
+	p := r.PhilHealth
+	return r.philHealthTrace(monthlyIncome, p.DirectRate, p.DirectFloor, p.DirectCeiling,
+		c.String()+" pays the full premium"), nil
+}
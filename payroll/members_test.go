@@ -0,0 +1,75 @@
+package payroll
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestComputeSSSMember(t *testing.T) {
+	type point struct{ earnings, credit, contribution int64 }
+	// For each member type: below the floor, mid-range and above the 30,000 ceiling
+	byType := map[SSSMemberType][]point{
+		Employed:     {{1000, 4000, 180}, {15240, 15000, 675}, {50000, 30000, 1350}},
+		SelfEmployed: {{1000, 3000, 420}, {15240, 15000, 2100}, {50000, 30000, 4200}},
+		Voluntary:    {{1000, 3000, 420}, {15240, 15000, 2100}, {50000, 30000, 4200}},
+		OFW:          {{5000, 8000, 1120}, {15240, 15000, 2100}, {50000, 30000, 4200}},
+	}
+	for _, m := range SSSMemberTypes {
+		m := m
+		t.Run(string(m), func(t *testing.T) {
+			points, ok := byType[m]
+			if !ok {
+				t.Fatal("no cases for the member type")
+			}
+			for _, p := range points {
+				c, err := ComputeSSSMember(m, decimal.NewFromInt(p.earnings))
+				if err != nil {
+					t.Fatalf("%d: %v", p.earnings, err)
+				}
+				if c.Range.SalaryCredit.IntPart() != p.credit || !c.Contribution.Equal(decimal.NewFromInt(p.contribution)) {
+					t.Errorf("%d: credit %s contribution %s, want %d and %d",
+						p.earnings, c.Range.SalaryCredit, c.Contribution, p.credit, p.contribution)
+				}
+			}
+		})
+	}
+}
+
+func TestComputeSSSMemberRefuses(t *testing.T) {
+	if _, err := ComputeSSSMember("retired", decimal.NewFromInt(10000)); err == nil {
+		t.Error("unknown member type accepted")
+	}
+	if _, err := ComputeSSSMember(Voluntary, decimal.NewFromInt(-1)); err == nil {
+		t.Error("negative earnings accepted")
+	}
+}
+
+func TestCalculatePhilHealthMember(t *testing.T) {
+	// Direct contributors pay the whole 4.5%, from a 450 floor up to 10,000
+	// of income to a 4,050 ceiling from 90,000
+	direct := map[int64]string{5000: "450", 10000: "450", 40000: "1800", 90000: "4050", 120000: "4050"}
+
+	for _, c := range PhilHealthCategories {
+		if !c.PaysFullPremium() {
+			continue
+		}
+		for income, want := range direct {
+			got, err := CalculatePhilHealthMember(c, decimal.NewFromInt(income))
+			if err != nil || got.String() != want {
+				t.Errorf("%s on %d = %s, %v, want %s", c, income, got, err, want)
+			}
+		}
+	}
+
+	// Employed members pay the employee share only
+	for income, want := range map[int64]string{5000: "225", 40000: "900"} {
+		if got, _ := CalculatePhilHealthMember(EmployedMember, decimal.NewFromInt(income)); got.String() != want {
+			t.Errorf("employed on %d = %s, want %s", income, got, want)
+		}
+	}
+
+	if _, err := CalculatePhilHealthMember(SelfEarning, decimal.NewFromInt(-1)); err == nil {
+		t.Error("negative income accepted")
+	}
+}
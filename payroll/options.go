@@ -0,0 +1,220 @@
+package payroll
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// EmployeeType decides which exemptions apply to an employee
+type EmployeeType string
+
+const (
+	Regular EmployeeType = "regular"
+	// MinimumWage earners are exempt from income tax on their statutory minimum wage
+	MinimumWage EmployeeType = "minimum-wage"
+	// Kasambahay domestic workers are exempt from income tax, and their employer
+	// pays their contributions when the wage is low
+	Kasambahay EmployeeType = "kasambahay"
+)
+
+// EmployeeTypes lists the supported employee types
+var EmployeeTypes = []EmployeeType{Regular, MinimumWage, Kasambahay}
+
+// TaxExempt reports whether the type pays no income tax on its wage
+func (t EmployeeType) TaxExempt() bool {
+	return t == MinimumWage || t == Kasambahay
+}
+
+// ParseEmployeeType converts a name such as "minimum-wage" into an EmployeeType
+func ParseEmployeeType(s string) (EmployeeType, error) {
+	for _, t := range EmployeeTypes {
+		if string(t) == s {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("unknown employee type %q", s)
+}
+
+// Options changes how an income is computed
+type Options struct {
+	PayFrequency PayFrequency // how often the income is received, monthly when empty
+	Year         int          // year of the rules to apply, the current rules when zero
+	EmployeeType EmployeeType // regular when empty
+	Region       string       // where a kasambahay works, to check the regional minimum wage
+}
+
+func (o Options) validate(r *Rules) error {
+	if o.PayFrequency != "" {
+		if _, err := ParsePayFrequency(string(o.PayFrequency)); err != nil {
+			return err
+		}
+	}
+	if o.EmployeeType != "" {
+		if _, err := ParseEmployeeType(string(o.EmployeeType)); err != nil {
+			return err
+		}
+	}
+	if o.Year != 0 && o.Year != r.Year {
+		return fmt.Errorf("options are for %d but the rules are for %d", o.Year, r.Year)
+	}
+	if o.Region != "" {
+		if _, err := r.Kasambahay.MinimumWageIn(o.Region); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkWage refuses a kasambahay wage below the minimum of the region, when one is given
+func (o Options) checkWage(r *Rules, monthlyWage decimal.Decimal) error {
+	if o.EmployeeType != Kasambahay || o.Region == "" {
+		return nil
+	}
+	return r.CheckKasambahayWage(o.Region, monthlyWage)
+}
+
+// ComputeWith computes the pay for one pay period of the given frequency.
+// The income is converted to its monthly equivalent, run through the
+// calculators of the year's rules and split back across the paydays of the month
+func ComputeWith(income decimal.Decimal, opts Options) (TaxInputs, error) {
+	r, err := ForYear(opts.Year)
+	if err != nil {
+		return TaxInputs{}, err
+	}
+	return r.ComputeWith(income, opts)
+}
+
+// ComputeWith computes the pay for one pay period under these rules
+func (r *Rules) ComputeWith(income decimal.Decimal, opts Options) (TaxInputs, error) {
+	if err := opts.validate(r); err != nil {
+		return TaxInputs{}, err
+	}
+	if income.LessThan(decimal.Zero) {
+		return TaxInputs{}, errors.New("income cannot be negative")
+	}
+
+	result, err := r.computeMonthly(opts.PayFrequency.ToMonthly(income), opts)
+	if err != nil {
+		return TaxInputs{}, err
+	}
+	result.MonthlyIncome = income
+	return result, nil
+}
+
+// ComputeSalaryWith computes the pay for one pay period of a monthly salary,
+// as ComputeWith does for the income of one pay period. Payroll runs use it
+// since the register keeps monthly salaries, which would not always convert
+// to a pay period's income and back to the centavo
+func (r *Rules) ComputeSalaryWith(monthlySalary decimal.Decimal, opts Options) (TaxInputs, error) {
+	if err := opts.validate(r); err != nil {
+		return TaxInputs{}, err
+	}
+	if monthlySalary.LessThan(decimal.Zero) {
+		return TaxInputs{}, errors.New("salary cannot be negative")
+	}
+	return r.computeMonthly(monthlySalary, opts)
+}
+
+// computeMonthly checks a monthly income against the options and computes it
+// for one pay period
+func (r *Rules) computeMonthly(monthly decimal.Decimal, opts Options) (TaxInputs, error) {
+	if err := opts.checkWage(r, monthly); err != nil {
+		return TaxInputs{}, err
+	}
+	return r.applyOptions(r.Compute(monthly), opts), nil
+}
+
+// applyOptions applies the employee type's exemptions to a monthly computation
+// and splits it across the paydays of the month
+func (r *Rules) applyOptions(monthly TaxInputs, opts Options) TaxInputs {
+	// A kasambahay's employer pays the worker's share of the contributions when
+	// the wage is low, so nothing is deducted for them
+	if opts.EmployeeType == Kasambahay && r.employerPaysAll(monthly.MonthlyIncome) {
+		contributions := monthly.TotalContributions
+		monthly.TaxableIncome = monthly.TaxableIncome.Add(contributions)
+		monthly.TotalDeductions = monthly.TotalDeductions.Sub(contributions)
+		monthly.NetPayAfterDeductions = monthly.NetPayAfterDeductions.Add(contributions)
+		monthly.SSSContributions = decimal.Zero
+		monthly.PhilHealthContributions = decimal.Zero
+		monthly.PagIbigContributions = decimal.Zero
+		monthly.TotalContributions = decimal.Zero
+	}
+
+	// Minimum wage earners and kasambahay keep their contributions but pay no income tax
+	if opts.EmployeeType.TaxExempt() {
+		monthly.TotalDeductions = monthly.TotalDeductions.Sub(monthly.Tax)
+		monthly.NetPayAfterDeductions = monthly.NetPayAfterDeductions.Add(monthly.Tax)
+		monthly.Tax = decimal.Zero
+		monthly.NetPayAfterTax = monthly.MonthlyIncome
+	}
+	return monthly.PerPeriod(opts.PayFrequency)
+}
+
+// Reverse finds the gross income per pay period that leaves the given net pay
+// after all deductions. The search returns a gross reaching the target where a
+// cent less falls short. Net pay dips where a contribution steps up, so around
+// those steps a lower gross may reach the target too and is not looked for.
+// An error is returned when no gross reaches the target, as when a company
+// deduction takes a rate of the whole income
+func Reverse(netPay decimal.Decimal, opts Options) (TaxInputs, error) {
+	r, err := ForYear(opts.Year)
+	if err != nil {
+		return TaxInputs{}, err
+	}
+	return r.Reverse(netPay, opts)
+}
+
+// maxReverseDoublings is how often Reverse doubles its first guess at the gross
+// before giving up, about a million times the net pay plus 10,000
+const maxReverseDoublings = 20
+
+// Reverse finds the gross income per pay period under these rules
+func (r *Rules) Reverse(netPay decimal.Decimal, opts Options) (TaxInputs, error) {
+	if netPay.LessThan(decimal.Zero) {
+		return TaxInputs{}, errors.New("net pay cannot be negative")
+	}
+
+	if err := opts.validate(r); err != nil {
+		return TaxInputs{}, err
+	}
+	// The search passes through wages below a kasambahay's regional minimum,
+	// only the answer is checked against it
+	search := opts
+	search.Region = ""
+	net := func(gross decimal.Decimal) (TaxInputs, error) { return r.ComputeWith(gross, search) }
+
+	/* Statutory deductions take less than half the income, but registered
+	company deductions can take more, so the bound is doubled until the net
+	pay there reaches the target */
+	low, high := decimal.Zero, netPay.Mul(decimal.NewFromInt(2)).Add(decimal.NewFromInt(10000))
+	for doublings := 0; ; doublings++ {
+		result, err := net(high)
+		if err != nil {
+			return TaxInputs{}, err
+		}
+		if !result.NetPayAfterDeductions.LessThan(netPay) {
+			break
+		}
+		if doublings == maxReverseDoublings {
+			return TaxInputs{}, fmt.Errorf("no gross income up to %s leaves a net pay of %s",
+				high.StringFixed(2), netPay.StringFixed(2))
+		}
+		low, high = high, high.Mul(decimal.NewFromInt(2))
+	}
+	cent := decimal.NewFromFloat(0.01)
+	for high.Sub(low).GreaterThan(cent) {
+		mid := low.Add(high).Div(decimal.NewFromInt(2)).Round(2)
+		result, err := net(mid)
+		if err != nil {
+			return TaxInputs{}, err
+		}
+		if result.NetPayAfterDeductions.LessThan(netPay) {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return r.ComputeWith(high, opts)
+}
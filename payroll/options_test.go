@@ -0,0 +1,71 @@
+package payroll
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// figures prints the contributions, tax and net pay of a result, to the cent
+func figures(r TaxInputs) string {
+	return fmt.Sprintf("%s %s %s", r.TotalContributions.StringFixed(2), r.Tax.StringFixed(2), r.NetPayAfterDeductions.StringFixed(2))
+}
+
+func TestComputeWith(t *testing.T) {
+	tests := []struct {
+		name   string
+		income string
+		opts   Options
+		want   string // contributions, tax and net pay
+	}{
+		{"monthly", "33333", Options{}, "2199.99 1545.00 29588.01"},
+		{"semi-monthly halves the monthly figures", "16666.50", Options{PayFrequency: SemiMonthly}, "1100.00 772.50 14794.00"},
+		{"minimum wage pays no tax", "50000", Options{EmployeeType: MinimumWage}, "2575.00 0.00 47425.00"},
+	}
+	for _, tt := range tests {
+		r, err := ComputeWith(decimal.RequireFromString(tt.income), tt.opts)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		} else if got := figures(r); got != tt.want {
+			t.Errorf("%s: %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := ComputeWith(decimal.NewFromInt(-1), Options{}); err == nil {
+		t.Error("negative income accepted")
+	}
+	if _, err := ComputeWith(decimal.NewFromInt(1000), Options{PayFrequency: "fortnightly"}); err == nil {
+		t.Error("unknown pay frequency accepted")
+	}
+}
+
+// TestReverse checks the gross found is the least that reaches the net pay:
+// a cent less falls short of it
+func TestReverse(t *testing.T) {
+	cent := decimal.RequireFromString("0.01")
+	for _, tt := range []struct {
+		netPay string
+		opts   Options
+	}{
+		{"29588.01", Options{}},
+		{"15000", Options{PayFrequency: SemiMonthly}},
+		{"78999.95", Options{}},
+		{"20000", Options{EmployeeType: MinimumWage}},
+	} {
+		target := decimal.RequireFromString(tt.netPay)
+		r, err := Reverse(target, tt.opts)
+		if err != nil {
+			t.Errorf("Reverse(%s): %v", tt.netPay, err)
+			continue
+		}
+		less, err := ComputeWith(r.MonthlyIncome.Sub(cent), tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.NetPayAfterDeductions.LessThan(target) || !less.NetPayAfterDeductions.LessThan(target) {
+			t.Errorf("Reverse(%s) = gross %s leaving %s, a cent less leaves %s",
+				tt.netPay, r.MonthlyIncome, r.NetPayAfterDeductions, less.NetPayAfterDeductions)
+		}
+	}
+}
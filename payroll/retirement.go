@@ -0,0 +1,101 @@
+package payroll
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// RetirementDaysPerYear is the RA 7641 minimum of one-half month salary per year of service:
+// 15 days, plus 1/12 of the 13th month pay (2.5 days) and 5 days of service incentive leave
+var RetirementDaysPerYear = decimal.NewFromFloat(22.5)
+
+// RetirementInputs describes a retiring employee and the company retirement plan, if any
+type RetirementInputs struct {
+	MonthlySalary  decimal.Decimal
+	BirthDate      time.Time
+	HireDate       time.Time
+	RetirementDate time.Time
+
+	PlanAmount        decimal.Decimal // benefit under the company plan, zero if there is none
+	PlanApproved      bool            // the plan is a BIR-approved reasonable private benefit plan
+	PreviouslyAvailed bool            // the employee already received a tax-exempt retirement benefit
+}
+
+// RetirementStatement is the result of ComputeRetirementPay
+type RetirementStatement struct {
+	Age          int
+	YearsService int
+	DailyRate    decimal.Decimal
+
+	StatutoryEligible bool            // meets the RA 7641 age and service requirements
+	StatutoryMinimum  decimal.Decimal // 22.5 days per year of service
+	PlanAmount        decimal.Decimal
+	Benefit           decimal.Decimal // amount actually payable
+
+	TaxExempt     bool
+	ExemptReason  string
+	TaxableAmount decimal.Decimal // portion to be added to taxable compensation
+}
+
+// ComputeRetirementPay works out the retirement pay owed and whether it is tax-exempt
+func ComputeRetirementPay(in RetirementInputs) RetirementStatement {
+	var st RetirementStatement
+	st.Age = ageOn(in.BirthDate, in.RetirementDate)
+	st.YearsService = yearsOfService(in.HireDate, in.RetirementDate)
+	st.DailyRate = DailyRate(in.MonthlySalary)
+	st.PlanAmount = in.PlanAmount
+
+	/* RA 7641 applies when there is no plan or the plan gives less:
+	the employee must be 60 to 65 years old and have served at least 5 years */
+	st.StatutoryEligible = st.Age >= 60 && st.Age <= 65 && st.YearsService >= 5
+	if st.StatutoryEligible {
+		st.StatutoryMinimum = st.DailyRate.Mul(RetirementDaysPerYear).
+			Mul(decimal.NewFromInt(int64(st.YearsService))).Round(2)
+	}
+	st.Benefit = decimal.Max(st.StatutoryMinimum, st.PlanAmount)
+
+	/* Section 32(B)(6)(a) of the Tax Code exempts retirement benefits that are availed of
+	only once, either under RA 7641 or under a BIR-approved plan where the employee is at
+	least 50 years old with at least 10 years of service to the same employer */
+	switch {
+	case st.Benefit.IsZero():
+		st.ExemptReason = "No retirement benefit is payable"
+	case in.PreviouslyAvailed:
+		st.ExemptReason = "The exemption was already availed of before"
+	case in.PlanApproved && st.PlanAmount.GreaterThanOrEqual(st.StatutoryMinimum):
+		if st.Age >= 50 && st.YearsService >= 10 {
+			st.TaxExempt = true
+			st.ExemptReason = "Approved plan, at least 50 years old with 10 years of service"
+		} else {
+			st.ExemptReason = "Approved plan requires age 50 and 10 years of service"
+		}
+	case st.StatutoryEligible:
+		st.TaxExempt = true
+		st.ExemptReason = "RA 7641, 60 to 65 years old with 5 years of service"
+	default:
+		st.ExemptReason = "Does not meet the RA 7641 or approved plan requirements"
+	}
+
+	if !st.TaxExempt {
+		st.TaxableAmount = st.Benefit
+	}
+
+	return st
+}
+
+// AdditionalTax is the extra withholding tax when the taxable retirement pay
+// is added to the month's taxable income
+func (st RetirementStatement) AdditionalTax(taxableIncome decimal.Decimal) decimal.Decimal {
+	return CalculateTax(taxableIncome.Add(st.TaxableAmount)).Sub(CalculateTax(taxableIncome))
+}
+
+// ageOn returns the age in completed years on the given date
+func ageOn(birthDate, date time.Time) int {
+	age := date.Year() - birthDate.Year()
+	if date.Month() < birthDate.Month() ||
+		(date.Month() == birthDate.Month() && date.Day() < birthDate.Day()) {
+		age--
+	}
+	return age
+}
@@ -0,0 +1,87 @@
+package payroll
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var retirementDay = time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+
+// retiree is an employee paid 26,100 a month, a daily rate of 1,200, who
+// retires at the given age after the given years of service
+func retiree(age, years int) RetirementInputs {
+	return RetirementInputs{
+		MonthlySalary:  decimal.NewFromInt(26100),
+		BirthDate:      retirementDay.AddDate(-age, 0, 0),
+		HireDate:       retirementDay.AddDate(-years, 0, 0),
+		RetirementDate: retirementDay,
+	}
+}
+
+func TestComputeRetirementPay(t *testing.T) {
+	withPlan := func(in RetirementInputs, amount int64, approved bool) RetirementInputs {
+		in.PlanAmount, in.PlanApproved = decimal.NewFromInt(amount), approved
+		return in
+	}
+	previously := retiree(60, 20)
+	previously.PreviouslyAvailed = true
+	fourYears := retiree(62, 4)
+	fourYears.HireDate = fourYears.HireDate.AddDate(0, -5, 0) // 4 years and 5 months count as 4
+
+	tests := []struct {
+		name     string
+		in       RetirementInputs
+		eligible bool
+		minimum  int64 // 22.5 days of 1,200 for each year of service when eligible
+		benefit  int64
+		exempt   bool
+		taxable  int64
+	}{
+		{"RA 7641 at 60 with 20 years", retiree(60, 20), true, 540000, 540000, true, 0},
+		{"RA 7641 at 65 with 5 years", retiree(65, 5), true, 135000, 135000, true, 0},
+		{"past 65", retiree(66, 20), false, 0, 0, false, 0},
+		{"before 60", retiree(59, 20), false, 0, 0, false, 0},
+		{"under 5 years of service", fourYears, false, 0, 0, false, 0},
+		{"exemption availed before", previously, true, 540000, 540000, false, 540000},
+		{"approved plan at 50 with 10 years", withPlan(retiree(50, 10), 500000, true), false, 0, 500000, true, 0},
+		{"approved plan with 9 years", withPlan(retiree(50, 9), 500000, true), false, 0, 500000, false, 500000},
+		{"approved plan at 49", withPlan(retiree(49, 10), 500000, true), false, 0, 500000, false, 500000},
+		{"plan not approved", withPlan(retiree(55, 15), 800000, false), false, 0, 800000, false, 800000},
+		// The plan gives less than the law, so the statutory minimum is paid under RA 7641
+		{"approved plan below the minimum", withPlan(retiree(61, 10), 100000, true), true, 270000, 270000, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := ComputeRetirementPay(tt.in)
+			if st.StatutoryEligible != tt.eligible || !st.StatutoryMinimum.Equal(decimal.NewFromInt(tt.minimum)) {
+				t.Errorf("eligible %v with a minimum of %s, want %v and %d", st.StatutoryEligible, st.StatutoryMinimum, tt.eligible, tt.minimum)
+			}
+			if !st.Benefit.Equal(decimal.NewFromInt(tt.benefit)) {
+				t.Errorf("benefit %s, want %d", st.Benefit, tt.benefit)
+			}
+			if st.TaxExempt != tt.exempt || !st.TaxableAmount.Equal(decimal.NewFromInt(tt.taxable)) {
+				t.Errorf("exempt %v (%s) with %s taxable, want %v and %d", st.TaxExempt, st.ExemptReason, st.TaxableAmount, tt.exempt, tt.taxable)
+			}
+		})
+	}
+}
+
+func TestRetirementAdditionalTax(t *testing.T) {
+	exempt := ComputeRetirementPay(retiree(60, 20))
+	if got := exempt.AdditionalTax(decimal.NewFromInt(30000)); !got.IsZero() {
+		t.Errorf("exempt retirement pay adds %s of tax", got)
+	}
+
+	// 500,000 on top of 30,000 of taxable income moves the month from the
+	// 15% bracket into the 30% one
+	in := retiree(50, 9)
+	in.PlanAmount, in.PlanApproved = decimal.NewFromInt(500000), true
+	taxed := ComputeRetirementPay(in)
+	got := taxed.AdditionalTax(decimal.NewFromInt(30000))
+	want := CalculateTax(decimal.NewFromInt(530000)).Sub(CalculateTax(decimal.NewFromInt(30000)))
+	if !got.Equal(want) || got.StringFixed(2) != "141166.65" {
+		t.Errorf("additional tax %s, want 141166.65", got)
+	}
+}
@@ -0,0 +1,475 @@
+package payroll
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/BurntSushi/toml"
+	"github.com/shopspring/decimal"
+	"gopkg.in/yaml.v3"
+)
+
+// Rules is one year's tax and contribution tables. The calculators are
+// methods of Rules, the package functions apply the current rules
+type Rules struct {
+	Version string // identifies the tables, recorded with saved computations
+	Year    int    // year the tables take effect
+	Source  string // file the tables were read from
+	Digest  string // short hash of the file, tells edits apart when Version was not changed
+
+	monthlyBrackets, monthlyRates []decimal.Decimal
+	annualBrackets, annualRates   []decimal.Decimal
+
+	// FringeBenefitRate is the final tax on the grossed-up value of fringe
+	// benefits given to managerial and supervisory employees
+	FringeBenefitRate decimal.Decimal
+
+	SSS        SSSSchedule
+	PhilHealth PhilHealthTable
+	PagIbig    PagIbigTable
+	Kasambahay KasambahayRules
+}
+
+// String identifies the rules in logs, e.g. "2023 [1a2b3c4d]"
+func (r *Rules) String() string {
+	return fmt.Sprintf("%s [%s]", r.Version, r.Digest)
+}
+
+// SSSSchedule is the SSS contribution, a rate of the salary credit
+type SSSSchedule struct {
+	MinCredit    decimal.Decimal `toml:"min_credit" yaml:"min_credit"`
+	MaxCredit    decimal.Decimal `toml:"max_credit" yaml:"max_credit"`
+	Step         decimal.Decimal `toml:"step" yaml:"step"` // credits are the income rounded to this step
+	EmployeeRate decimal.Decimal `toml:"employee_rate" yaml:"employee_rate"`
+	EmployerRate decimal.Decimal `toml:"employer_rate" yaml:"employer_rate"`
+
+	// Highest salary credit benefits are computed on, credits above it go to the provident fund
+	BenefitMaxCredit decimal.Decimal `toml:"benefit_max_credit" yaml:"benefit_max_credit"`
+
+	// Employees' compensation, paid by the employer alone: ECLow for salary
+	// credits below ECThreshold, ECHigh from it up
+	ECThreshold decimal.Decimal `toml:"ec_threshold" yaml:"ec_threshold"`
+	ECLow       decimal.Decimal `toml:"ec_low" yaml:"ec_low"`
+	ECHigh      decimal.Decimal `toml:"ec_high" yaml:"ec_high"`
+
+	// Salary credit floors of the members who pay the whole contribution themselves
+	Members []SSSMemberCredit `toml:"members" yaml:"members"`
+}
+
+// RuleSet is every year of rules that was loaded
+type RuleSet struct {
+	years map[int]*Rules
+}
+
+// Years lists the years with rules, oldest first
+func (s *RuleSet) Years() []int {
+	var years []int
+	for y := range s.years {
+		years = append(years, y)
+	}
+	sort.Ints(years)
+	return years
+}
+
+// Latest returns the rules of the most recent year
+func (s *RuleSet) Latest() *Rules {
+	years := s.Years()
+	return s.years[years[len(years)-1]]
+}
+
+// ForYear returns the rules of a year, the latest rules when year is zero
+func (s *RuleSet) ForYear(year int) (*Rules, error) {
+	if year == 0 {
+		return s.Latest(), nil
+	}
+	if r, ok := s.years[year]; ok {
+		return r, nil
+	}
+	return nil, fmt.Errorf("rules for %d are not available, only %s", year, s.yearList())
+}
+
+func (s *RuleSet) yearList() string {
+	var years []string
+	for _, y := range s.Years() {
+		years = append(years, fmt.Sprint(y))
+	}
+	return strings.Join(years, ", ")
+}
+
+//go:embed rules
+var defaultRules embed.FS
+
+// active is the rule set the package functions apply
+var active atomic.Pointer[RuleSet]
+
+func init() {
+	set, err := LoadRules("")
+	if err != nil {
+		panic("payroll: built-in rules are invalid: " + err.Error())
+	}
+	active.Store(set)
+}
+
+// Use makes set the rules applied by the package functions
+func Use(set *RuleSet) { active.Store(set) }
+
+// Current returns the rules of the most recent year
+func Current() *Rules { return active.Load().Latest() }
+
+// ForYear returns the rules in effect for a year, the current rules when year is zero
+func ForYear(year int) (*Rules, error) { return active.Load().ForYear(year) }
+
+// Years lists the years with rules available, oldest first
+func Years() []int { return active.Load().Years() }
+
+// LoadRules reads the built-in rules and then every .toml, .yaml or .yml file in
+// dir. A file for a year that is already loaded replaces it. An empty or missing
+// dir leaves just the built-in rules. Every file is validated and nothing is
+// returned if any of them is invalid
+func LoadRules(dir string) (*RuleSet, error) {
+	set := &RuleSet{years: map[int]*Rules{}}
+	var errs []error
+
+	load := func(fsys fs.FS, name, source string, loaded map[int]string) {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		r, err := ParseRules(source, data)
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		if other, ok := loaded[r.Year]; ok {
+			errs = append(errs, fmt.Errorf("%s: rules for %d are already in %s", source, r.Year, other))
+			return
+		}
+		loaded[r.Year] = source
+		set.years[r.Year] = r
+	}
+
+	builtIn := map[int]string{}
+	names, _ := fs.Glob(defaultRules, "rules/*")
+	for _, name := range names {
+		load(defaultRules, name, "built-in "+name, builtIn)
+	}
+
+	if dir != "" {
+		entries, err := os.ReadDir(dir)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+		overrides := map[int]string{}
+		for _, e := range entries {
+			if e.IsDir() || !isRulesFile(e.Name()) {
+				continue
+			}
+			load(os.DirFS(dir), e.Name(), filepath.Join(dir, e.Name()), overrides)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	if len(set.years) == 0 {
+		return nil, errors.New("no rules found")
+	}
+	return set, nil
+}
+
+func isRulesFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".toml", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// rulesFile is the layout of a rules file, see rules/2023.toml
+type rulesFile struct {
+	Version string `toml:"version" yaml:"version"`
+	Year    int    `toml:"year" yaml:"year"`
+	Tax     struct {
+		Monthly []bracketRow `toml:"monthly" yaml:"monthly"`
+		Annual  []bracketRow `toml:"annual" yaml:"annual"`
+
+		FringeRate decimal.Decimal `toml:"fringe_rate" yaml:"fringe_rate"`
+	} `toml:"tax" yaml:"tax"`
+	SSS        SSSSchedule     `toml:"sss" yaml:"sss"`
+	PhilHealth PhilHealthTable `toml:"philhealth" yaml:"philhealth"`
+	PagIbig    PagIbigTable    `toml:"pagibig" yaml:"pagibig"`
+	Kasambahay KasambahayRules `toml:"kasambahay" yaml:"kasambahay"`
+}
+
+type bracketRow struct {
+	Over decimal.Decimal  `toml:"over" yaml:"over"`
+	UpTo *decimal.Decimal `toml:"up_to" yaml:"up_to"` // nil for the top bracket
+	Rate decimal.Decimal  `toml:"rate" yaml:"rate"`
+}
+
+// ParseRules reads and validates a rules file, in YAML when the name ends in
+// .yaml or .yml and in TOML otherwise
+func ParseRules(name string, data []byte) (*Rules, error) {
+	var f rulesFile
+	if err := decodeFile(name, data, &f); err != nil {
+		return nil, err
+	}
+
+	if err := f.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	r := &Rules{
+		Version:    f.Version,
+		Year:       f.Year,
+		Source:     name,
+		Digest:     fmt.Sprintf("%x", sha256.Sum256(data))[:8],
+		SSS:        f.SSS,
+		PhilHealth: f.PhilHealth,
+		PagIbig:    f.PagIbig,
+		Kasambahay: f.Kasambahay,
+
+		FringeBenefitRate: f.Tax.FringeRate,
+	}
+	r.monthlyBrackets, r.monthlyRates = thresholds(f.Tax.Monthly)
+	r.annualBrackets, r.annualRates = thresholds(f.Tax.Annual)
+	return r, nil
+}
+
+// decodeFile decodes YAML when the name ends in .yaml or .yml and TOML otherwise,
+// rejecting unknown keys so a misspelt setting is not silently left at zero
+func decodeFile(name string, data []byte, v interface{}) error {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(v); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	default:
+		md, err := toml.Decode(string(data), v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("%s: unknown key %s", name, undecoded[0])
+		}
+	}
+	return nil
+}
+
+// thresholds converts validated brackets into the upper bounds and rates graduatedTax walks
+func thresholds(rows []bracketRow) (brackets, rates []decimal.Decimal) {
+	for _, row := range rows {
+		if row.UpTo != nil {
+			brackets = append(brackets, *row.UpTo)
+		}
+		rates = append(rates, row.Rate)
+	}
+	return
+}
+
+var one = decimal.NewFromInt(1)
+
+// validate reports every problem with the file at once
+func (f *rulesFile) validate() error {
+	var errs []error
+	problem := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+	positive := func(name string, d decimal.Decimal) {
+		if !d.IsPositive() {
+			problem("%s must be more than zero", name)
+		}
+	}
+	rate := func(name string, d decimal.Decimal) {
+		if !d.IsPositive() || d.GreaterThan(one) {
+			problem("%s must be more than 0 and at most 1, got %s", name, d)
+		}
+	}
+
+	if f.Version == "" {
+		problem("version is missing")
+	}
+	if f.Year < 2000 || f.Year > 2100 {
+		problem("year %d is out of range", f.Year)
+	}
+
+	validateBrackets("tax.monthly", f.Tax.Monthly, problem)
+	validateBrackets("tax.annual", f.Tax.Annual, problem)
+	if !f.Tax.FringeRate.IsPositive() || !f.Tax.FringeRate.LessThan(one) {
+		problem("tax.fringe_rate must be more than 0 and below 1, got %s", f.Tax.FringeRate)
+	}
+
+	s := f.SSS
+	positive("sss.min_credit", s.MinCredit)
+	positive("sss.step", s.Step)
+	if !s.MaxCredit.GreaterThan(s.MinCredit) {
+		problem("sss.max_credit must be above sss.min_credit")
+	} else if s.Step.IsPositive() && !s.MaxCredit.Sub(s.MinCredit).Mod(s.Step).IsZero() {
+		problem("sss.max_credit must be sss.min_credit plus a whole number of steps")
+	}
+	rate("sss.employee_rate", s.EmployeeRate)
+	rate("sss.employer_rate", s.EmployerRate)
+	positive("sss.benefit_max_credit", s.BenefitMaxCredit)
+	if s.BenefitMaxCredit.GreaterThan(s.MaxCredit) {
+		problem("sss.benefit_max_credit must not be above sss.max_credit")
+	}
+	positive("sss.ec_threshold", s.ECThreshold)
+	positive("sss.ec_low", s.ECLow)
+	if s.ECHigh.LessThan(s.ECLow) {
+		problem("sss.ec_high must not be below sss.ec_low")
+	}
+	members := map[SSSMemberType]bool{}
+	for i, c := range s.Members {
+		at := fmt.Sprintf("sss.members %d", i+1)
+		if _, err := ParseSSSMemberType(string(c.Type)); err != nil || !c.Type.PaysFullRate() {
+			problem("%s: type must be self-employed, voluntary or ofw, got %q", at, c.Type)
+		} else if members[c.Type] {
+			problem("%s: type %s is listed twice", at, c.Type)
+		}
+		members[c.Type] = true
+		positive(at+" min_credit", c.MinCredit)
+		if !c.MinCredit.LessThan(s.MaxCredit) {
+			problem("%s: min_credit must be below sss.max_credit", at)
+		} else if s.Step.IsPositive() && !s.MaxCredit.Sub(c.MinCredit).Mod(s.Step).IsZero() {
+			problem("%s: min_credit must be sss.max_credit less a whole number of steps", at)
+		}
+	}
+	for _, m := range SSSMemberTypes {
+		if m.PaysFullRate() && !members[m] {
+			problem("sss.members has no salary credit floor for %s members", m)
+		}
+	}
+
+	p := f.PhilHealth
+	rate("philhealth.rate", p.Rate)
+	positive("philhealth.floor_income", p.FloorIncome)
+	positive("philhealth.floor", p.Floor)
+	if !p.CeilingIncome.GreaterThan(p.FloorIncome) {
+		problem("philhealth.ceiling_income must be above philhealth.floor_income")
+	}
+	if p.Ceiling.LessThan(p.Floor) {
+		problem("philhealth.ceiling must not be below philhealth.floor")
+	}
+	rate("philhealth.employer_rate", p.EmployerRate)
+	positive("philhealth.employer_floor", p.EmployerFloor)
+	if p.EmployerCeiling.LessThan(p.EmployerFloor) {
+		problem("philhealth.employer_ceiling must not be below philhealth.employer_floor")
+	}
+	rate("philhealth.direct_rate", p.DirectRate)
+	positive("philhealth.direct_floor", p.DirectFloor)
+	if p.DirectCeiling.LessThan(p.DirectFloor) {
+		problem("philhealth.direct_ceiling must not be below philhealth.direct_floor")
+	}
+
+	g := f.PagIbig
+	if g.LowIncome.IsNegative() {
+		problem("pagibig.low_income cannot be negative")
+	}
+	rate("pagibig.low_rate", g.LowRate)
+	rate("pagibig.rate", g.Rate)
+	positive("pagibig.max", g.Max)
+	rate("pagibig.employer_rate", g.EmployerRate)
+	positive("pagibig.employer_max", g.EmployerMax)
+	h := g.Housing
+	rate("pagibig.housing.capacity_ratio", h.CapacityRatio)
+	positive("pagibig.housing.max_loan", h.MaxLoan)
+	if h.MaxTermYears < 1 || h.MaxTermYears > 40 {
+		problem("pagibig.housing.max_term_years must be from 1 to 40, got %d", h.MaxTermYears)
+	}
+	if h.MaxAge < 18 {
+		problem("pagibig.housing.max_age must be at least 18, got %d", h.MaxAge)
+	}
+	if len(h.Rates) == 0 {
+		problem("pagibig.housing.rates needs at least one repricing period")
+	}
+	for i, r := range h.Rates {
+		at := fmt.Sprintf("pagibig.housing.rates %d", i+1)
+		if r.FixingYears < 1 || r.FixingYears > h.MaxTermYears {
+			problem("%s: fixing_years must be from 1 to pagibig.housing.max_term_years, got %d", at, r.FixingYears)
+		} else if i > 0 && r.FixingYears <= h.Rates[i-1].FixingYears {
+			problem("%s: fixing_years must be above the previous period's", at)
+		}
+		rate(at+" rate", r.Rate)
+	}
+	if len(g.MP2Dividends) == 0 {
+		problem("pagibig.mp2_dividends needs at least one year")
+	}
+	for i, d := range g.MP2Dividends {
+		at := fmt.Sprintf("pagibig.mp2_dividends %d", i+1)
+		if d.Year < 2000 || d.Year > f.Year {
+			problem("%s: year must be from 2000 to the rules' year, got %d", at, d.Year)
+		} else if i > 0 && d.Year <= g.MP2Dividends[i-1].Year {
+			problem("%s: year must be after the previous one", at)
+		}
+		rate(at+" rate", d.Rate)
+	}
+
+	k := f.Kasambahay
+	positive("kasambahay.employer_pays_below", k.EmployerPaysBelow)
+	if len(k.MinimumWage) == 0 {
+		problem("kasambahay.minimum_wage needs at least one region")
+	}
+	regions := map[string]bool{}
+	for i, w := range k.MinimumWage {
+		at := fmt.Sprintf("kasambahay.minimum_wage %d", i+1)
+		if w.Region == "" {
+			problem("%s: region is missing", at)
+		} else if regions[strings.ToLower(w.Region)] {
+			problem("%s: region %s is listed twice", at, w.Region)
+		}
+		regions[strings.ToLower(w.Region)] = true
+		positive(at+" wage", w.Wage)
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateBrackets checks that the brackets start at zero, follow on from each
+// other without gaps or overlaps, and that rates are fractions that never fall
+func validateBrackets(name string, rows []bracketRow, problem func(string, ...interface{})) {
+	if len(rows) < 2 {
+		problem("%s needs at least two brackets", name)
+		return
+	}
+	if !rows[0].Over.IsZero() {
+		problem("%s: the first bracket must start at 0, not %s", name, rows[0].Over)
+	}
+	if !rows[0].Rate.IsZero() {
+		problem("%s: the first bracket is the exempt bracket, its rate must be 0", name)
+	}
+	for i, row := range rows {
+		at := fmt.Sprintf("%s bracket %d", name, i+1)
+		last := i == len(rows)-1
+
+		if row.Rate.IsNegative() || !row.Rate.LessThan(one) {
+			problem("%s: rate must be from 0 to below 1, got %s", at, row.Rate)
+		}
+		if i > 0 && row.Rate.LessThan(rows[i-1].Rate) {
+			problem("%s: rate %s is lower than the bracket below", at, row.Rate)
+		}
+
+		switch {
+		case last && row.UpTo != nil:
+			problem("%s: the top bracket must not have up_to", at)
+		case !last && row.UpTo == nil:
+			problem("%s: only the top bracket can leave out up_to", at)
+		case !last && !row.UpTo.GreaterThan(row.Over):
+			problem("%s: up_to %s must be above over %s", at, row.UpTo, row.Over)
+		}
+		if i > 0 && rows[i-1].UpTo != nil && !row.Over.Equal(*rows[i-1].UpTo) {
+			problem("%s: starts at %s but the bracket below ends at %s", at, row.Over, rows[i-1].UpTo)
+		}
+	}
+}
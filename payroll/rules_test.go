@@ -0,0 +1,158 @@
+package payroll
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// builtIn returns the embedded 2023 rule file with old replaced by new
+func builtIn(t *testing.T, old, new string) []byte {
+	t.Helper()
+	data, err := defaultRules.ReadFile("rules/2023.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), old) {
+		t.Fatalf("%q is not in the built-in file", old)
+	}
+	return []byte(strings.Replace(string(data), old, new, 1))
+}
+
+func TestParseRules(t *testing.T) {
+	r, err := ParseRules("2023.toml", builtIn(t, "", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Year != 2023 {
+		t.Errorf("year %d, want 2023", r.Year)
+	}
+
+	// Each change breaks the file, and the error names the setting
+	broken := []struct{ old, new, want string }{
+		{"fringe_rate = 0.35", "fringe_rate = 0.35\nfrindge = 1", "unknown key"},
+		{"fringe_rate = 0.35", "fringe_rate = 1", "tax.fringe_rate"},
+		{"over = 33333\nup_to = 66667", "over = 33334\nup_to = 66667", "tax.monthly"},
+		{"employee_rate = 0.045", "employee_rate = 4.5", "sss.employee_rate"},
+		{"ceiling_income = 90000", "ceiling_income = 9000", "philhealth.ceiling_income"},
+	}
+	for _, b := range broken {
+		_, err := ParseRules("2023.toml", builtIn(t, b.old, b.new))
+		if err == nil || !strings.Contains(err.Error(), b.want) {
+			t.Errorf("%q: error %v, want one mentioning %q", b.new, err, b.want)
+		}
+	}
+}
+
+func TestParseRulesYAML(t *testing.T) {
+	if _, err := ParseRules("2023.yaml", []byte("tax: [")); err == nil {
+		t.Error("invalid YAML accepted")
+	}
+}
+
+// TestCompute checks the built-in tables across the tax brackets and the
+// contribution floors and ceilings
+func TestCompute(t *testing.T) {
+	want := map[string]string{
+		// income: SSS, PhilHealth, Pag-IBIG, tax and net pay
+		"10000":   "450.00 225.00 100.00 0.00 9225.00",
+		"20833":   "945.00 468.74 100.00 0.00 19319.26",
+		"33333":   "1350.00 749.99 100.00 1545.00 29588.01",
+		"50000":   "1350.00 1125.00 100.00 4693.40 42731.60",
+		"100000":  "1350.00 4050.00 100.00 15500.05 78999.95",
+		"1000000": "1350.00 4050.00 100.00 298283.35 696216.65",
+	}
+	for income, w := range want {
+		r := Compute(mustDecimal(income))
+		got := strings.Join([]string{
+			r.SSSContributions.StringFixed(2), r.PhilHealthContributions.StringFixed(2),
+			r.PagIbigContributions.StringFixed(2), r.Tax.StringFixed(2), r.NetPayAfterDeductions.StringFixed(2),
+		}, " ")
+		if got != w {
+			t.Errorf("Compute(%s) = %s, want %s", income, got, w)
+		}
+	}
+}
+
+func TestGraduatedTax(t *testing.T) {
+	brackets := decimals("20833", "33333", "66667", "166667", "666667")
+	rates := decimals("0", "0.15", "0.20", "0.25", "0.30", "0.35")
+	for income, want := range map[string]string{
+		"20000":  "0",         // below the first bracket
+		"33333":  "1875",      // at a bracket's end
+		"47425":  "4693.4",    // inside a bracket
+		"994500": "298283.35", // top bracket
+	} {
+		if got := graduatedTax(mustDecimal(income), brackets, rates); got.String() != want {
+			t.Errorf("graduatedTax(%s) = %s, want %s", income, got, want)
+		}
+	}
+
+	// Tables of one and two rows, as a rule file may give
+	if got := graduatedTax(mustDecimal("47425"), decimals("20833"), decimals("0", "0.15")); got.String() != "3988.8" {
+		t.Errorf("two rows: %s, want 3988.8", got)
+	}
+	if got := graduatedTax(mustDecimal("1000"), nil, decimals("0.10")); got.String() != "100" {
+		t.Errorf("one row: %s, want 100", got)
+	}
+}
+
+func mustDecimal(s string) decimal.Decimal { return decimal.RequireFromString(s) }
+
+func decimals(values ...string) []decimal.Decimal {
+	var ds []decimal.Decimal
+	for _, v := range values {
+		ds = append(ds, mustDecimal(v))
+	}
+	return ds
+}
+
+func TestLoadRules(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A file for a new year becomes the latest rules, the built-in year stays
+	write("2024.toml", builtIn(t, "version = \"2023\"\nyear = 2023", "version = \"2024 test\"\nyear = 2024"))
+	write("notes.txt", []byte("not a rules file"))
+	set, err := LoadRules(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if years := set.Years(); len(years) != 2 || years[0] != 2023 || years[1] != 2024 {
+		t.Errorf("years %v, want [2023 2024]", years)
+	}
+	if v := set.Latest().Version; v != "2024 test" {
+		t.Errorf("latest rules %q", v)
+	}
+
+	// Two files for the same year in the folder cannot both apply
+	write("2024-copy.toml", builtIn(t, "version = \"2023\"\nyear = 2023", "version = \"2024 copy\"\nyear = 2024"))
+	if _, err := LoadRules(dir); err == nil || !strings.Contains(err.Error(), "already in") {
+		t.Errorf("duplicate year: error %v", err)
+	}
+
+	if set, err := LoadRules(filepath.Join(dir, "missing")); err != nil || len(set.Years()) != 1 {
+		t.Errorf("missing folder: %v", err)
+	}
+}
+
+func TestParseRulesMP2Dividends(t *testing.T) {
+	for _, tt := range []struct{ old, new, want string }{
+		{"year = 2018\nrate = 0.0741", "year = 2016\nrate = 0.0741", "pagibig.mp2_dividends 2"},
+		{"year = 2023\nrate = 0.0705", "year = 2024\nrate = 0.0705", "pagibig.mp2_dividends 7"},
+		{"year = 2023\nrate = 0.0705", "year = 2023\nrate = 7.05", "pagibig.mp2_dividends 7"},
+	} {
+		_, err := ParseRules("2023.toml", builtIn(t, tt.old, tt.new))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: error %v, want one mentioning %q", tt.new, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package payroll
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// SSSBenefit is a short-term SSS benefit that can be estimated from a contribution history
+type SSSBenefit string
+
+const (
+	MaternityBenefit    SSSBenefit = "maternity"
+	SicknessBenefit     SSSBenefit = "sickness"
+	UnemploymentBenefit SSSBenefit = "unemployment"
+)
+
+// SSSBenefits lists the benefits in display order
+var SSSBenefits = []SSSBenefit{MaternityBenefit, SicknessBenefit, UnemploymentBenefit}
+
+// String names the benefit on screens
+func (b SSSBenefit) String() string {
+	switch b {
+	case MaternityBenefit:
+		return "Maternity Benefit"
+	case SicknessBenefit:
+		return "Sickness Benefit"
+	case UnemploymentBenefit:
+		return "Unemployment Benefit"
+	}
+	return string(b)
+}
+
+// ParseSSSBenefit converts a name such as "maternity" into an SSSBenefit
+func ParseSSSBenefit(s string) (SSSBenefit, error) {
+	for _, b := range SSSBenefits {
+		if string(b) == s || b.String() == s {
+			return b, nil
+		}
+	}
+	return "", fmt.Errorf("unknown SSS benefit %q", s)
+}
+
+const (
+	// MaternityDays is the maternity leave paid for a live birth (RA 11210)
+	MaternityDays = 105
+	// MiscarriageDays is the leave paid for a miscarriage or emergency termination of pregnancy
+	MiscarriageDays = 60
+	// SicknessMinDays is the shortest confinement the sickness benefit pays for
+	SicknessMinDays = 4
+	// SicknessMaxDays is the most days of sickness benefit paid in a year
+	SicknessMaxDays = 120
+	// UnemploymentMonths is how many months the unemployment benefit is paid (RA 11199)
+	UnemploymentMonths = 2
+)
+
+var (
+	// SicknessRate is the share of the average daily salary credit paid per day of sickness
+	SicknessRate = decimal.NewFromFloat(0.9)
+	// UnemploymentRate is the share of the average monthly salary credit paid per month of unemployment
+	UnemploymentRate = decimal.NewFromFloat(0.5)
+)
+
+// SSSContribution is one month an SSS contribution was paid
+type SSSContribution struct {
+	Month  time.Time       // any day of the month
+	Amount decimal.Decimal // the monthly salary credit, or the compensation it is taken from
+}
+
+// ParseSSSHistory reads a contribution history, one "YYYY-MM,amount" per line.
+// Blank lines, lines starting with # and a heading line are skipped
+func ParseSSSHistory(in io.Reader) ([]SSSContribution, error) {
+	var history []SSSContribution
+	first := true
+	scanner := bufio.NewScanner(in)
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		heading := first
+		first = false
+
+		fields, err := csv.NewReader(strings.NewReader(text)).Read()
+		if err != nil || len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: use YYYY-MM,amount", n)
+		}
+		month, err := time.Parse("2006-01", strings.TrimSpace(fields[0]))
+		if err != nil {
+			if heading {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: %q is not a month such as 2023-01", n, fields[0])
+		}
+		amount, err := decimal.NewFromString(strings.TrimSpace(strings.ReplaceAll(fields[1], ",", "")))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %q is not a valid amount", n, fields[1])
+		}
+		history = append(history, SSSContribution{Month: month, Amount: amount})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// SteadySSSHistory is a contribution on the same compensation every month for
+// the given number of months up to and including the month of last
+func SteadySSSHistory(compensation decimal.Decimal, last time.Time, months int) []SSSContribution {
+	history := make([]SSSContribution, months)
+	for i := range history {
+		history[i] = SSSContribution{Month: monthOf(last).AddDate(0, i-months+1, 0), Amount: compensation}
+	}
+	return history
+}
+
+// SSSBenefitInputs is a benefit claim and the member's contribution history
+type SSSBenefitInputs struct {
+	Benefit SSSBenefit
+	// Contingency is the date of delivery or miscarriage, the first day of
+	// confinement, or the date of involuntary separation
+	Contingency time.Time
+	History     []SSSContribution
+	Miscarriage bool // maternity for a miscarriage or emergency termination, 60 days instead of 105
+	SickDays    int  // days of confinement for the sickness benefit
+}
+
+// SSSBenefitStatement is the result of ComputeSSSBenefit
+type SSSBenefitStatement struct {
+	Benefit SSSBenefit
+
+	// Semester of contingency, the quarter of the contingency and the one before.
+	// Zero for the unemployment benefit, which counts from the month of separation
+	SemesterFrom, SemesterTo time.Time
+	// Months whose contributions qualify the member, the first days of the first and last months
+	QualifyingFrom, QualifyingTo time.Time
+
+	Contributions int // paid in the qualifying months
+	Required      int
+	Eligible      bool
+	Reason        string
+
+	Credits []SSSContribution // the salary credits averaged, capped at the benefit maximum
+	Average decimal.Decimal   // average daily salary credit, or monthly for the unemployment benefit
+	Rate    decimal.Decimal   // share of the average paid
+	Periods int               // days paid, or months for the unemployment benefit
+	Amount  decimal.Decimal
+}
+
+// ComputeSSSBenefit estimates an SSS benefit under the current rules
+func ComputeSSSBenefit(in SSSBenefitInputs) (SSSBenefitStatement, error) {
+	return Current().ComputeSSSBenefit(in)
+}
+
+// ComputeSSSBenefit estimates an SSS benefit from the contribution history.
+// Each amount in the history is rounded to its monthly salary credit with the
+// contribution table and capped at the highest credit benefits are paid on
+func (r *Rules) ComputeSSSBenefit(in SSSBenefitInputs) (SSSBenefitStatement, error) {
+	if _, err := ParseSSSBenefit(string(in.Benefit)); err != nil {
+		return SSSBenefitStatement{}, err
+	}
+	if in.Contingency.IsZero() {
+		return SSSBenefitStatement{}, errors.New("contingency date is missing")
+	}
+	if in.SickDays < 0 {
+		return SSSBenefitStatement{}, errors.New("days of confinement cannot be negative")
+	}
+	credits, err := r.benefitCredits(in.History)
+	if err != nil {
+		return SSSBenefitStatement{}, err
+	}
+
+	st := SSSBenefitStatement{Benefit: in.Benefit, Required: 3}
+	contingency := monthOf(in.Contingency)
+	if in.Benefit == UnemploymentBenefit {
+		/* RA 11199: at least 36 monthly contributions, 12 of them in the
+		18 months before the month of involuntary separation */
+		st.QualifyingFrom, st.QualifyingTo = contingency.AddDate(0, -18, 0), contingency.AddDate(0, -1, 0)
+		st.Required = 12
+	} else {
+		// The 12 months before the semester must include at least 3 monthly contributions
+		st.SemesterFrom, st.SemesterTo = semesterOf(contingency)
+		st.QualifyingFrom, st.QualifyingTo = st.SemesterFrom.AddDate(0, -12, 0), st.SemesterFrom.AddDate(0, -1, 0)
+	}
+	qualifying := between(credits, st.QualifyingFrom, st.QualifyingTo)
+	st.Contributions = len(qualifying)
+
+	switch {
+	case st.Contributions < st.Required:
+		st.Reason = fmt.Sprintf("%d contributions in the qualifying period, %d needed", st.Contributions, st.Required)
+	case in.Benefit == UnemploymentBenefit && len(before(credits, contingency)) < 36:
+		st.Reason = fmt.Sprintf("%d contributions before the separation, 36 needed", len(before(credits, contingency)))
+	case in.Benefit == SicknessBenefit && in.SickDays < SicknessMinDays:
+		st.Reason = fmt.Sprintf("confinement of %d days, at least %d needed", in.SickDays, SicknessMinDays)
+	default:
+		st.Eligible = true
+	}
+
+	var divisor decimal.Decimal
+	switch in.Benefit {
+	case UnemploymentBenefit:
+		// Average of the last 12 salary credits before the separation
+		st.Credits = latest(before(credits, contingency), 12)
+		divisor = decimal.NewFromInt(12)
+		st.Rate, st.Periods = UnemploymentRate, UnemploymentMonths
+	default:
+		// Sum of the six highest salary credits in the qualifying period over 180 days
+		st.Credits = highest(qualifying, 6)
+		divisor = decimal.NewFromInt(180)
+		st.Rate, st.Periods = decimal.NewFromInt(1), MaternityDays
+		if in.Miscarriage {
+			st.Periods = MiscarriageDays
+		}
+		if in.Benefit == SicknessBenefit {
+			st.Rate, st.Periods = SicknessRate, in.SickDays
+			if st.Periods > SicknessMaxDays {
+				st.Periods = SicknessMaxDays
+			}
+		}
+	}
+	// The average is shown rounded, the amount is computed on the exact average
+	total := sumCredits(st.Credits)
+	st.Average = total.Div(divisor).Round(2)
+	if st.Eligible {
+		st.Amount = total.Mul(st.Rate).Mul(decimal.NewFromInt(int64(st.Periods))).Div(divisor).Round(2)
+	}
+	return st, nil
+}
+
+// Trace lists how the estimate was arrived at
+func (st SSSBenefitStatement) Trace() Trace {
+	t := Trace{Name: st.Benefit.String()}
+	rule := "Paid in " + monthRange(st.QualifyingFrom, st.QualifyingTo)
+	if !st.SemesterFrom.IsZero() {
+		rule += ", semester " + monthRange(st.SemesterFrom, st.SemesterTo)
+	}
+	t.add(Step{
+		Rule:   fmt.Sprintf("%s, %d needed", rule, st.Required),
+		Amount: decimal.NewFromInt(int64(st.Contributions)),
+	})
+	if !st.Eligible {
+		t.add(Step{Rule: "Not eligible, " + st.Reason})
+		return t
+	}
+
+	unit, over := "days", "180 days"
+	if st.Benefit == UnemploymentBenefit {
+		unit, over = "months", "12 months"
+	}
+	t.add(Step{Rule: fmt.Sprintf("Total of %d salary credits", len(st.Credits)), Amount: sumCredits(st.Credits)})
+	t.add(Step{Rule: "Average salary credit, the total over " + over, Amount: st.Average})
+	t.Result = st.Amount
+	t.add(Step{
+		Rule:   fmt.Sprintf("%s of the average for %d %s", percent(st.Rate), st.Periods, unit),
+		Amount: st.Amount,
+		Rate:   st.Rate,
+	})
+	return t
+}
+
+// benefitCredits turns a contribution history into salary credits capped at
+// the benefit maximum, sorted by month
+func (r *Rules) benefitCredits(history []SSSContribution) ([]SSSContribution, error) {
+	var errs []error
+	seen := map[time.Time]bool{}
+	credits := make([]SSSContribution, 0, len(history))
+	for _, c := range history {
+		month := monthOf(c.Month)
+		switch {
+		case c.Amount.IsNegative():
+			errs = append(errs, fmt.Errorf("%s: amount cannot be negative", month.Format("2006-01")))
+		case seen[month]:
+			errs = append(errs, fmt.Errorf("%s: month is listed twice", month.Format("2006-01")))
+		}
+		seen[month] = true
+		if c.Amount.IsPositive() {
+			credit := decimal.Min(r.SSSSalaryCredit(c.Amount), r.SSS.BenefitMaxCredit)
+			credits = append(credits, SSSContribution{Month: month, Amount: credit})
+		}
+	}
+	sort.Slice(credits, func(i, j int) bool { return credits[i].Month.Before(credits[j].Month) })
+	return credits, errors.Join(errs...)
+}
+
+// semesterOf returns the first days of the first and last months of the
+// semester of contingency, the quarter of the contingency and the quarter before it
+func semesterOf(contingency time.Time) (from, to time.Time) {
+	quarter := time.Date(contingency.Year(), (contingency.Month()-1)/3*3+1, 1, 0, 0, 0, 0, time.UTC)
+	return quarter.AddDate(0, -3, 0), quarter.AddDate(0, 2, 0)
+}
+
+// monthOf returns the first day of the month of t
+func monthOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// monthRange names a range of months, e.g. "Jan 2023-Jun 2023"
+func monthRange(from, to time.Time) string {
+	return from.Format("Jan 2006") + "-" + to.Format("Jan 2006")
+}
+
+// between returns the credits of the months from and to, inclusive
+func between(credits []SSSContribution, from, to time.Time) []SSSContribution {
+	var in []SSSContribution
+	for _, c := range credits {
+		if !c.Month.Before(from) && !c.Month.After(to) {
+			in = append(in, c)
+		}
+	}
+	return in
+}
+
+// before returns the credits of the months before the given month
+func before(credits []SSSContribution, month time.Time) []SSSContribution {
+	var in []SSSContribution
+	for _, c := range credits {
+		if c.Month.Before(month) {
+			in = append(in, c)
+		}
+	}
+	return in
+}
+
+// highest returns the n highest credits
+func highest(credits []SSSContribution, n int) []SSSContribution {
+	sorted := append([]SSSContribution(nil), credits...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Amount.GreaterThan(sorted[j].Amount) })
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// latest returns the last n credits of a history sorted by month
+func latest(credits []SSSContribution, n int) []SSSContribution {
+	if len(credits) > n {
+		return credits[len(credits)-n:]
+	}
+	return credits
+}
+
+func sumCredits(credits []SSSContribution) decimal.Decimal {
+	sum := decimal.Zero
+	for _, c := range credits {
+		sum = sum.Add(c.Amount)
+	}
+	return sum
+}
@@ -0,0 +1,99 @@
+package payroll
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// maxedOut is five years of contributions up to May 2023 on a compensation
+// well above the 20,000 credit benefits are paid on
+var maxedOut = SteadySSSHistory(decimal.NewFromInt(50000), month(2023, 5), 60)
+
+func TestComputeSSSBenefit(t *testing.T) {
+	june := month(2023, 6)
+	t.Run("maternity", func(t *testing.T) {
+		// 105 days of the 20,000 credit averaged over 30 days
+		st, err := ComputeSSSBenefit(SSSBenefitInputs{Benefit: MaternityBenefit, Contingency: june, History: maxedOut})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !st.Eligible || st.Periods != 105 || st.Amount.StringFixed(2) != "70000.00" {
+			t.Errorf("eligible %v, %d days, %s, want 105 days of 70000.00 (%s)", st.Eligible, st.Periods, st.Amount.StringFixed(2), st.Reason)
+		}
+	})
+	t.Run("miscarriage", func(t *testing.T) {
+		st, err := ComputeSSSBenefit(SSSBenefitInputs{Benefit: MaternityBenefit, Contingency: june, History: maxedOut, Miscarriage: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if st.Periods != 60 || st.Amount.StringFixed(2) != "40000.00" {
+			t.Errorf("%d days, %s, want 60 days of 40000.00", st.Periods, st.Amount.StringFixed(2))
+		}
+	})
+	t.Run("sickness", func(t *testing.T) {
+		for days, want := range map[int]string{10: "6000.00", 3: "0.00"} {
+			st, err := ComputeSSSBenefit(SSSBenefitInputs{Benefit: SicknessBenefit, Contingency: june, History: maxedOut, SickDays: days})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := st.Amount.StringFixed(2); got != want || st.Eligible != (days > 3) {
+				t.Errorf("%d sick days: eligible %v, %s, want %s", days, st.Eligible, got, want)
+			}
+		}
+	})
+	t.Run("unemployment", func(t *testing.T) {
+		// Two months of half the average monthly credit
+		st, err := ComputeSSSBenefit(SSSBenefitInputs{Benefit: UnemploymentBenefit, Contingency: june, History: maxedOut})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !st.Eligible || st.Periods != UnemploymentMonths || st.Amount.StringFixed(2) != "20000.00" {
+			t.Errorf("eligible %v, %d months, %s, want 20000.00", st.Eligible, st.Periods, st.Amount.StringFixed(2))
+		}
+	})
+	t.Run("too few contributions", func(t *testing.T) {
+		short := SteadySSSHistory(decimal.NewFromInt(20000), month(2022, 12), 2)
+		st, err := ComputeSSSBenefit(SSSBenefitInputs{Benefit: MaternityBenefit, Contingency: june, History: short})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if st.Eligible || !st.Amount.IsZero() || st.Reason == "" {
+			t.Errorf("eligible %v, %s, reason %q", st.Eligible, st.Amount, st.Reason)
+		}
+	})
+	t.Run("no contingency", func(t *testing.T) {
+		if _, err := ComputeSSSBenefit(SSSBenefitInputs{Benefit: SicknessBenefit, History: maxedOut, SickDays: 10}); err == nil {
+			t.Error("a claim without a date was accepted")
+		}
+	})
+}
+
+func TestParseSSSHistory(t *testing.T) {
+	history, err := ParseSSSHistory(strings.NewReader("month,amount\n# posted\n2023-01,\"20,000\"\n\n2023-02, 19500.50\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || !history[0].Month.Equal(month(2023, 1)) ||
+		history[0].Amount.String() != "20000" || history[1].Amount.String() != "19500.5" {
+		t.Errorf("got %+v", history)
+	}
+
+	for _, bad := range []string{"2023-01,20000\nmonth,amount", "2023-01,100\n2023-13,100", "2023-01,lots", "2023-01"} {
+		if _, err := ParseSSSHistory(strings.NewReader(bad)); err == nil {
+			t.Errorf("%q was accepted", bad)
+		}
+	}
+}
+
+func TestParseSSSBenefit(t *testing.T) {
+	for _, s := range []string{"sickness", "Sickness Benefit"} {
+		if b, err := ParseSSSBenefit(s); err != nil || b != SicknessBenefit {
+			t.Errorf("%q gave %q, %v", s, b, err)
+		}
+	}
+	if _, err := ParseSSSBenefit("funeral"); err == nil {
+		t.Error("funeral was accepted")
+	}
+}
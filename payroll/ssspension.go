@@ -0,0 +1,188 @@
+package payroll
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// PensionMinContributions is the monthly contributions needed for a monthly
+	// pension, members with fewer get a lump sum instead
+	PensionMinContributions = 120
+	// PensionMaxDependents is the most dependent children a dependents' allowance is paid for
+	PensionMaxDependents = 5
+)
+
+var (
+	// PensionIncrease is the across-the-board increase added to every monthly pension since 2017
+	PensionIncrease = decimal.NewFromInt(1000)
+	// MinimumDependentAllowance is the least allowance paid for each dependent child
+	MinimumDependentAllowance = decimal.NewFromInt(250)
+)
+
+// SSSPensionInputs is a retiring member's contribution history and service
+type SSSPensionInputs struct {
+	History        []SSSContribution
+	CreditedYears  int       // calendar years with at least six monthly contributions, counted from the history when zero
+	Dependents     int       // children under 21, up to five are counted
+	RetirementDate time.Time // contributions from the semester of retirement on are not counted
+}
+
+// SSSPensionStatement is the result of ComputeSSSPension
+type SSSPensionStatement struct {
+	Contributions int // paid before the semester of retirement
+	Eligible      bool
+	Reason        string
+
+	// Average monthly salary credit, the higher of the average of the last 60
+	// salary credits and that of all of them
+	AverageLast60  decimal.Decimal
+	AverageAll     decimal.Decimal
+	AverageCredit  decimal.Decimal
+	CreditedYears  int
+	FormulaA       decimal.Decimal // 300 plus 20% of the average plus 2% of it for each year over 10
+	FormulaB       decimal.Decimal // 40% of the average
+	FormulaC       decimal.Decimal // minimum pension of 1,200, or 2,400 with 20 years
+	BasicPension   decimal.Decimal // the greatest of the three
+	Increase       decimal.Decimal
+	Dependents     int
+	DependentsPay  decimal.Decimal // dependents' allowance
+	MonthlyPension decimal.Decimal
+	ThirteenthPay  decimal.Decimal // 13th month pension paid every December
+	Annual         decimal.Decimal
+}
+
+// ComputeSSSPension estimates the SSS monthly retirement pension under the current rules
+func ComputeSSSPension(in SSSPensionInputs) (SSSPensionStatement, error) {
+	return Current().ComputeSSSPension(in)
+}
+
+// ComputeSSSPension estimates the SSS monthly retirement pension. The history
+// is rounded to salary credits and capped as for the other benefits
+func (r *Rules) ComputeSSSPension(in SSSPensionInputs) (SSSPensionStatement, error) {
+	switch {
+	case in.RetirementDate.IsZero():
+		return SSSPensionStatement{}, errors.New("retirement date is missing")
+	case in.CreditedYears < 0:
+		return SSSPensionStatement{}, errors.New("credited years of service cannot be negative")
+	case in.Dependents < 0:
+		return SSSPensionStatement{}, errors.New("dependents cannot be negative")
+	}
+	credits, err := r.benefitCredits(in.History)
+	if err != nil {
+		return SSSPensionStatement{}, err
+	}
+
+	semester, _ := semesterOf(monthOf(in.RetirementDate))
+	credits = before(credits, semester)
+
+	// The history shows at least this many credited years, it may leave out earlier ones
+	counted := creditedYears(credits)
+	years := in.CreditedYears
+	switch {
+	case years == 0:
+		years = counted
+	case years < counted:
+		return SSSPensionStatement{}, fmt.Errorf("%d credited years given but the history has %d years with six or more contributions",
+			years, counted)
+	}
+	st := SSSPensionStatement{Contributions: len(credits), CreditedYears: years}
+	if st.Contributions < PensionMinContributions {
+		st.Reason = fmt.Sprintf("%d contributions before the semester of retirement, %d needed for a monthly pension, a lump sum is paid instead",
+			st.Contributions, PensionMinContributions)
+		return st, nil
+	}
+	st.Eligible = true
+
+	last60 := latest(credits, 60)
+	st.AverageLast60 = sumCredits(last60).Div(decimal.NewFromInt(60)).Round(2)
+	st.AverageAll = sumCredits(credits).Div(decimal.NewFromInt(int64(len(credits)))).Round(2)
+	st.AverageCredit = decimal.Max(st.AverageLast60, st.AverageAll)
+
+	/* RA 8282: the monthly pension is the highest of
+	a) 300 + 20% of the AMSC + 2% of the AMSC for each credited year over 10
+	b) 40% of the AMSC
+	c) 1,200, or 2,400 for 20 credited years or more */
+	amsc := st.AverageCredit
+	over := decimal.NewFromInt(int64(yearsOver10(years)))
+	st.FormulaA = decimal.NewFromInt(300).
+		Add(amsc.Mul(decimal.NewFromFloat(0.2))).
+		Add(amsc.Mul(decimal.NewFromFloat(0.02)).Mul(over)).Round(2)
+	st.FormulaB = amsc.Mul(decimal.NewFromFloat(0.4)).Round(2)
+	switch {
+	case years >= 20:
+		st.FormulaC = decimal.NewFromInt(2400)
+	case years >= 10:
+		st.FormulaC = decimal.NewFromInt(1200)
+	}
+	st.BasicPension = decimal.Max(st.FormulaA, st.FormulaB, st.FormulaC)
+	st.Increase = PensionIncrease
+
+	// 10% of the basic pension or 250, whichever is higher, for each dependent child
+	st.Dependents = in.Dependents
+	if st.Dependents > PensionMaxDependents {
+		st.Dependents = PensionMaxDependents
+	}
+	perChild := decimal.Max(st.BasicPension.Mul(decimal.NewFromFloat(0.1)), MinimumDependentAllowance).Round(2)
+	st.DependentsPay = perChild.Mul(decimal.NewFromInt(int64(st.Dependents)))
+
+	st.MonthlyPension = decimal.Sum(st.BasicPension, st.Increase, st.DependentsPay)
+	st.ThirteenthPay = st.BasicPension.Add(st.Increase)
+	st.Annual = st.MonthlyPension.Mul(decimal.NewFromInt(12)).Add(st.ThirteenthPay)
+	return st, nil
+}
+
+// Trace lists how the pension was arrived at
+func (st SSSPensionStatement) Trace() Trace {
+	t := Trace{Name: "Retirement Pension"}
+	t.add(Step{
+		Rule:   fmt.Sprintf("Contributions before the semester of retirement, %d needed", PensionMinContributions),
+		Amount: decimal.NewFromInt(int64(st.Contributions)),
+	})
+	if !st.Eligible {
+		t.add(Step{Rule: "No monthly pension, a lump sum is paid instead"})
+		return t
+	}
+	t.add(Step{Rule: "Average of the last 60 salary credits", Amount: st.AverageLast60})
+	t.add(Step{Rule: "Average of all salary credits", Amount: st.AverageAll})
+	t.add(Step{Rule: "Average monthly salary credit, the higher", Amount: st.AverageCredit})
+	t.add(Step{Rule: fmt.Sprintf("300 + 20%% of average + 2%% for each of %d years over 10", yearsOver10(st.CreditedYears)),
+		Amount: st.FormulaA})
+	t.add(Step{Rule: "40% of average", Amount: st.FormulaB})
+	t.add(Step{Rule: fmt.Sprintf("Minimum pension for %d credited years", st.CreditedYears), Amount: st.FormulaC})
+	t.add(Step{Rule: "Basic monthly pension, the highest", Amount: st.BasicPension})
+	t.add(Step{Rule: "Across-the-board increase", Amount: st.Increase})
+	if st.Dependents > 0 {
+		t.add(Step{Rule: fmt.Sprintf("Dependents' allowance for %d children", st.Dependents), Amount: st.DependentsPay})
+	}
+	t.add(Step{Rule: "13th month pension in December", Amount: st.ThirteenthPay})
+	t.add(Step{Rule: "Pension for a year", Amount: st.Annual})
+	t.Result = st.MonthlyPension
+	return t
+}
+
+// creditedYears counts the calendar years with six or more monthly contributions
+func creditedYears(credits []SSSContribution) int {
+	perYear := map[int]int{}
+	for _, c := range credits {
+		perYear[c.Month.Year()]++
+	}
+	years := 0
+	for _, n := range perYear {
+		if n >= 6 {
+			years++
+		}
+	}
+	return years
+}
+
+// yearsOver10 is the credited years beyond the first ten
+func yearsOver10(creditedYears int) int {
+	if creditedYears < 10 {
+		return 0
+	}
+	return creditedYears - 10
+}
@@ -0,0 +1,65 @@
+package payroll
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// twentyYears is 240 contributions on a 25,000 compensation up to December
+// 2022, a member retiring on June 15, 2023
+var (
+	twentyYears = SteadySSSHistory(decimal.NewFromInt(25000), month(2022, 12), 240)
+	retiring    = time.Date(2023, 6, 15, 0, 0, 0, 0, time.UTC)
+)
+
+// pension computes the pension of in and fails the test on an error
+func pension(t *testing.T, in SSSPensionInputs) SSSPensionStatement {
+	t.Helper()
+	st, err := ComputeSSSPension(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return st
+}
+
+func TestComputeSSSPension(t *testing.T) {
+	// Formula A on the 20,000 benefit ceiling: 300 + 4,000 + 2% of it for
+	// each of the 10 years over 10, with the 1,000 increase on top
+	st := pension(t, SSSPensionInputs{History: twentyYears, RetirementDate: retiring})
+	if !st.Eligible || st.CreditedYears != 20 {
+		t.Fatalf("eligible %v with %d years (%s)", st.Eligible, st.CreditedYears, st.Reason)
+	}
+	if st.FormulaA.StringFixed(2) != "8300.00" || st.MonthlyPension.StringFixed(2) != "9300.00" {
+		t.Errorf("formula A %s, pension %s, want 8300.00 and 9300.00",
+			st.FormulaA.StringFixed(2), st.MonthlyPension.StringFixed(2))
+	}
+}
+
+func TestComputeSSSPensionDependents(t *testing.T) {
+	st := pension(t, SSSPensionInputs{History: twentyYears, RetirementDate: retiring, Dependents: 2})
+	if st.MonthlyPension.StringFixed(2) != "10960.00" {
+		t.Errorf("pension with two dependents %s, want 10960.00", st.MonthlyPension.StringFixed(2))
+	}
+}
+
+func TestComputeSSSPensionCreditedYears(t *testing.T) {
+	// Service the history file doesn't reach back to counts when given
+	st := pension(t, SSSPensionInputs{History: twentyYears, RetirementDate: retiring, CreditedYears: 25})
+	if st.CreditedYears != 25 || st.FormulaA.StringFixed(2) != "10300.00" || st.MonthlyPension.StringFixed(2) != "11300.00" {
+		t.Errorf("%d years, formula A %s, pension %s", st.CreditedYears, st.FormulaA, st.MonthlyPension)
+	}
+
+	if _, err := ComputeSSSPension(SSSPensionInputs{History: twentyYears, RetirementDate: retiring, CreditedYears: 10}); err == nil {
+		t.Error("fewer credited years than the history shows were accepted")
+	}
+}
+
+func TestComputeSSSPensionLumpSum(t *testing.T) {
+	// 100 contributions fall short of the 120 a monthly pension needs
+	st := pension(t, SSSPensionInputs{History: twentyYears[:100], RetirementDate: retiring})
+	if st.Eligible || !st.MonthlyPension.IsZero() || st.Reason == "" {
+		t.Errorf("eligible %v, pension %s, reason %q", st.Eligible, st.MonthlyPension, st.Reason)
+	}
+}
@@ -0,0 +1,71 @@
+package payroll
+
+import "github.com/shopspring/decimal"
+
+// Tables describes every rate table the calculators apply
+type Tables struct {
+	Version         string
+	Year            int
+	Source          string
+	Tax             []TaxBracket
+	AnnualTax       []TaxBracket
+	FringeRate      decimal.Decimal // fringe benefits tax on the grossed-up value
+	SSS             []SSSRange
+	SSSEmployeeRate decimal.Decimal
+	SSSEmployerRate decimal.Decimal
+	SSSMembers      []SSSMemberCredit // salary credit floors of self-paying members
+	PhilHealth      PhilHealthTable
+	PagIbig         PagIbigTable
+	Kasambahay      KasambahayRules
+}
+
+// CurrentTables returns the tables in effect
+func CurrentTables() Tables { return Current().Tables() }
+
+// Tables lists every table of these rules
+func (r *Rules) Tables() Tables {
+	return Tables{
+		Version:         r.Version,
+		Year:            r.Year,
+		Source:          r.Source,
+		Tax:             r.MonthlyTaxTable(),
+		AnnualTax:       bracketTable(r.annualBrackets, r.annualRates),
+		FringeRate:      r.FringeBenefitRate,
+		SSS:             r.SSSTable(),
+		SSSEmployeeRate: r.SSS.EmployeeRate,
+		SSSEmployerRate: r.SSS.EmployerRate,
+		SSSMembers:      r.SSS.Members,
+		PhilHealth:      r.PhilHealth,
+		PagIbig:         r.PagIbig,
+		Kasambahay:      r.Kasambahay,
+	}
+}
+
+// MonthlyTaxTable lists the brackets of the monthly withholding tax table
+func MonthlyTaxTable() []TaxBracket { return Current().MonthlyTaxTable() }
+
+// MonthlyTaxTable lists the brackets of these rules' monthly table
+func (r *Rules) MonthlyTaxTable() []TaxBracket {
+	return bracketTable(r.monthlyBrackets, r.monthlyRates)
+}
+
+func bracketTable(brackets, rates []decimal.Decimal) []TaxBracket {
+	table := []TaxBracket{findBracket(decimal.Zero, brackets, rates)}
+	for _, b := range brackets {
+		// the first peso above each threshold lands in the next bracket
+		table = append(table, findBracket(b.Add(decimal.NewFromInt(1)), brackets, rates))
+	}
+	return table
+}
+
+// SSSTable lists every compensation range of the SSS table
+func SSSTable() []SSSRange { return Current().SSSTable() }
+
+// SSSTable lists every compensation range of these rules' SSS table
+func (r *Rules) SSSTable() []SSSRange {
+	var table []SSSRange
+	for credit := r.SSS.MinCredit; credit.LessThanOrEqual(r.SSS.MaxCredit); credit = credit.Add(r.SSS.Step) {
+		table = append(table, r.SSSRangeOf(credit))
+	}
+	return table
+}
@@ -0,0 +1,170 @@
+// Package payroll holds the tax and contribution computations shared by the
+// desktop app and the other front ends.
+package payroll
+
+import "github.com/shopspring/decimal"
+
+// TaxInputs holds all variables needed
+// to hold computational results and inputs
+type TaxInputs struct {
+	MonthlyIncome           decimal.Decimal
+	TaxableIncome           decimal.Decimal
+	Tax                     decimal.Decimal
+	NetPayAfterTax          decimal.Decimal
+	SSSContributions        decimal.Decimal
+	PhilHealthContributions decimal.Decimal
+	PagIbigContributions    decimal.Decimal
+	TotalContributions      decimal.Decimal
+	TotalDeductions         decimal.Decimal
+	NetPayAfterDeductions   decimal.Decimal
+
+	// Registered deductions other than SSS, PhilHealth and Pag-IBIG, in the
+	// order they were taken. They are part of TotalDeductions
+	OtherDeductions []DeductionLine `json:",omitempty"`
+
+	// The pay by earning line, when it was computed from lines rather than a single income
+	Earnings *EarningsBreakdown `json:",omitempty"`
+}
+
+// Field is one named amount of a TaxInputs breakdown
+type Field struct {
+	Name  string
+	Value decimal.Decimal
+}
+
+// Fields lists the breakdown in the order it is displayed
+func (t TaxInputs) Fields() []Field {
+	return []Field{
+		{"Monthly Income", t.MonthlyIncome},
+		{"SSS Contribution", t.SSSContributions},
+		{"PhilHealth Contribution", t.PhilHealthContributions},
+		{"Pag-IBIG Contribution", t.PagIbigContributions},
+		{"Total Contribution", t.TotalContributions},
+		{"Taxable Income", t.TaxableIncome},
+		{"Income Tax", t.Tax},
+		{"Net Pay After Tax", t.NetPayAfterTax},
+		{"Total Deductions", t.TotalDeductions},
+		{"Net Pay After Deductions", t.NetPayAfterDeductions},
+	}
+}
+
+// Compute runs the monthly income through the contribution and tax calculators
+func Compute(monthlyIncome decimal.Decimal) TaxInputs {
+	return Current().Compute(monthlyIncome)
+}
+
+// Compute runs the monthly income through the calculators of these rules
+// and the registered deductions
+func (r *Rules) Compute(monthlyIncome decimal.Decimal) TaxInputs {
+	return r.compute(monthlyIncome, monthlyIncome, monthlyIncome, Deductions.Deductions())
+}
+
+// compute takes the deductions computed on the contribution base from the gross
+// pay, and the tax on the taxable pay less the pre-tax deductions
+func (r *Rules) compute(gross, taxable, base decimal.Decimal, deductions []Deduction) TaxInputs {
+	t := TaxInputs{MonthlyIncome: gross}
+
+	// Calling functions to calculate for monthly contributions,
+	// pre-tax deductions lower the taxable income
+	preTax, postTax := decimal.Zero, decimal.Zero
+	for _, d := range deductions {
+		amount := d.Calculator.Calculate(r, base)
+		switch d.Calculator.(type) {
+		case sssCalculator:
+			t.SSSContributions = amount
+		case philHealthCalculator:
+			t.PhilHealthContributions = amount
+		case pagIbigCalculator:
+			t.PagIbigContributions = amount
+		default:
+			t.OtherDeductions = append(t.OtherDeductions,
+				DeductionLine{Name: d.Calculator.Name(), Amount: amount, Timing: d.Timing})
+		}
+		if d.Timing == PreTax {
+			preTax = preTax.Add(amount)
+		} else {
+			postTax = postTax.Add(amount)
+		}
+	}
+	t.TotalContributions = decimal.Sum(t.SSSContributions,
+		t.PhilHealthContributions,
+		t.PagIbigContributions)
+
+	// Calling functions to calculate for tax deductions
+	t.TaxableIncome = taxable.Sub(preTax)
+	t.Tax = r.CalculateTax(t.TaxableIncome)
+	t.NetPayAfterTax = gross.Sub(t.Tax)
+	t.TotalDeductions = decimal.Sum(preTax, t.Tax, postTax)
+	t.NetPayAfterDeductions = gross.Sub(t.TotalDeductions)
+	return t
+}
+
+// CalculateTax computes the monthly withholding tax based on taxable income
+func CalculateTax(taxableIncome decimal.Decimal) decimal.Decimal {
+	return Current().CalculateTax(taxableIncome)
+}
+
+// CalculateTax computes the monthly withholding tax under these rules
+func (r *Rules) CalculateTax(taxableIncome decimal.Decimal) decimal.Decimal {
+	return graduatedTax(taxableIncome, r.monthlyBrackets, r.monthlyRates)
+}
+
+// taxTrace names the income tax trace
+const taxTrace = "Income Tax"
+
+// ExplainTax computes the monthly withholding tax and records the bracket applied
+func ExplainTax(taxableIncome decimal.Decimal) Trace {
+	return Current().ExplainTax(taxableIncome)
+}
+
+// ExplainTax traces the monthly withholding tax under these rules
+func (r *Rules) ExplainTax(taxableIncome decimal.Decimal) Trace {
+	t := Trace{Name: taxTrace}
+	t.add(Step{Rule: "Taxable income", Amount: taxableIncome})
+
+	b := r.MonthlyTaxBracket(taxableIncome)
+	t.add(Step{Rule: "Bracket: " + b.String(), Amount: b.Lower})
+	if b.Rate.IsZero() {
+		t.add(Step{Rule: "No tax on income up to " + b.Upper.StringFixed(0), Amount: decimal.Zero})
+	} else {
+		excess := taxableIncome.Sub(b.Lower)
+		t.add(Step{Rule: "Base tax on " + b.Lower.StringFixed(0), Amount: b.BaseTax})
+		t.add(Step{Rule: "Excess over " + b.Lower.StringFixed(0), Amount: excess})
+		t.add(Step{Rule: percent(b.Rate) + " of excess", Amount: excess.Mul(b.Rate), Rate: b.Rate})
+	}
+
+	t.Result = r.CalculateTax(taxableIncome)
+	return t
+}
+
+// CalculateAnnualTax computes the income tax due on a full year's taxable compensation
+func CalculateAnnualTax(taxableIncome decimal.Decimal) decimal.Decimal {
+	return Current().CalculateAnnualTax(taxableIncome)
+}
+
+// CalculateAnnualTax computes the annual income tax under these rules
+func (r *Rules) CalculateAnnualTax(taxableIncome decimal.Decimal) decimal.Decimal {
+	return graduatedTax(taxableIncome, r.annualBrackets, r.annualRates)
+}
+
+// graduatedTax applies each bracket's rate to the part of the income inside
+// it. brackets are the upper bounds of every bracket but the top one, so there
+// is one rate more than there are brackets
+func graduatedTax(taxableIncome decimal.Decimal, brackets, rates []decimal.Decimal) decimal.Decimal {
+	tax := decimal.Zero
+	lower := decimal.Zero
+	for i, rate := range rates {
+		if !taxableIncome.GreaterThan(lower) {
+			break
+		}
+		upper := taxableIncome
+		if i < len(brackets) && brackets[i].LessThan(upper) {
+			upper = brackets[i]
+		}
+		tax = tax.Add(upper.Sub(lower).Mul(rate))
+		if i < len(brackets) {
+			lower = brackets[i]
+		}
+	}
+	return tax.Round(2)
+}
@@ -0,0 +1,130 @@
+package payroll
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Step is one rule a calculator applied on the way to its result
+type Step struct {
+	Rule   string          // what was applied, e.g. which bracket or salary credit
+	Amount decimal.Decimal // the amount the rule produced
+	Rate   decimal.Decimal // rate applied to get Amount, zero if none
+	Capped bool            // a floor or ceiling of the table was hit
+}
+
+// Trace records how a calculator arrived at its result, so the
+// figures on a payslip can be explained
+type Trace struct {
+	Name   string
+	Steps  []Step
+	Result decimal.Decimal
+}
+
+func (t *Trace) add(step Step) {
+	t.Steps = append(t.Steps, step)
+}
+
+// String renders the trace as text, one step per line
+func (t Trace) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n", t.Name)
+	for _, s := range t.Steps {
+		rule := s.Rule
+		if s.Capped {
+			rule += " (limit reached)"
+		}
+		fmt.Fprintf(&b, "  %-66s %14s\n", rule, s.Amount.StringFixed(2))
+	}
+	fmt.Fprintf(&b, "  %-66s %14s\n", "= "+t.Name, t.Result.StringFixed(2))
+	return b.String()
+}
+
+// Explain traces every calculator for a monthly income, in the order they are applied
+func Explain(monthlyIncome decimal.Decimal) []Trace {
+	return Current().Explain(monthlyIncome)
+}
+
+// Explain traces every calculator of these rules and the registered deductions
+// for a monthly income: the pre-tax deductions, the income tax, then the
+// post-tax deductions
+func (r *Rules) Explain(monthlyIncome decimal.Decimal) []Trace {
+	return r.explain(monthlyIncome, monthlyIncome)
+}
+
+// explain traces the deductions on the contribution base and the tax on the
+// taxable pay less the pre-tax deductions
+func (r *Rules) explain(taxable, base decimal.Decimal) []Trace {
+	var pre, post []Trace
+	preTax := decimal.Zero
+	for _, d := range Deductions.Deductions() {
+		t := d.Calculator.Explain(r, base)
+		if d.Timing == PreTax {
+			pre = append(pre, t)
+			preTax = preTax.Add(t.Result)
+		} else {
+			post = append(post, t)
+		}
+	}
+	return append(append(pre, r.ExplainTax(taxable.Sub(preTax))), post...)
+}
+
+// ExplainWith traces the calculators for an income received every pay period,
+// on its monthly equivalent
+func ExplainWith(income decimal.Decimal, opts Options) ([]Trace, error) {
+	r, err := ForYear(opts.Year)
+	if err != nil {
+		return nil, err
+	}
+	return r.ExplainWith(income, opts)
+}
+
+// ExplainWith traces the calculators of these rules for an income received every pay period
+func (r *Rules) ExplainWith(income decimal.Decimal, opts Options) ([]Trace, error) {
+	if err := opts.validate(r); err != nil {
+		return nil, err
+	}
+	monthly := opts.PayFrequency.ToMonthly(income)
+	return r.explainOptions(r.Explain(monthly), monthly, monthly, opts), nil
+}
+
+// explainOptions adds the exemptions of the employee type to the traces
+func (r *Rules) explainOptions(traces []Trace, monthlyWage, taxable decimal.Decimal, opts Options) []Trace {
+	employerPays := opts.EmployeeType == Kasambahay && r.employerPaysAll(monthlyWage)
+	for i := range traces {
+		switch {
+		case traces[i].Name == taxTrace && opts.EmployeeType == MinimumWage:
+			traces[i].add(Step{Rule: "Minimum wage earner, exempt from income tax", Amount: decimal.Zero})
+			traces[i].Result = decimal.Zero
+		case traces[i].Name == taxTrace && opts.EmployeeType == Kasambahay:
+			if employerPays {
+				// No contributions are deducted, so the whole wage is taxable, and exempt
+				traces[i] = r.ExplainTax(taxable)
+			}
+			traces[i].add(Step{Rule: "Kasambahay, exempt from income tax", Amount: decimal.Zero})
+			traces[i].Result = decimal.Zero
+		case employerPays && isContribution(traces[i].Name):
+			traces[i].add(Step{Rule: "Wage below " + r.Kasambahay.EmployerPaysBelow.StringFixed(0) +
+				", paid in full by the employer", Amount: decimal.Zero})
+			traces[i].Result = decimal.Zero
+		}
+	}
+	return traces
+}
+
+// isContribution tells the SSS, PhilHealth and Pag-IBIG traces from company deductions
+func isContribution(name string) bool {
+	for _, c := range []ContributionCalculator{SSSCalculator, PhilHealthCalculator, PagIbigCalculator} {
+		if c.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// percent formats a rate such as 0.045 as 4.5%
+func percent(rate decimal.Decimal) string {
+	return rate.Mul(decimal.NewFromInt(100)).String() + "%"
+}
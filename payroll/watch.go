@@ -0,0 +1,61 @@
+package payroll
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Editors save a file in several steps, wait for them to settle before reloading
+const reloadDelay = 300 * time.Millisecond
+
+// WatchRules reloads the rules whenever a rule file in dir is created, changed,
+// renamed or removed. Tables that validate replace the ones in use at once, so
+// every computation sees either the old tables or the new ones. If any file is
+// invalid the tables in use are kept. Each reload is passed to report, with the
+// new rules or the reason they were rejected. The returned stop function ends
+// the watch
+func WatchRules(dir string, report func(*RuleSet, error)) (stop func() error, err error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := w.Add(dir); err != nil {
+		w.Close()
+		return nil, err
+	}
+
+	go func() {
+		timer := time.NewTimer(reloadDelay)
+		timer.Stop()
+		for {
+			select {
+			case e, ok := <-w.Events:
+				if !ok {
+					timer.Stop()
+					return
+				}
+				if isRulesFile(filepath.Base(e.Name)) {
+					timer.Reset(reloadDelay)
+				}
+			case err, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+				report(nil, err)
+			case <-timer.C:
+				set, err := LoadRules(dir)
+				if err == nil {
+					Use(set)
+				}
+				report(set, err)
+			}
+		}
+	}()
+	return w.Close, nil
+}
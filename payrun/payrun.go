@@ -0,0 +1,261 @@
+// Package payrun runs payroll for a cutoff period as a controlled process:
+// compute, review, approve and lock, with an audit trail of every change.
+package payrun
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"runfyne/employee"
+	"runfyne/loan"
+	"runfyne/payroll"
+)
+
+// Status is where a run is in the approval process
+type Status string
+
+const (
+	Draft    Status = "draft"
+	Approved Status = "approved"
+	Locked   Status = "locked"
+)
+
+// Line is the computation for one employee in a run
+type Line struct {
+	EmployeeID int
+	Name       string
+	payroll.TaxInputs
+
+	// Loan installments are taken after NetPayAfterDeductions, what is left is TakeHomePay
+	Loans       []LoanDeduction `json:",omitempty"`
+	TakeHomePay decimal.Decimal
+}
+
+// LoanDeduction is the installment of one loan taken in a run
+type LoanDeduction struct {
+	LoanID      int
+	Description string
+	Amount      decimal.Decimal
+}
+
+// Totals adds up every line of a run
+type Totals struct {
+	Employees               int
+	GrossPay                decimal.Decimal
+	SSSContributions        decimal.Decimal
+	PhilHealthContributions decimal.Decimal
+	PagIbigContributions    decimal.Decimal
+	Tax                     decimal.Decimal
+	TotalDeductions         decimal.Decimal
+	NetPay                  decimal.Decimal
+	LoanDeductions          decimal.Decimal
+	TakeHomePay             decimal.Decimal
+}
+
+// AuditEntry records who did what to a run and when
+type AuditEntry struct {
+	Time   time.Time
+	User   string
+	Action string
+	Detail string
+}
+
+// Run is the payroll for one cutoff period and pay frequency
+type Run struct {
+	ID           int
+	PeriodStart  time.Time
+	PeriodEnd    time.Time
+	PayFrequency payroll.PayFrequency
+	Status       Status
+	Rules        string `json:",omitempty"` // version of the rules the lines were computed with
+	Lines        []Line
+	Totals       Totals
+	Audit        []AuditEntry
+}
+
+var (
+	// ErrLocked is returned when a change is attempted on a run that is not a draft
+	ErrLocked = errors.New("run is not a draft, reopen it first")
+	// ErrNotComputed is returned when approving a run with nothing computed
+	ErrNotComputed = errors.New("run has not been computed")
+	// ErrOverlap is returned when saving a run whose cutoff overlaps another run's
+	ErrOverlap = errors.New("cutoff overlaps another run")
+)
+
+// New starts a draft run for the cutoff period
+func New(start, end time.Time, freq payroll.PayFrequency, user string) (*Run, error) {
+	if end.Before(start) {
+		return nil, errors.New("cutoff end must not be before its start")
+	}
+	if _, err := payroll.ParsePayFrequency(string(freq)); err != nil {
+		return nil, err
+	}
+
+	r := &Run{PeriodStart: start, PeriodEnd: end, PayFrequency: freq, Status: Draft}
+	r.log(user, "created", fmt.Sprintf("%s cutoff %s to %s", freq,
+		start.Format("2006-01-02"), end.Format("2006-01-02")))
+	return r, nil
+}
+
+// Compute runs every employee active during the cutoff with the run's pay frequency
+// through the calculators, then takes the installments of their loans from the
+// net pay. Every line is computed with the same rules, the current ones, and
+// nothing changes if any employee cannot be computed. Recomputing logs every
+// line whose take-home pay changed
+func (r *Run) Compute(employees []employee.Employee, loans []loan.Loan, user string) error {
+	if r.Status != Draft {
+		return ErrLocked
+	}
+	rules := payroll.Current()
+
+	previous := make(map[int]Line, len(r.Lines))
+	for _, l := range r.Lines {
+		previous[l.EmployeeID] = l
+	}
+
+	var lines []Line
+	var errs []error
+	for _, e := range employees {
+		if e.PayFrequency != r.PayFrequency || !e.ActiveDuring(r.PeriodStart, r.PeriodEnd) {
+			continue
+		}
+		opts := e.Options()
+		opts.Year = rules.Year
+		pay, err := rules.ComputeSalaryWith(e.MonthlySalary, opts)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", e.Name, err))
+			continue
+		}
+		line := Line{EmployeeID: e.ID, Name: e.Name, TaxInputs: pay}
+		line.deductLoans(loans, r.PayFrequency, r.PeriodEnd)
+		lines = append(lines, line)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	// Only recomputations are worth logging line by line
+	for _, line := range lines {
+		if len(r.Lines) == 0 {
+			break
+		}
+		old, ok := previous[line.EmployeeID]
+		delete(previous, line.EmployeeID)
+		if !ok {
+			r.log(user, "added", fmt.Sprintf("%s, take-home pay %s", line.Name, line.TakeHomePay.StringFixed(2)))
+		} else if !old.TakeHomePay.Equal(line.TakeHomePay) {
+			r.log(user, "changed", fmt.Sprintf("%s, take-home pay %s -> %s", line.Name,
+				old.TakeHomePay.StringFixed(2), line.TakeHomePay.StringFixed(2)))
+		}
+	}
+	for _, old := range previous {
+		r.log(user, "removed", old.Name)
+	}
+
+	r.Lines = lines
+	r.Totals = total(lines)
+	r.Rules = rules.Version
+	r.log(user, "computed", fmt.Sprintf("%d employees, net pay %s, take-home pay %s, rules %s",
+		r.Totals.Employees, r.Totals.NetPay.StringFixed(2), r.Totals.TakeHomePay.StringFixed(2), rules))
+	log.Printf("payroll run %d computed with rules %s", r.ID, rules)
+	return nil
+}
+
+// deductLoans takes the installments due on the employee's loans, oldest loan
+// first, from the net pay. An installment that does not fit in what is left of
+// the pay is cut short, the rest stays in the balance
+func (l *Line) deductLoans(loans []loan.Loan, freq payroll.PayFrequency, periodEnd time.Time) {
+	left := decimal.Max(decimal.Zero, l.NetPayAfterDeductions)
+	for _, ln := range loans {
+		if ln.EmployeeID != l.EmployeeID {
+			continue
+		}
+		due := decimal.Min(ln.Due(freq, periodEnd), left)
+		if !due.IsPositive() {
+			continue
+		}
+		l.Loans = append(l.Loans, LoanDeduction{LoanID: ln.ID, Description: ln.Label(), Amount: due})
+		left = left.Sub(due)
+	}
+	l.TakeHomePay = l.NetPayAfterDeductions.Sub(l.LoanDeductions())
+}
+
+// LoanDeductions is the sum of the line's loan installments
+func (l Line) LoanDeductions() decimal.Decimal {
+	total := decimal.Zero
+	for _, d := range l.Loans {
+		total = total.Add(d.Amount)
+	}
+	return total
+}
+
+// LoanPayments lists the installments taken in the run by loan ID, to be
+// recorded against the balances once the run is locked
+func (r *Run) LoanPayments() map[int]decimal.Decimal {
+	payments := map[int]decimal.Decimal{}
+	for _, l := range r.Lines {
+		for _, d := range l.Loans {
+			payments[d.LoanID] = payments[d.LoanID].Add(d.Amount)
+		}
+	}
+	return payments
+}
+
+// Approve marks a computed draft as reviewed
+func (r *Run) Approve(user string) error {
+	if r.Status != Draft {
+		return ErrLocked
+	}
+	if len(r.Lines) == 0 {
+		return ErrNotComputed
+	}
+	r.Status = Approved
+	r.log(user, "approved", "")
+	return nil
+}
+
+// Lock freezes an approved run so its figures can no longer change
+func (r *Run) Lock(user string) error {
+	if r.Status != Approved {
+		return errors.New("only approved runs can be locked")
+	}
+	r.Status = Locked
+	r.log(user, "locked", "")
+	return nil
+}
+
+// Reopen returns an approved or locked run to draft, the reason is kept in the audit trail
+func (r *Run) Reopen(user, reason string) error {
+	if r.Status == Draft {
+		return errors.New("run is already a draft")
+	}
+	if reason == "" {
+		return errors.New("a reason is required to reopen a run")
+	}
+	r.Status = Draft
+	r.log(user, "reopened", reason)
+	return nil
+}
+
+func (r *Run) log(user, action, detail string) {
+	r.Audit = append(r.Audit, AuditEntry{Time: time.Now(), User: user, Action: action, Detail: detail})
+}
+
+func total(lines []Line) Totals {
+	t := Totals{Employees: len(lines)}
+	for _, l := range lines {
+		t.GrossPay = t.GrossPay.Add(l.MonthlyIncome)
+		t.SSSContributions = t.SSSContributions.Add(l.SSSContributions)
+		t.PhilHealthContributions = t.PhilHealthContributions.Add(l.PhilHealthContributions)
+		t.PagIbigContributions = t.PagIbigContributions.Add(l.PagIbigContributions)
+		t.Tax = t.Tax.Add(l.Tax)
+		t.TotalDeductions = t.TotalDeductions.Add(l.TotalDeductions)
+		t.NetPay = t.NetPay.Add(l.NetPayAfterDeductions)
+		t.LoanDeductions = t.LoanDeductions.Add(l.LoanDeductions())
+		t.TakeHomePay = t.TakeHomePay.Add(l.TakeHomePay)
+	}
+	return t
+}
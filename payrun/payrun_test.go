@@ -0,0 +1,113 @@
+package payrun
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"runfyne/employee"
+	"runfyne/loan"
+	"runfyne/payroll"
+)
+
+var (
+	january = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	hired   = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func worker(id int, salary string, t payroll.EmployeeType) employee.Employee {
+	return employee.Employee{ID: id, Name: "Employee", HireDate: hired, PayFrequency: payroll.Monthly,
+		MonthlySalary: decimal.RequireFromString(salary), EmployeeType: t}
+}
+
+// januaryRun starts a draft monthly run for January 2024
+func januaryRun(t *testing.T) *Run {
+	t.Helper()
+	r, err := New(january, january.AddDate(0, 1, -1), payroll.Monthly, "tester")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestCompute(t *testing.T) {
+	semiMonthly := worker(2, "30000", "")
+	semiMonthly.PayFrequency = payroll.SemiMonthly
+	separated := worker(3, "30000", "")
+	separated.SeparatedOn = january.AddDate(0, -1, 0)
+
+	r := januaryRun(t)
+	if err := r.Compute([]employee.Employee{worker(1, "33333", ""), semiMonthly, separated}, nil, "tester"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the monthly employee still with the company is paid in the run
+	if len(r.Lines) != 1 || r.Lines[0].EmployeeID != 1 {
+		t.Fatalf("lines %+v, want only employee 1", r.Lines)
+	}
+	l := r.Lines[0]
+	if l.Tax.StringFixed(2) != "1545.00" || l.NetPayAfterDeductions.StringFixed(2) != "29588.01" {
+		t.Errorf("tax %s net %s, want 1545.00 and 29588.01", l.Tax.StringFixed(2), l.NetPayAfterDeductions.StringFixed(2))
+	}
+	if r.Rules != payroll.Current().Version {
+		t.Errorf("rules %q, want %q", r.Rules, payroll.Current().Version)
+	}
+}
+
+func TestComputeEmployeeTypes(t *testing.T) {
+	r := januaryRun(t)
+	staff := []employee.Employee{worker(1, "25000", payroll.MinimumWage), worker(2, "4500", payroll.Kasambahay)}
+	if err := r.Compute(staff, nil, "tester"); err != nil {
+		t.Fatal(err)
+	}
+	// The minimum wage earner keeps paying contributions, the low-paid
+	// kasambahay's are paid by the employer; neither pays income tax
+	for i, want := range []string{"23212.50", "4500.00"} {
+		l := r.Lines[i]
+		if !l.Tax.IsZero() || l.NetPayAfterDeductions.StringFixed(2) != want {
+			t.Errorf("employee %d: tax %s net %s, want no tax and %s", l.EmployeeID, l.Tax, l.NetPayAfterDeductions, want)
+		}
+	}
+}
+
+func TestComputeBelowMinimumWage(t *testing.T) {
+	r := januaryRun(t)
+	regular := worker(1, "30000", "")
+	if err := r.Compute([]employee.Employee{regular}, nil, "tester"); err != nil {
+		t.Fatal(err)
+	}
+	low := worker(2, "4500", payroll.Kasambahay)
+	low.Region = "NCR"
+	if err := r.Compute([]employee.Employee{regular, low}, nil, "tester"); !errors.Is(err, payroll.ErrBelowMinimumWage) {
+		t.Errorf("error %v, want %v", err, payroll.ErrBelowMinimumWage)
+	}
+	if len(r.Lines) != 1 || r.Lines[0].EmployeeID != 1 {
+		t.Error("the run changed although an employee could not be computed")
+	}
+}
+
+func TestNewRejectsReversedCutoff(t *testing.T) {
+	if _, err := New(january, january.AddDate(0, 0, -1), payroll.Monthly, "tester"); err == nil {
+		t.Error("cutoff ending before it starts was accepted")
+	}
+}
+
+func TestComputeTakesLoanInstallments(t *testing.T) {
+	advance := loan.Loan{ID: 1, EmployeeID: 1, Kind: loan.Company, Principal: decimal.NewFromInt(12000), TermMonths: 12, StartDate: january}
+	later := advance
+	later.ID, later.StartDate = 2, january.AddDate(0, 2, 0)
+
+	r := januaryRun(t)
+	if err := r.Compute([]employee.Employee{worker(1, "33333", "")}, []loan.Loan{advance, later}, "tester"); err != nil {
+		t.Fatal(err)
+	}
+	l := r.Lines[0]
+	// Only the loan that has started is taken, after the net pay
+	if l.NetPayAfterDeductions.StringFixed(2) != "29588.01" || l.TakeHomePay.StringFixed(2) != "28588.01" {
+		t.Errorf("net %s take-home %s, want 29588.01 and 28588.01", l.NetPayAfterDeductions, l.TakeHomePay)
+	}
+	if payments := r.LoanPayments(); len(payments) != 1 || !payments[1].Equal(decimal.NewFromInt(1000)) {
+		t.Errorf("loan payments %v, want 1000 on loan 1", payments)
+	}
+}
@@ -0,0 +1,170 @@
+package payrun
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"runfyne/loan"
+	"runfyne/storage"
+)
+
+// Store keeps every payroll run, saved to a JSON file after every change
+type Store struct {
+	mu     sync.Mutex
+	path   string
+	nextID int
+	runs   map[int]Run
+	loans  *loan.Store // balances the installments of locked runs are taken from
+}
+
+// storeFile is the layout of the runs on disk
+type storeFile struct {
+	NextID int
+	Runs   []Run
+}
+
+// NewStore returns an empty store backed by the file at path. Saving a run
+// as locked takes its loan installments from the balances in loans, saving
+// a reopened one puts them back. loans may be nil when no loans are kept
+func NewStore(path string, loans *loan.Store) *Store {
+	return &Store{path: path, nextID: 1, runs: map[int]Run{}, loans: loans}
+}
+
+// Load reads the runs from disk, a missing file gives an empty store
+func (s *Store) Load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file := storeFile{NextID: 1}
+	if err := storage.ReadJSON(s.path, &file); err != nil {
+		return fmt.Errorf("loading payroll runs: %w", err)
+	}
+	s.nextID = file.NextID
+	s.runs = make(map[int]Run, len(file.Runs))
+	for _, r := range file.Runs {
+		s.runs[r.ID] = r
+		if r.ID >= s.nextID {
+			s.nextID = r.ID + 1
+		}
+	}
+	return nil
+}
+
+// Save stores a run, assigning an ID to new ones. A run that is locked
+// on disk can only be saved again once it has been reopened, and a run
+// whose cutoff overlaps another run of the same pay frequency is refused,
+// as both would take the same loan installments
+func (s *Store) Save(r *Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, exists := s.runs[r.ID]
+	if exists && old.Status == Locked && r.Status == Locked {
+		return ErrLocked
+	}
+	for _, other := range s.runs {
+		if other.ID != r.ID && other.PayFrequency == r.PayFrequency &&
+			!other.PeriodStart.After(r.PeriodEnd) && !r.PeriodStart.After(other.PeriodEnd) {
+			return fmt.Errorf("%w: run %d covers %s to %s", ErrOverlap, other.ID,
+				other.PeriodStart.Format("2006-01-02"), other.PeriodEnd.Format("2006-01-02"))
+		}
+	}
+
+	// A new run takes the next ID, which is only used up once the run is saved
+	isNew := r.ID == 0
+	if isNew {
+		r.ID = s.nextID
+		s.nextID++
+	}
+	undo, err := s.settleLoans(r, old, exists && old.Status == Locked)
+	if err != nil {
+		err = fmt.Errorf("updating loan balances: %w", err)
+	} else {
+		s.runs[r.ID] = *r.clone()
+		if err = s.save(); err != nil && undo != nil {
+			err = errors.Join(err, undo())
+		}
+	}
+	if err != nil {
+		if exists {
+			s.runs[r.ID] = old
+		} else {
+			delete(s.runs, r.ID)
+		}
+		if isNew {
+			r.ID = 0
+			s.nextID--
+		}
+		return err
+	}
+	return nil
+}
+
+// settleLoans records a newly locked run's installments against the loan
+// balances, or removes them when a locked run was reopened. The returned
+// function reverses the change when the run cannot be saved after all
+func (s *Store) settleLoans(r *Run, old Run, wasLocked bool) (undo func() error, err error) {
+	if s.loans == nil {
+		return nil, nil
+	}
+	switch {
+	case r.Status == Locked && !wasLocked:
+		if err := s.loans.RecordPayments(r.ID, r.PeriodEnd, r.LoanPayments()); err != nil {
+			return nil, err
+		}
+		return func() error { return s.loans.RemovePayments(r.ID) }, nil
+	case r.Status != Locked && wasLocked:
+		if err := s.loans.RemovePayments(r.ID); err != nil {
+			return nil, err
+		}
+		return func() error { return s.loans.RecordPayments(old.ID, old.PeriodEnd, old.LoanPayments()) }, nil
+	}
+	return nil, nil
+}
+
+// Get returns a copy of the run with the given ID
+func (s *Store) Get(id int) (*Run, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.runs[id]
+	if !ok {
+		return nil, false
+	}
+	return r.clone(), true
+}
+
+// All returns every run, the latest cutoff first
+func (s *Store) All() []*Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runs := make([]*Run, 0, len(s.runs))
+	for _, r := range s.runs {
+		runs = append(runs, r.clone())
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		if !runs[i].PeriodStart.Equal(runs[j].PeriodStart) {
+			return runs[i].PeriodStart.After(runs[j].PeriodStart)
+		}
+		return runs[i].ID > runs[j].ID
+	})
+	return runs
+}
+
+func (s *Store) save() error {
+	file := storeFile{NextID: s.nextID}
+	for _, r := range s.runs {
+		file.Runs = append(file.Runs, r)
+	}
+	sort.Slice(file.Runs, func(i, j int) bool { return file.Runs[i].ID < file.Runs[j].ID })
+	return storage.WriteJSON(s.path, file)
+}
+
+// clone copies the run so callers cannot change stored figures behind the store's back
+func (r Run) clone() *Run {
+	r.Lines = append([]Line(nil), r.Lines...)
+	r.Audit = append([]AuditEntry(nil), r.Audit...)
+	return &r
+}
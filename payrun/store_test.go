@@ -0,0 +1,113 @@
+package payrun
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"runfyne/employee"
+	"runfyne/loan"
+	"runfyne/payroll"
+)
+
+func TestStoreOverlappingRuns(t *testing.T) {
+	runs := NewStore(filepath.Join(t.TempDir(), "runs.json"), nil)
+	first := januaryRun(t)
+	if err := runs.Save(first); err != nil {
+		t.Fatal(err)
+	}
+
+	day := func(month time.Month, d int) time.Time { return time.Date(2024, month, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name       string
+		start, end time.Time
+		freq       payroll.PayFrequency
+		err        error
+	}{
+		{"same cutoff", day(1, 1), day(1, 31), payroll.Monthly, ErrOverlap},
+		{"sharing the last day", day(1, 31), day(2, 29), payroll.Monthly, ErrOverlap},
+		{"inside it", day(1, 10), day(1, 20), payroll.Monthly, ErrOverlap},
+		{"next month", day(2, 1), day(2, 29), payroll.Monthly, nil},
+		{"other pay frequency", day(1, 1), day(1, 15), payroll.SemiMonthly, nil},
+	}
+	for _, tt := range tests {
+		r, err := New(tt.start, tt.end, tt.freq, "tester")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := runs.Save(r); !errors.Is(err, tt.err) {
+			t.Errorf("%s: error %v, want %v", tt.name, err, tt.err)
+		}
+	}
+
+	// The run can still be saved over itself
+	if err := runs.Save(first); err != nil {
+		t.Errorf("saving the first run again: %v", err)
+	}
+}
+
+func TestStoreFailedSaveKeepsID(t *testing.T) {
+	// The runs file cannot be written under a regular file
+	blocker := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runs := NewStore(filepath.Join(blocker, "runs.json"), nil)
+
+	r := januaryRun(t)
+	if err := runs.Save(r); err == nil {
+		t.Fatal("saved under a regular file")
+	}
+	if r.ID != 0 || runs.nextID != 1 || len(runs.All()) != 0 {
+		t.Errorf("after the failed save the run has ID %d, the next is %d and %d runs are kept",
+			r.ID, runs.nextID, len(runs.All()))
+	}
+
+	runs.path = filepath.Join(t.TempDir(), "runs.json")
+	if err := runs.Save(r); err != nil || r.ID != 1 {
+		t.Errorf("saved as run %d, error %v, want run 1", r.ID, err)
+	}
+}
+
+func TestStoreLoanPayments(t *testing.T) {
+	dir := t.TempDir()
+	loans := loan.NewStore(filepath.Join(dir, "loans.json"))
+	advance, err := loans.Add(loan.Loan{EmployeeID: 1, Kind: loan.Company, Principal: decimal.NewFromInt(12000),
+		TermMonths: 12, StartDate: january})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runs := NewStore(filepath.Join(dir, "runs.json"), loans)
+	r := januaryRun(t)
+	if err := r.Compute([]employee.Employee{worker(1, "30000", "")}, loans.All(), "tester"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The installment leaves the balance only while the run is locked
+	steps := []struct {
+		name   string
+		action func(r *Run) error
+		paid   int64
+		err    error
+	}{
+		{"draft", func(r *Run) error { return nil }, 0, nil},
+		{"approved", func(r *Run) error { return r.Approve("tester") }, 0, nil},
+		{"locked", func(r *Run) error { return r.Lock("tester") }, 1000, nil},
+		{"saved while locked", func(r *Run) error { return nil }, 1000, ErrLocked},
+		{"reopened", func(r *Run) error { return r.Reopen("tester", "wrong hours") }, 0, nil},
+	}
+	for _, s := range steps {
+		if err := s.action(r); err != nil {
+			t.Fatalf("%s: %v", s.name, err)
+		}
+		if err := runs.Save(r); !errors.Is(err, s.err) {
+			t.Errorf("%s: save error %v, want %v", s.name, err, s.err)
+		}
+		if l, _ := loans.Get(advance.ID); !l.Paid().Equal(decimal.NewFromInt(s.paid)) {
+			t.Errorf("%s: %s paid on the loan, want %d", s.name, l.Paid(), s.paid)
+		}
+	}
+}
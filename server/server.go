@@ -0,0 +1,405 @@
+// Package server exposes the calculator on the local network, as a JSON API
+// for other systems such as the HR portal and as a page for web browsers.
+package server
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/shopspring/decimal"
+	"runfyne/payroll"
+)
+
+// MaxBatch is the most employees accepted in one batch request
+const MaxBatch = 5000
+
+// Request bodies are small, refuse anything far beyond a large batch
+const maxBodyBytes = 2 << 20
+
+//go:embed openapi.json
+var openAPI []byte
+
+// Options selects the rules applied to a request, every field is optional
+type Options struct {
+	PayFrequency payroll.PayFrequency
+	Year         int
+	EmployeeType payroll.EmployeeType
+	Region       string // where a kasambahay works, to check the minimum wage
+}
+
+func (o Options) toPayroll() payroll.Options {
+	return payroll.Options{PayFrequency: o.PayFrequency, Year: o.Year, EmployeeType: o.EmployeeType, Region: o.Region}
+}
+
+// ComputeRequest is the body of POST /api/compute, with either an income or earning lines
+type ComputeRequest struct {
+	Options
+	Income   *decimal.Decimal // gross income for one pay period
+	Earnings []EarningLine    // the pay for one pay period by line
+}
+
+// EarningLine is one line of pay. Name, Taxable and ContributionBase default
+// to those of the type
+type EarningLine struct {
+	Name             string
+	Type             payroll.EarningType
+	Amount           *decimal.Decimal
+	Taxable          *bool
+	ContributionBase *bool
+}
+
+// toPayroll returns the line with its defaults filled in
+func (l EarningLine) toPayroll() payroll.Earning {
+	e := payroll.NewEarning(l.Type, *l.Amount)
+	if l.Name != "" {
+		e.Name = l.Name
+	}
+	if l.Taxable != nil {
+		e.Taxable = *l.Taxable
+	}
+	if l.ContributionBase != nil {
+		e.ContributionBase = *l.ContributionBase
+	}
+	return e
+}
+
+// ReverseRequest is the body of POST /api/reverse
+type ReverseRequest struct {
+	Options
+	NetPay *decimal.Decimal // net pay wanted for one pay period
+}
+
+// BatchEmployee is one employee of a batch request
+type BatchEmployee struct {
+	ID     string `json:",omitempty"`
+	Name   string `json:",omitempty"`
+	Income *decimal.Decimal
+}
+
+// BatchRequest is the body of POST /api/batch
+type BatchRequest struct {
+	Options
+	Employees []BatchEmployee
+}
+
+// Result is the breakdown returned for one income
+type Result struct {
+	Rules        string
+	PayFrequency payroll.PayFrequency
+	EmployeeType payroll.EmployeeType
+	Result       payroll.TaxInputs
+}
+
+// BatchLine is the breakdown of one employee of a batch
+type BatchLine struct {
+	ID     string `json:",omitempty"`
+	Name   string `json:",omitempty"`
+	Result payroll.TaxInputs
+}
+
+// BatchResult is the response of POST /api/batch
+type BatchResult struct {
+	Rules        string
+	PayFrequency payroll.PayFrequency
+	EmployeeType payroll.EmployeeType
+	Lines        []BatchLine
+	Totals       payroll.TaxInputs
+}
+
+// Error is the body of every failed request. Problems lists each invalid
+// field when the request could be read but did not validate
+type Error struct {
+	Error    string
+	Problems []string `json:",omitempty"`
+}
+
+// validationError is a request that was read but has invalid values
+type validationError struct{ problems []string }
+
+func (e *validationError) Error() string {
+	if len(e.problems) == 1 {
+		return e.problems[0]
+	}
+	return fmt.Sprintf("%d problems with the request", len(e.problems))
+}
+
+func (e *validationError) add(format string, args ...interface{}) {
+	e.problems = append(e.problems, fmt.Sprintf(format, args...))
+}
+
+// check adds the problems with the options to the error and returns the rules
+// of the year asked for, nil when there are none
+func (e *validationError) check(o Options) *payroll.Rules {
+	if o.PayFrequency != "" {
+		if _, err := payroll.ParsePayFrequency(string(o.PayFrequency)); err != nil {
+			e.add("PayFrequency: %v", err)
+		}
+	}
+	if o.EmployeeType != "" {
+		if _, err := payroll.ParseEmployeeType(string(o.EmployeeType)); err != nil {
+			e.add("EmployeeType: %v", err)
+		}
+	}
+	rules, err := payroll.ForYear(o.Year)
+	if err != nil {
+		e.add("Year: %v", err)
+	} else if o.Region != "" {
+		if _, err := rules.Kasambahay.MinimumWageIn(o.Region); err != nil {
+			e.add("Region: %v", err)
+		}
+	}
+	return rules
+}
+
+func (e *validationError) checkAmount(name string, d *decimal.Decimal) {
+	switch {
+	case d == nil:
+		e.add("%s: required", name)
+	case d.LessThan(decimal.Zero):
+		e.add("%s: cannot be negative", name)
+	}
+}
+
+func (e *validationError) err() error {
+	if len(e.problems) == 0 {
+		return nil
+	}
+	return e
+}
+
+// New returns the handler serving the API and the calculator page
+func New() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/compute", post(compute))
+	mux.HandleFunc("/api/reverse", post(reverse))
+	mux.HandleFunc("/api/batch", post(batch))
+	mux.HandleFunc("/api/tables", get(tables))
+	mux.HandleFunc("/api/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openAPI)
+	})
+	mux.HandleFunc("/", calculatorPage)
+	return mux
+}
+
+func compute(r *http.Request) (interface{}, error) {
+	var req ComputeRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	v := &validationError{}
+	rules := v.check(req.Options)
+	if len(req.Earnings) == 0 {
+		v.checkAmount("Income", req.Income)
+	} else if req.Income != nil {
+		v.add("Income: give either an income or earnings, not both")
+	}
+	for i, l := range req.Earnings {
+		if _, err := payroll.ParseEarningType(string(l.Type)); err != nil {
+			v.add("Earnings[%d].Type: %v", i, err)
+		}
+		v.checkAmount(fmt.Sprintf("Earnings[%d].Amount", i), l.Amount)
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("compute with rules %s", rules)
+	var result payroll.TaxInputs
+	var err error
+	if len(req.Earnings) > 0 {
+		earnings := make([]payroll.Earning, len(req.Earnings))
+		for i, l := range req.Earnings {
+			earnings[i] = l.toPayroll()
+		}
+		result, err = rules.ComputeEarningsWith(earnings, req.toPayroll())
+	} else {
+		result, err = rules.ComputeWith(*req.Income, req.toPayroll())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newResult(rules, req.Options, result), nil
+}
+
+func reverse(r *http.Request) (interface{}, error) {
+	var req ReverseRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	v := &validationError{}
+	rules := v.check(req.Options)
+	v.checkAmount("NetPay", req.NetPay)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("reverse with rules %s", rules)
+	result, err := rules.Reverse(*req.NetPay, req.toPayroll())
+	if err != nil {
+		return nil, err
+	}
+	return newResult(rules, req.Options, result), nil
+}
+
+func batch(r *http.Request) (interface{}, error) {
+	var req BatchRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	v := &validationError{}
+	rules := v.check(req.Options)
+	switch {
+	case len(req.Employees) == 0:
+		v.add("Employees: at least one employee is required")
+	case len(req.Employees) > MaxBatch:
+		v.add("Employees: at most %d employees per request", MaxBatch)
+	}
+	for i, e := range req.Employees {
+		v.checkAmount(fmt.Sprintf("Employees[%d].Income", i), e.Income)
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("batch of %d with rules %s", len(req.Employees), rules)
+	opts := req.toPayroll()
+	res := BatchResult{
+		Rules:        rules.Version,
+		PayFrequency: frequencyOf(req.Options),
+		EmployeeType: typeOf(req.Options),
+	}
+	for _, e := range req.Employees {
+		result, err := rules.ComputeWith(*e.Income, opts)
+		if err != nil {
+			return nil, err
+		}
+		res.Lines = append(res.Lines, BatchLine{ID: e.ID, Name: e.Name, Result: result})
+		res.Totals = add(res.Totals, result)
+	}
+	return res, nil
+}
+
+// tables lists the current tables, or those of the year given as ?year=
+func tables(r *http.Request) (interface{}, error) {
+	year := 0
+	if y := r.URL.Query().Get("year"); y != "" {
+		var err error
+		if year, err = strconv.Atoi(y); err != nil {
+			return nil, &validationError{problems: []string{"year: not a number"}}
+		}
+	}
+	rules, err := payroll.ForYear(year)
+	if err != nil {
+		return nil, &validationError{problems: []string{"year: " + err.Error()}}
+	}
+	return rules.Tables(), nil
+}
+
+// add sums two breakdowns field by field, and other deductions by name
+func add(a, b payroll.TaxInputs) payroll.TaxInputs {
+	sum := payroll.TaxInputs{
+		MonthlyIncome:           a.MonthlyIncome.Add(b.MonthlyIncome),
+		TaxableIncome:           a.TaxableIncome.Add(b.TaxableIncome),
+		Tax:                     a.Tax.Add(b.Tax),
+		NetPayAfterTax:          a.NetPayAfterTax.Add(b.NetPayAfterTax),
+		SSSContributions:        a.SSSContributions.Add(b.SSSContributions),
+		PhilHealthContributions: a.PhilHealthContributions.Add(b.PhilHealthContributions),
+		PagIbigContributions:    a.PagIbigContributions.Add(b.PagIbigContributions),
+		TotalContributions:      a.TotalContributions.Add(b.TotalContributions),
+		TotalDeductions:         a.TotalDeductions.Add(b.TotalDeductions),
+		NetPayAfterDeductions:   a.NetPayAfterDeductions.Add(b.NetPayAfterDeductions),
+		OtherDeductions:         append([]payroll.DeductionLine(nil), a.OtherDeductions...),
+	}
+	for _, d := range b.OtherDeductions {
+		found := false
+		for i := range sum.OtherDeductions {
+			if sum.OtherDeductions[i].Name == d.Name {
+				sum.OtherDeductions[i].Amount = sum.OtherDeductions[i].Amount.Add(d.Amount)
+				found = true
+			}
+		}
+		if !found {
+			sum.OtherDeductions = append(sum.OtherDeductions, d)
+		}
+	}
+	return sum
+}
+
+func newResult(rules *payroll.Rules, o Options, r payroll.TaxInputs) Result {
+	return Result{Rules: rules.Version, PayFrequency: frequencyOf(o), EmployeeType: typeOf(o), Result: r}
+}
+
+// frequencyOf and typeOf fill in the defaults so responses always say what was applied
+func frequencyOf(o Options) payroll.PayFrequency {
+	if o.PayFrequency == "" {
+		return payroll.Monthly
+	}
+	return o.PayFrequency
+}
+
+func typeOf(o Options) payroll.EmployeeType {
+	if o.EmployeeType == "" {
+		return payroll.Regular
+	}
+	return o.EmployeeType
+}
+
+// decode reads a JSON body, rejecting unknown fields so typos are not silently ignored
+func decode(r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		if errors.Is(err, io.EOF) {
+			return &validationError{problems: []string{"request body is empty"}}
+		}
+		return &validationError{problems: []string{"invalid JSON: " + err.Error()}}
+	}
+	return nil
+}
+
+type handler func(r *http.Request) (interface{}, error)
+
+func post(h handler) http.HandlerFunc { return method(http.MethodPost, h) }
+
+func get(h handler) http.HandlerFunc { return method(http.MethodGet, h) }
+
+// method serves h for one HTTP method and writes its result or error as JSON
+func method(name string, h handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != name {
+			w.Header().Set("Allow", name)
+			writeJSON(w, http.StatusMethodNotAllowed, Error{Error: "method not allowed, use " + name})
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+
+		v, err := h(r)
+		var invalid *validationError
+		switch {
+		case errors.As(err, &invalid):
+			writeJSON(w, http.StatusBadRequest, Error{Error: invalid.Error(), Problems: invalid.problems})
+		case errors.Is(err, payroll.ErrBelowMinimumWage):
+			writeJSON(w, http.StatusBadRequest, Error{Error: err.Error()})
+		case err != nil:
+			log.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+			writeJSON(w, http.StatusInternalServerError, Error{Error: err.Error()})
+		default:
+			writeJSON(w, http.StatusOK, v)
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("writing response: %v", err)
+	}
+}
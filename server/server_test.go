@@ -0,0 +1,104 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// send serves one request and decodes a successful response into v
+func send(t *testing.T, method, path, body string, v interface{}) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	New().ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	if rec.Code == http.StatusOK && v != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatalf("%s %s: %v\n%s", method, path, err, rec.Body)
+		}
+	}
+	return rec.Code
+}
+
+func TestCompute(t *testing.T) {
+	for body, netPay := range map[string]string{
+		`{"Income": "33333"}`: "29588.01",
+		`{"Income": "25000", "PayFrequency": "semi-monthly"}`: "21365.80",
+	} {
+		var res Result
+		if status := send(t, "POST", "/api/compute", body, &res); status != http.StatusOK {
+			t.Errorf("%s: status %d", body, status)
+			continue
+		}
+		if got := res.Result.NetPayAfterDeductions.StringFixed(2); got != netPay || res.Rules == "" {
+			t.Errorf("%s: net pay %s under rules %q, want %s", body, got, res.Rules, netPay)
+		}
+	}
+}
+
+func TestComputeRefused(t *testing.T) {
+	for _, body := range []string{
+		`{"Income": "-1"}`,
+		`{}`,
+		`{"Income": "1000", "PayFrequency": "fortnightly"}`,
+		`{"Income": `,
+	} {
+		if status := send(t, "POST", "/api/compute", body, nil); status != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want %d", body, status, http.StatusBadRequest)
+		}
+	}
+	if status := send(t, "GET", "/api/compute", "", nil); status != http.StatusMethodNotAllowed {
+		t.Errorf("GET /api/compute: status %d", status)
+	}
+}
+
+func TestReverseAndBatch(t *testing.T) {
+	var reversed Result
+	if status := send(t, "POST", "/api/reverse", `{"NetPay": "29588.01"}`, &reversed); status != http.StatusOK {
+		t.Fatalf("reverse: status %d", status)
+	}
+	if gross := reversed.Result.MonthlyIncome.StringFixed(2); gross != "33333.01" {
+		t.Errorf("reverse found a gross of %s, want 33333.01", gross)
+	}
+
+	var batch BatchResult
+	body := `{"Employees": [{"Name": "Ana", "Income": "33333"}, {"Name": "Ben", "Income": "50000"}]}`
+	if status := send(t, "POST", "/api/batch", body, &batch); status != http.StatusOK {
+		t.Fatalf("batch: status %d", status)
+	}
+	if len(batch.Lines) != 2 || batch.Lines[0].Name != "Ana" ||
+		batch.Totals.NetPayAfterDeductions.StringFixed(2) != "72319.61" {
+		t.Errorf("batch %+v", batch)
+	}
+
+	if status := send(t, "GET", "/api/tables", "", nil); status != http.StatusOK {
+		t.Errorf("tables: status %d", status)
+	}
+}
+
+func TestKasambahay(t *testing.T) {
+	var res Result
+	if status := send(t, "POST", "/api/compute", `{"Income": "4500", "EmployeeType": "kasambahay"}`, &res); status != http.StatusOK {
+		t.Fatalf("compute: status %d", status)
+	}
+	if got := res.Result.NetPayAfterDeductions.StringFixed(2); got != "4500.00" {
+		t.Errorf("net pay %s, want the whole 4500.00", got)
+	}
+
+	body := `{"Income": "4500", "EmployeeType": "kasambahay", "Region": "NCR"}`
+	if status := send(t, "POST", "/api/compute", body, nil); status != http.StatusBadRequest {
+		t.Errorf("below the NCR minimum: status %d, want %d", status, http.StatusBadRequest)
+	}
+
+	var reversed Result
+	body = `{"NetPay": "6500", "EmployeeType": "kasambahay", "Region": "NCR"}`
+	if status := send(t, "POST", "/api/reverse", body, &reversed); status != http.StatusOK {
+		t.Fatalf("reverse: status %d", status)
+	}
+	if reversed.Result.NetPayAfterDeductions.LessThan(decimal.NewFromInt(6500)) {
+		t.Errorf("reverse leaves %s, short of 6500", reversed.Result.NetPayAfterDeductions)
+	}
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"embed"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/leekchan/accounting"
+	"github.com/shopspring/decimal"
+	"runfyne/payroll"
+)
+
+//go:embed web
+var webFiles embed.FS
+
+// Same peso formatting as the desktop app
+var peso = accounting.Accounting{Symbol: "₱ ", Precision: 2}
+
+var page = template.Must(template.New("index.html").Funcs(template.FuncMap{
+	"peso": func(d decimal.Decimal) string { return peso.FormatMoney(d) },
+}).ParseFS(webFiles, "web/index.html"))
+
+// pageData is what the calculator page shows
+type pageData struct {
+	Income       string
+	Frequency    payroll.PayFrequency
+	EmployeeType payroll.EmployeeType
+	Region       string
+	Frequencies  []payroll.PayFrequency
+	Types        []payroll.EmployeeType
+	Regions      []string
+	Rules        string
+	Error        string
+	Result       *payroll.TaxInputs
+}
+
+// calculatorPage renders the form and, once an income is submitted, the breakdown.
+// Everything is rendered on the server so the page works without scripts or internet access
+func calculatorPage(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	// one snapshot of the rules, they can be reloaded while the page renders
+	rules := payroll.Current()
+	q := r.URL.Query()
+	data := pageData{
+		Income:       strings.TrimSpace(q.Get("income")),
+		Frequency:    payroll.PayFrequency(q.Get("frequency")),
+		EmployeeType: payroll.EmployeeType(q.Get("type")),
+		Region:       q.Get("region"),
+		Frequencies:  payroll.PayFrequencies,
+		Types:        payroll.EmployeeTypes,
+		Regions:      rules.Kasambahay.Regions(),
+		Rules:        rules.Version,
+	}
+	if data.Frequency == "" {
+		data.Frequency = payroll.Monthly
+	}
+	if data.EmployeeType == "" {
+		data.EmployeeType = payroll.Regular
+	}
+
+	if data.Income != "" {
+		income, err := decimal.NewFromString(strings.ReplaceAll(data.Income, ",", ""))
+		if err != nil {
+			data.Error = "Invalid income input"
+		} else if result, err := rules.ComputeWith(income, payroll.Options{
+			PayFrequency: data.Frequency,
+			EmployeeType: data.EmployeeType,
+			Region:       data.Region,
+		}); err != nil {
+			data.Error = err.Error()
+		} else {
+			log.Printf("page compute with rules %s", rules)
+			data.Result = &result
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := page.Execute(w, data); err != nil {
+		log.Printf("rendering calculator page: %v", err)
+	}
+}
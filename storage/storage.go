@@ -0,0 +1,93 @@
+// Package storage keeps the app's data as JSON files in the user's config directory.
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// appDir is the folder created inside the user's config directory
+const appDir = "TaxCollector"
+
+// Path returns where the named data file is kept, falling back to the
+// working directory when the config directory cannot be determined
+func Path(name string) string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return name
+	}
+	return filepath.Join(dir, appDir, name)
+}
+
+// unreadable holds the files that could be neither read nor set aside,
+// WriteJSON refuses to overwrite them
+var unreadable sync.Map
+
+// ReadJSON decodes the file at path into v. A missing file is not an error
+// and leaves v untouched, as does any error. A file that cannot be decoded
+// is renamed out of the way, so the empty data the caller carries on with is
+// not saved over it, and the error names the copy. When even that fails,
+// later writes to path are refused
+func ReadJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	/* Decoding into a fresh value first leaves v as it was when the file is
+	bad. Once that succeeds, decoding into v cannot fail and keeps the
+	defaults the caller set for fields the file does not have */
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.Pointer {
+		return json.Unmarshal(data, v)
+	}
+	if err = json.Unmarshal(data, reflect.New(reflect.TypeOf(v).Elem()).Interface()); err == nil {
+		return json.Unmarshal(data, v)
+	}
+
+	backup := fmt.Sprintf("%s.unreadable-%s", path, time.Now().Format("20060102-150405"))
+	if renameErr := os.Rename(path, backup); renameErr != nil {
+		unreadable.Store(path, true)
+		return fmt.Errorf("%w; %s was left as it is and will not be saved over", err, path)
+	}
+	return fmt.Errorf("%w; the file was kept as %s", err, backup)
+}
+
+// WriteJSON encodes v into the file at path. The data is written to a
+// temporary file first and renamed over the old one so a crash midway
+// never leaves a half-written file behind
+func WriteJSON(path string, v interface{}) error {
+	if _, ok := unreadable.Load(path); ok {
+		return fmt.Errorf("%s could not be read when it was loaded, not saving over it", path)
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type settings struct {
+	Names  []string
+	NextID int
+}
+
+// writeFile puts contents at a fresh path and returns it
+func writeFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "data.json")
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadJSON(t *testing.T) {
+	t.Run("missing file keeps the defaults", func(t *testing.T) {
+		v := settings{NextID: 1}
+		if err := ReadJSON(filepath.Join(t.TempDir(), "none.json"), &v); err != nil {
+			t.Fatal(err)
+		}
+		if v.NextID != 1 || v.Names != nil {
+			t.Errorf("read %+v", v)
+		}
+	})
+
+	t.Run("fields missing from the file keep the defaults", func(t *testing.T) {
+		v := settings{NextID: 1}
+		if err := ReadJSON(writeFile(t, `{"Names":["a","b"]}`), &v); err != nil {
+			t.Fatal(err)
+		}
+		if want := (settings{Names: []string{"a", "b"}, NextID: 1}); !reflect.DeepEqual(v, want) {
+			t.Errorf("read %+v, want %+v", v, want)
+		}
+	})
+
+	t.Run("a bad file is set aside and v is untouched", func(t *testing.T) {
+		const contents = `{"Names":["a"],"NextID":"seven"}`
+		path := writeFile(t, contents)
+		v := settings{NextID: 1}
+		err := ReadJSON(path, &v)
+		if err == nil {
+			t.Fatal("no error for a field of the wrong type")
+		}
+		if v.Names != nil || v.NextID != 1 {
+			t.Errorf("partly decoded into %+v", v)
+		}
+
+		copies, _ := filepath.Glob(path + ".unreadable-*")
+		if len(copies) != 1 {
+			t.Fatalf("copies %v, error %v", copies, err)
+		}
+		if kept, _ := os.ReadFile(copies[0]); string(kept) != contents {
+			t.Errorf("copy holds %q", kept)
+		}
+		if err := WriteJSON(path, v); err != nil {
+			t.Errorf("saving after the file was set aside: %v", err)
+		}
+	})
+
+	t.Run("a file that cannot be read stays where it is", func(t *testing.T) {
+		path := t.TempDir() // reading a folder fails without decoding anything
+		var v settings
+		if err := ReadJSON(path, &v); err == nil {
+			t.Fatal("no error reading a folder")
+		}
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("the folder was moved: %v", err)
+		}
+		if copies, _ := filepath.Glob(path + ".unreadable-*"); len(copies) != 0 {
+			t.Errorf("set aside as %v", copies)
+		}
+		if _, refused := unreadable.Load(path); refused {
+			t.Error("later saves to the path are refused")
+		}
+	})
+}
+
+func TestWriteJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "new", "data.json")
+	in := map[string]int{"a": 1, "b": 2}
+	if err := WriteJSON(path, in); err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]int
+	if err := ReadJSON(path, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("read back %v, want %v", out, in)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("%d files left in the folder, want only data.json", len(entries))
+	}
+
+	unreadable.Store(path, true)
+	t.Cleanup(func() { unreadable.Delete(path) })
+	if err := WriteJSON(path, in); err == nil {
+		t.Error("saved over a file that could not be read")
+	}
+}
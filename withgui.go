@@ -1,40 +1,105 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"log"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/leekchan/accounting"
 	"github.com/shopspring/decimal"
+	"runfyne/employee"
+	"runfyne/history"
+	"runfyne/loan"
+	"runfyne/payroll"
+	"runfyne/payrun"
+	"runfyne/storage"
 )
 
-/* Define a structure for all variables needed
-   to hold computational results and inputs */
-type TaxInputs struct {
-	MonthlyIncome           decimal.Decimal
-	TaxableIncome           decimal.Decimal
-	Tax                     decimal.Decimal
-	NetPayAfterTax          decimal.Decimal
-	SSSContributions        decimal.Decimal
-	PhilHealthContributions decimal.Decimal
-	PagIbigContributions    decimal.Decimal
-	TotalContributions      decimal.Decimal
-	TotalDeductions         decimal.Decimal
-	NetPayAfterDeductions   decimal.Decimal
-}
+// Amounts are displayed in Peso format with 2 digit precision
+var peso = accounting.Accounting{Symbol: "₱ ", Precision: 2}
+
+// noRegion is the region choice that skips the kasambahay minimum wage check
+const noRegion = "Not checked"
 
 func main() {
 	/* Create a new application along 
 	with its output and input widgets */
 	myApp := app.New()
 
+	// Create a new window for the desktop application
+	myWindow := myApp.NewWindow("Tax Calculator")
+	myApp.Settings().SetTheme(theme.LightTheme())
+
+	// Rule files in the config directory override the built-in rate tables,
+	// the built-in tables stay in use if any of them is invalid
+	var loadErr error
+	if rules, err := payroll.LoadRules(storage.Path("rules")); err != nil {
+		loadErr = err
+	} else {
+		payroll.Use(rules)
+	}
+
+	// Company deductions taken besides the statutory contributions
+	if err := payroll.RegisterFile(storage.Path("deductions.toml")); err != nil {
+		loadErr = errors.Join(loadErr, err)
+	}
+
+	// Check digits of the government numbers, only their layout is checked without it
+	if err := employee.LoadCheckDigits(storage.Path("checkdigits.toml")); err != nil {
+		loadErr = errors.Join(loadErr, err)
+	}
+
+	// Employee register kept in the user's config directory
+	employees := employee.NewStore(storage.Path("employees.json"))
+	if err := employees.Load(); err != nil {
+		loadErr = errors.Join(loadErr, err)
+	}
+
+	// Loans repaid through payroll, with the installments already taken
+	loans := loan.NewStore(storage.Path("loans.json"))
+	if err := loans.Load(); err != nil {
+		loadErr = errors.Join(loadErr, err)
+	}
+
+	// Payroll runs are kept next to the employee register, locking one takes
+	// its installments from the loan balances
+	runs := payrun.NewStore(storage.Path("payroll_runs.json"), loans)
+	if err := runs.Load(); err != nil {
+		loadErr = errors.Join(loadErr, err)
+	}
+
+	// Every calculation is saved so it can be looked up later
+	calculations := history.NewStore(storage.Path("history.json"))
+	if err := calculations.Load(); err != nil {
+		loadErr = errors.Join(loadErr, err)
+	}
+
 	// Input Widgets
 	incomeEntry := widget.NewEntry()
+
+	// Employee type and, for a kasambahay, the region whose minimum wage applies
+	var typeNames []string
+	for _, t := range payroll.EmployeeTypes {
+		typeNames = append(typeNames, string(t))
+	}
+	typeSelect := widget.NewSelect(typeNames, nil)
+	typeSelect.SetSelectedIndex(0)
+	regionSelect := widget.NewSelect(append([]string{noRegion}, payroll.Current().Kasambahay.Regions()...), nil)
+	regionSelect.SetSelectedIndex(0)
+	options := func() payroll.Options {
+		opts := payroll.Options{EmployeeType: payroll.EmployeeTypes[typeSelect.SelectedIndex()]}
+		if regionSelect.Selected != noRegion {
+			opts.Region = regionSelect.Selected
+		}
+		return opts
+	}
 	
 	// Output Widgets
 	taxLabel := widget.NewLabel("")
@@ -45,10 +110,74 @@ func main() {
 	totalContributionsLabel := widget.NewLabel("")
 	totalDeductionsLabel := widget.NewLabel("")
 	netPayAfterDeductionsLabel := widget.NewLabel("")
+	otherDeductionsBox := container.NewVBox()
+	earningsBox := container.NewVBox()
+
+	// Allowances, commissions and reimbursements entered besides the basic pay
+	earningsEditor := newEarningsEditor()
 
 	// Create input row for user input
 	incomeEntry.SetPlaceHolder("Enter your monthly income")
 
+	/* Display the results of computation in Peso format 
+	with 2 digit precision for decimal points */
+	showResults := func(inputs payroll.TaxInputs) {
+		taxLabel.SetText(fmt.Sprintf(peso.FormatMoney(inputs.Tax)))
+		taxableIncomeLabel.SetText(fmt.Sprintf(peso.FormatMoney(inputs.TaxableIncome)))
+		sssContributionsLabel.SetText(fmt.Sprintf(peso.FormatMoney(inputs.SSSContributions)))
+		philhealthContributionsLabel.SetText(fmt.Sprintf(peso.FormatMoney(inputs.PhilHealthContributions)))
+		pagibigContributionsLabel.SetText(fmt.Sprintf(peso.FormatMoney(inputs.PagIbigContributions)))
+		totalContributionsLabel.SetText(fmt.Sprintf(peso.FormatMoney(inputs.TotalContributions)))
+		totalDeductionsLabel.SetText(fmt.Sprintf(peso.FormatMoney(inputs.TotalDeductions)))
+		netPayAfterDeductionsLabel.SetText(fmt.Sprintf(peso.FormatMoney(inputs.NetPayAfterDeductions)))
+
+		// Pay computed from earning lines shows what was taxed and contributed on
+		earningsBox.RemoveAll()
+		if b := inputs.Earnings; b != nil {
+			earningsBox.Add(widget.NewLabelWithStyle("Earnings",
+				fyne.TextAlignLeading,
+				fyne.TextStyle{Bold: true}))
+			for _, e := range b.Lines {
+				earningsBox.Add(container.NewHBox(
+					widget.NewLabel(fmt.Sprintf("%s (%s)\t", e.Name, earningTreatment(e))),
+					widget.NewLabel(peso.FormatMoney(e.Amount)),
+				))
+			}
+			earningsBox.Add(widget.NewLabel(fmt.Sprintf("Gross %s   Taxable %s   Non-Taxable %s   Contribution Base %s",
+				peso.FormatMoney(b.Gross),
+				peso.FormatMoney(b.Taxable),
+				peso.FormatMoney(b.NonTaxable),
+				peso.FormatMoney(b.ContributionBase))))
+		}
+
+		// Company deductions registered besides the statutory contributions
+		otherDeductionsBox.RemoveAll()
+		if len(inputs.OtherDeductions) > 0 {
+			otherDeductionsBox.Add(widget.NewLabelWithStyle("Other Deductions",
+				fyne.TextAlignLeading,
+				fyne.TextStyle{Bold: true}))
+		}
+		for _, d := range inputs.OtherDeductions {
+			otherDeductionsBox.Add(container.NewHBox(
+				widget.NewLabel(fmt.Sprintf("%s (%s)\t", d.Name, d.Timing)),
+				widget.NewLabel(peso.FormatMoney(d.Amount)),
+			))
+		}
+	}
+
+	// History view, refreshed whenever a new calculation is saved
+	historyView := newHistoryView(myWindow, calculations)
+	loansView := newLoansView(myWindow, loans, employees)
+
+	// Step by step explanation of each deduction
+	explanation := newExplanationView()
+
+	// Charts highlight the last calculated income
+	chartsView := newChartsView(myWindow)
+
+	// What the basic pay costs the employer, next to what the employee takes home
+	employerView := newEmployerCostView()
+
 	// Create the calculate button
 	calculateBtn := widget.NewButton("Calculate", func() {
 
@@ -64,45 +193,45 @@ func main() {
 			return
 		}
 
-		// Calling functions to calculate for monthly contributions
-		sssContributions := calculateSSSContributions(monthlyIncome)
-		philhealthContributions := calculatePhilHealthContributions(monthlyIncome)
-		pagibigContributions := calculatePagIbigContributions(monthlyIncome)
-		totalContributions := decimal.Sum(sssContributions, 
-							  philhealthContributions, 
-							  pagibigContributions)
-
-		// Calling functions to calculate for tax deductions
-		taxableIncome := monthlyIncome.Sub(totalContributions)
-		tax := calculateTax(taxableIncome)
-		totalDeductions := totalContributions.Add(tax)
-		netPayAfterDeductions := monthlyIncome.Sub(totalDeductions)
-
-		// Create a display struct to hold the user input and calculated values
-		inputs := TaxInputs{
-			MonthlyIncome:           monthlyIncome,
-			TaxableIncome:           taxableIncome,
-			Tax:                     tax,
-			SSSContributions:        sssContributions,
-			PhilHealthContributions: philhealthContributions,
-			PagIbigContributions:    pagibigContributions,
-			TotalContributions:      totalContributions,
-			TotalDeductions:         totalDeductions,
-			NetPayAfterDeductions:   netPayAfterDeductions,
+		// Other earning lines are added to the income as basic pay
+		earnings, err := earningsEditor.earnings(monthlyIncome)
+		if err != nil {
+			dialog.ShowError(err, myWindow)
+			return
+		}
+
+		// Run the income through the contribution and tax calculators
+		rules := payroll.Current()
+		log.Printf("computing with rules %s", rules)
+		opts := options()
+		var inputs payroll.TaxInputs
+		var traces []payroll.Trace
+		if earnings != nil {
+			inputs, err = rules.ComputeEarningsWith(earnings, opts)
+			if err == nil {
+				traces, err = rules.ExplainEarningsWith(earnings, opts)
+			}
+		} else {
+			inputs, err = rules.ComputeWith(monthlyIncome, opts)
+			if err == nil {
+				traces, err = rules.ExplainWith(monthlyIncome, opts)
+			}
+		}
+		if err != nil {
+			dialog.ShowError(err, myWindow)
+			return
 		}
+		explanation.show(traces)
 
-		/* Display the results of computation in Peso format 
-		with 2 digit precision for decimal points */
+		showResults(inputs)
+		chartsView.setIncome(monthlyIncome)
+		employerView.show(monthlyIncome, opts.EmployeeType)
 
-		ac := accounting.Accounting{Symbol: "₱ ", Precision: 2}
-		taxLabel.SetText(fmt.Sprintf(ac.FormatMoney(inputs.Tax)))
-		taxableIncomeLabel.SetText(fmt.Sprintf(ac.FormatMoney(inputs.TaxableIncome)))
-		sssContributionsLabel.SetText(fmt.Sprintf(ac.FormatMoney(inputs.SSSContributions)))
-		philhealthContributionsLabel.SetText(fmt.Sprintf(ac.FormatMoney(inputs.PhilHealthContributions)))
-		pagibigContributionsLabel.SetText(fmt.Sprintf(ac.FormatMoney(inputs.PagIbigContributions)))
-		totalContributionsLabel.SetText(fmt.Sprintf(ac.FormatMoney(inputs.TotalContributions)))
-		totalDeductionsLabel.SetText(fmt.Sprintf(ac.FormatMoney(inputs.TotalDeductions)))
-		netPayAfterDeductionsLabel.SetText(fmt.Sprintf(ac.FormatMoney(inputs.NetPayAfterDeductions)))
+		// Keep the computation in the history
+		if _, err := calculations.Add(inputs.MonthlyIncome, rules, opts, inputs); err != nil {
+			dialog.ShowError(err, myWindow)
+		}
+		historyView.Refresh()
 
 	})
 
@@ -162,141 +291,116 @@ func main() {
 									fyne.TextAlignLeading, 
 									fyne.TextStyle{Bold: true}),
 			incomeEntry,
+			widget.NewForm(
+				widget.NewFormItem("Employee Type", typeSelect),
+				widget.NewFormItem("Region", regionSelect),
+			),
+			earningsEditor.content,
 			calculateBtn,
 			layout.NewSpacer(),
 		),
 
+		earningsBox,
+
 		/* Resizing tax and contributions container through Grid Wrap Layout Manager */
 		container.New(layout.NewGridWrapLayout(fyne.NewSize(300, 200)), 
 											  contribContainer, 
 											  taxContainer),
 		container.New(layout.NewGridWrapLayout(fyne.NewSize(150, 150)), 
 											  finalComputations),
-	)
-
-	// Create a new window for the desktop application
-	myWindow := myApp.NewWindow("Tax Calculator")
-	myApp.Settings().SetTheme(theme.LightTheme())
-	myWindow.SetContent(content)
-	myWindow.Resize(fyne.NewSize(600, 600))
-	myWindow.SetFixedSize(true)
-	myWindow.ShowAndRun()
+		otherDeductionsBox,
 
-}
+		/* Expandable explanation of how each deduction was derived */
+		widget.NewLabelWithStyle("Show Your Work", 
+								fyne.TextAlignLeading, 
+								fyne.TextStyle{Bold: true}),
+		explanation.accordion,
+	)
 
-// Define a function to calculate tax based on taxable income
-func calculateTax(taxableIncome decimal.Decimal) decimal.Decimal {
-	var brackets = []decimal.Decimal{
-		decimal.NewFromFloat(20833),
-		decimal.NewFromFloat(33333),
-		decimal.NewFromFloat(66667),
-		decimal.NewFromFloat(166667),
-		decimal.NewFromFloat(666667),
-	}
+	/* The employee's figures on the left, the employer's cost on the right */
+	calculatorSplit := container.NewHSplit(
+		container.NewVScroll(content),
+		container.NewVScroll(employerView.content))
+	calculatorSplit.Offset = 0.6
+
+	/* Each screen of the application is on its own tab */
+	tabs := container.NewAppTabs(
+		container.NewTabItem("Calculator", calculatorSplit),
+		container.NewTabItem("Employees", employeesTab(myWindow, employees)),
+		container.NewTabItem("Payroll", payrollTab(myWindow, runs, employees, loans)),
+		container.NewTabItem("Loans", loansView.content),
+		container.NewTabItem("History", historyView.content),
+		container.NewTabItem("Compare", compareTab(myWindow)),
+		container.NewTabItem("Members", membersTab(myWindow)),
+		container.NewTabItem("MP2", mp2Tab(myWindow)),
+		container.NewTabItem("SSS Benefits", benefitsTab(myWindow)),
+		container.NewTabItem("Housing Loan", housingTab(myWindow)),
+		container.NewTabItem("Fringe Benefits", fringeTab(myWindow)),
+		container.NewTabItem("Charts", chartsView.content),
+	)
 
-	var rates = []decimal.Decimal{
-		decimal.NewFromFloat(0.0),
-		decimal.NewFromFloat(0.15),
-		decimal.NewFromFloat(0.20),
-		decimal.NewFromFloat(0.25),
-		decimal.NewFromFloat(0.30),
-		decimal.NewFromFloat(0.35),
+	// Balances change when payroll runs are locked or reopened
+	tabs.OnSelected = func(t *container.TabItem) {
+		if t.Text == "Loans" {
+			loansView.Refresh()
+		}
 	}
 
-	tax := decimal.Zero
-
-	if taxableIncome.GreaterThan(brackets[0]) {
-		for i := 1; i < len(brackets); i++ {
-			if taxableIncome.LessThanOrEqual(brackets[i]) {
-				tax = tax.Add((taxableIncome.Sub(brackets[i-1])).Mul(rates[i]))
-				//fmt.Println("i is ", i, "ADDED ", (taxableIncome.Sub(brackets[i-1])).Mul(rates[i]), "BECAME", tax)
-				break
-			} else {
-				tax = tax.Add(brackets[i].Sub(brackets[i-1]).Mul(rates[i]))
-				//fmt.Println("I is ", i, "ADDED ", brackets[i].Sub(brackets[i-1]).Mul(rates[i]), "BECAME", tax)
-				if i == len(brackets)-1 {
-					tax = tax.Add((taxableIncome.Sub(brackets[i])).Mul(rates[i+1]))
-					//fmt.Println("This is ", i, "ADDED ", (taxableIncome.Sub(brackets[i])).Mul(rates[i+1]), "BECAME", tax)
-				}
-
+	// Reopening a past computation puts it back on the calculator
+	historyView.onReopen = func(e history.Entry) {
+		rules := payroll.Current()
+		opts := e.Options
+		typeSelect.SetSelectedIndex(0)
+		for i, t := range payroll.EmployeeTypes {
+			if t == opts.EmployeeType {
+				typeSelect.SetSelectedIndex(i)
 			}
 		}
-	}
-
-	return tax.Round(2)
-}
+		regionSelect.SetSelected(noRegion)
+		if opts.Region != "" {
+			regionSelect.SetSelected(opts.Region)
+		}
 
-func calculateSSSContributions(monthlyIncome decimal.Decimal) decimal.Decimal {
-	/* Notice that based on the 2023 SSS Table,
-	the formula to get the gross contribution based on the monthly income is
-	the nearest multiple of 500, except when it is lower than 4250 it is automatically 4000,
-	and when it is greater than or equal to 29750 it is automatically 30000.
-	This is then multiplied by 4.5% to get the employee's actual SSS contribution
-
-	The following code is the implementation of this
-	*/
-	employeeRate := decimal.NewFromFloat(0.045)
-
-	var gross decimal.Decimal
-	if monthlyIncome.LessThan(decimal.NewFromInt(4250)) {
-		gross = decimal.NewFromInt(4000)
-	} else if monthlyIncome.GreaterThanOrEqual(decimal.NewFromInt(29750)) {
-		gross = decimal.NewFromInt(30000)
-	} else {
-		// implementation of MROUND(monthlyIncome, 500)
-		rate := decimal.NewFromInt(500)
-		divided := monthlyIncome.Div(rate)
-		floor := divided.RoundDown(0)
-		ceil := divided.RoundUp(0)
-		if divided.Sub(floor).LessThan(ceil.Sub(divided)) {
-			gross = floor.Mul(rate)
+		var traces []payroll.Trace
+		if b := e.Result.Earnings; b != nil && len(b.Lines) > 0 {
+			incomeEntry.SetText(b.Lines[0].Amount.String())
+			earningsEditor.set(b.Lines[1:])
+			traces, _ = rules.ExplainEarningsWith(b.Lines, opts)
+			chartsView.setIncome(b.Lines[0].Amount)
+			employerView.show(b.Lines[0].Amount, opts.EmployeeType)
 		} else {
-			gross = ceil.Mul(rate)
+			incomeEntry.SetText(e.Income.String())
+			earningsEditor.set(nil)
+			traces, _ = rules.ExplainWith(e.Income, opts)
+			chartsView.setIncome(e.Income)
+			employerView.show(e.Income, opts.EmployeeType)
 		}
-
-		//fmt.Println("ROUNDED: ", gross)
+		explanation.show(traces)
+		showResults(e.Result)
+		tabs.SelectIndex(0)
 	}
 
-	return gross.Mul(employeeRate)
-}
-
-func calculatePagIbigContributions(monthlyIncome decimal.Decimal) decimal.Decimal {
-	/* The https://taxcalculatorphilippines.com/ still uses the 2021 Pag-Ibig contribution table
-	This takes the monthly income and multiplies it by 1% if it is less than or equal to 1500,
-	otherwise it multiplies it by 2%
-
-	The maximum pag-ibig contribution is 100.00
-	*/
-	var rate decimal.Decimal
-	max := decimal.NewFromInt(100)
+	myWindow.SetContent(tabs)
+	myWindow.Resize(fyne.NewSize(800, 600))
+	myWindow.SetFixedSize(true)
+	if loadErr != nil {
+		dialog.ShowError(loadErr, myWindow)
+	}
 
-	if monthlyIncome.LessThanOrEqual(decimal.NewFromInt(1500)) {
-		rate = decimal.NewFromFloat(0.01)
+	// Corrected rule files are picked up while the app is open
+	stopWatching, err := payroll.WatchRules(storage.Path("rules"), func(set *payroll.RuleSet, err error) {
+		if err != nil {
+			log.Printf("rules not reloaded, keeping rules %s: %v", payroll.Current(), err)
+			dialog.ShowError(fmt.Errorf("the rule files were not reloaded: %w", err), myWindow)
+			return
+		}
+		log.Printf("rules reloaded, now using rules %s", set.Latest())
+	})
+	if err != nil {
+		log.Printf("not watching rule files: %v", err)
 	} else {
-		rate = decimal.NewFromFloat(0.02)
+		defer stopWatching()
 	}
+	myWindow.ShowAndRun()
 
-	return decimal.Min(max, monthlyIncome.Mul(rate))
-}
-
-func calculatePhilHealthContributions(monthlyIncome decimal.Decimal) decimal.Decimal {
-	/* The 2023 contribution rate for Philhealth is 4.5%
-	which is split equally between the employee and employer.
-	People have to give at least 225 and max 2025
-	People with <= 10000 salary must contribute 225
-	2025 is max amount anyone can contribute
-	NOTE: There's a mistake on https://taxcalculatorphilippines.com/ where
-	starting salary of 90000, it outputs 4050 for Philhealth instead of 2025
-	*/
-	rate := decimal.NewFromFloat(0.0225)
-	min := decimal.NewFromFloat(225)
-	// max := decimal.NewFromFloat(2025)
-
-	if monthlyIncome.LessThanOrEqual(decimal.NewFromFloat(10000)) {
-		return min
-	} else if monthlyIncome.GreaterThanOrEqual(decimal.NewFromFloat(90000)) {
-		return decimal.NewFromFloat(4050)
-	}
-	// return decimal.Min(max, monthlyIncome.Mul(rate))
-	return monthlyIncome.Mul(rate)
 }